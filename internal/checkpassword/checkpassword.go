@@ -0,0 +1,179 @@
+package checkpassword
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/gerolf-vent/mailctl/internal/utils/crypto/passwordhash"
+)
+
+// Exit codes of the checkpassword protocol, as interpreted by Dovecot.
+const (
+	ExitFailure       = 1   // Authentication failed
+	ExitUnknownUser   = 3   // User doesn't exist
+	ExitTempFailure   = 111 // Temporary problem, e.g. the database is unreachable
+	MaxCredentialsLen = 512 // Maximum length of the credentials on file descriptor 3
+)
+
+// Request is a lookup of Dovecot's checkpassword passdb or userdb.
+type Request struct {
+	Username string
+	Password string
+
+	// Authorized is set for userdb lookups (AUTHORIZED=1), which don't verify
+	// the password
+	Authorized bool
+}
+
+// ReadRequest reads the credentials ("<username>\0<password>\0...") of a
+// request.
+func ReadRequest(r io.Reader, authorized bool) (Request, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxCredentialsLen+1))
+	if err != nil {
+		return Request{}, err
+	}
+	if len(data) > MaxCredentialsLen {
+		return Request{}, fmt.Errorf("credentials exceed the maximum length of %d bytes", MaxCredentialsLen)
+	}
+
+	fields := bytes.SplitN(data, []byte{0}, 3)
+	if len(fields) < 2 {
+		return Request{}, errors.New("credentials aren't terminated by a NUL byte")
+	}
+	return Request{Username: string(fields[0]), Password: string(fields[1]), Authorized: authorized}, nil
+}
+
+// Account is the state of a mailbox or remote, which is relevant for logins.
+type Account struct {
+	NoLogin bool
+	Reason  string            // Why the login is disabled
+	Fields  map[string]string // Userdb fields, e.g. "quota_storage_size"
+}
+
+// Authenticator answers requests for a kind of login, e.g. mailboxes.
+type Authenticator struct {
+	// Lookup looks up the account of a login, which is nil if it doesn't
+	// exist
+	Lookup func(login string) (*Account, error)
+
+	// Verify verifies the password of a login. Errors wrapping
+	// passwordhash.ErrUnsupportedHash fail the login.
+	Verify func(login, password string) (bool, error)
+
+	// Allow reports whether a login has attempts left, so brute-force attacks
+	// are stopped (optional)
+	Allow func(login string) (bool, error)
+
+	// Record records a login attempt (optional)
+	Record func(login string, succeeded bool, failureReason string) error
+}
+
+// Result is the outcome of a request.
+type Result struct {
+	Code       int    // Exit code, 0 on success
+	Reason     string // Why the request failed
+	User       string
+	Fields     map[string]string
+	Authorized bool
+}
+
+// Authenticate answers a request. Login attempts are checked against the
+// rate limit and recorded, userdb lookups are not.
+func (a *Authenticator) Authenticate(req Request) (Result, error) {
+	login := req.Username
+
+	if req.Authorized {
+		account, err := a.Lookup(login)
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to look up %s: %w", login, err)
+		}
+		if account == nil {
+			return Result{Code: ExitUnknownUser, Reason: "Unknown user."}, nil
+		}
+		return Result{User: login, Fields: account.Fields, Authorized: true}, nil
+	}
+
+	if a.Allow != nil {
+		ok, err := a.Allow(login)
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to check login attempts of %s: %w", login, err)
+		}
+		if !ok {
+			return a.fail(login, ExitFailure, "Too many login attempts.")
+		}
+	}
+
+	account, err := a.Lookup(login)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to look up %s: %w", login, err)
+	}
+	if account == nil {
+		return a.fail(login, ExitUnknownUser, "Unknown user.")
+	}
+	if account.NoLogin {
+		return a.fail(login, ExitFailure, account.Reason)
+	}
+
+	ok, err := a.Verify(login, req.Password)
+	if errors.Is(err, passwordhash.ErrUnsupportedHash) {
+		// Retrying won't help, so the login fails instead of being deferred
+		return a.fail(login, ExitFailure, "Unsupported password hash.")
+	} else if err != nil {
+		return Result{}, fmt.Errorf("failed to verify password of %s: %w", login, err)
+	}
+	if !ok {
+		return a.fail(login, ExitFailure, "Password mismatch.")
+	}
+
+	if a.Record != nil {
+		if err := a.Record(login, true, ""); err != nil {
+			return Result{}, fmt.Errorf("failed to record login attempt of %s: %w", login, err)
+		}
+	}
+	return Result{User: login, Fields: account.Fields}, nil
+}
+
+// fail records a failed login attempt.
+func (a *Authenticator) fail(login string, code int, reason string) (Result, error) {
+	if a.Record != nil {
+		if err := a.Record(login, false, reason); err != nil {
+			return Result{}, fmt.Errorf("failed to record login attempt of %s: %w", login, err)
+		}
+	}
+	return Result{Code: code, Reason: reason}, nil
+}
+
+// Env returns the environment for the reply command of a successful request:
+// USER, the userdb fields as "userdb_<name>" variables listed in EXTRA and
+// AUTHORIZED=2 for userdb lookups.
+func (res Result) Env(environ []string) []string {
+	env := slices.DeleteFunc(slices.Clone(environ), func(v string) bool {
+		name, _, _ := strings.Cut(v, "=")
+		return name == "USER" || name == "EXTRA" || name == "AUTHORIZED" || strings.HasPrefix(name, "userdb_")
+	})
+	env = append(env, "USER="+res.User)
+
+	names := make([]string, 0, len(res.Fields))
+	for name := range res.Fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	extra := make([]string, 0, len(names))
+	for _, name := range names {
+		env = append(env, "userdb_"+name+"="+res.Fields[name])
+		extra = append(extra, "userdb_"+name)
+	}
+	if len(extra) > 0 {
+		env = append(env, "EXTRA="+strings.Join(extra, " "))
+	}
+
+	if res.Authorized {
+		env = append(env, "AUTHORIZED=2")
+	}
+	return env
+}
@@ -0,0 +1,126 @@
+package checkpassword
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gerolf-vent/mailctl/internal/utils/crypto/passwordhash"
+)
+
+func TestReadRequest(t *testing.T) {
+	req, err := ReadRequest(strings.NewReader("alice@example.com\x00secret\x00\x00"), false)
+	if err != nil {
+		t.Fatalf("ReadRequest() unexpected error: %v", err)
+	}
+	if req.Username != "alice@example.com" || req.Password != "secret" || req.Authorized {
+		t.Fatalf("ReadRequest() = %+v", req)
+	}
+
+	// Userdb lookups send an empty password
+	req, err = ReadRequest(strings.NewReader("alice@example.com\x00\x00"), true)
+	if err != nil || req.Username != "alice@example.com" || req.Password != "" || !req.Authorized {
+		t.Fatalf("ReadRequest() = %+v, %v", req, err)
+	}
+
+	for _, in := range []string{"", "alice@example.com", strings.Repeat("a", MaxCredentialsLen) + "\x00\x00"} {
+		if _, err := ReadRequest(strings.NewReader(in), false); err == nil {
+			t.Fatalf("ReadRequest(%.20q) expected error", in)
+		}
+	}
+}
+
+type attempt struct {
+	email     string
+	succeeded bool
+	reason    string
+}
+
+func TestAuthenticate(t *testing.T) {
+	var attempts []attempt
+	a := &Authenticator{
+		Lookup: func(login string) (*Account, error) {
+			switch login {
+			case "alice@example.com":
+				return &Account{Fields: map[string]string{"quota_storage_size": "1024M"}}, nil
+			case "bob@example.com":
+				return &Account{NoLogin: true, Reason: "Login is disabled."}, nil
+			case "dave@example.com":
+				return &Account{}, nil
+			case "broken@example.com":
+				return nil, errors.New("connection refused")
+			}
+			return nil, nil
+		},
+		Verify: func(login, password string) (bool, error) {
+			if login == "dave@example.com" {
+				return false, passwordhash.ErrUnsupportedHash
+			}
+			return password == "secret", nil
+		},
+		Allow: func(login string) (bool, error) {
+			return login != "mallory@example.com", nil
+		},
+		Record: func(login string, succeeded bool, failureReason string) error {
+			attempts = append(attempts, attempt{login, succeeded, failureReason})
+			return nil
+		},
+	}
+
+	for _, tt := range []struct {
+		req     Request
+		code    int
+		attempt *attempt
+	}{
+		{Request{Username: "alice@example.com", Password: "secret"}, 0, &attempt{"alice@example.com", true, ""}},
+		{Request{Username: "alice@example.com", Password: "wrong"}, ExitFailure, &attempt{"alice@example.com", false, "Password mismatch."}},
+		{Request{Username: "bob@example.com", Password: "secret"}, ExitFailure, &attempt{"bob@example.com", false, "Login is disabled."}},
+		{Request{Username: "carol@example.com", Password: "secret"}, ExitUnknownUser, &attempt{"carol@example.com", false, "Unknown user."}},
+		{Request{Username: "mallory@example.com", Password: "secret"}, ExitFailure, &attempt{"mallory@example.com", false, "Too many login attempts."}},
+		{Request{Username: "dave@example.com", Password: "secret"}, ExitFailure, &attempt{"dave@example.com", false, "Unsupported password hash."}},
+
+		// Userdb lookups aren't login attempts
+		{Request{Username: "alice@example.com", Authorized: true}, 0, nil},
+		{Request{Username: "bob@example.com", Authorized: true}, 0, nil},
+		{Request{Username: "carol@example.com", Authorized: true}, ExitUnknownUser, nil},
+	} {
+		attempts = nil
+		result, err := a.Authenticate(tt.req)
+		if err != nil {
+			t.Fatalf("Authenticate(%+v) unexpected error: %v", tt.req, err)
+		}
+		if result.Code != tt.code {
+			t.Fatalf("Authenticate(%+v) code = %d, want %d (%s)", tt.req, result.Code, tt.code, result.Reason)
+		}
+		if result.Code == 0 && (result.User != tt.req.Username || result.Authorized != tt.req.Authorized) {
+			t.Fatalf("Authenticate(%+v) = %+v", tt.req, result)
+		}
+		switch {
+		case tt.attempt == nil && len(attempts) > 0:
+			t.Fatalf("Authenticate(%+v) recorded %v", tt.req, attempts)
+		case tt.attempt != nil && (len(attempts) != 1 || attempts[0] != *tt.attempt):
+			t.Fatalf("Authenticate(%+v) recorded %v, want %v", tt.req, attempts, *tt.attempt)
+		}
+	}
+
+	if _, err := a.Authenticate(Request{Username: "broken@example.com", Password: "secret"}); err == nil {
+		t.Fatal("Authenticate() expected error")
+	}
+}
+
+func TestResultEnv(t *testing.T) {
+	result := Result{User: "alice@example.com", Fields: map[string]string{"quota_storage_size": "1024M", "home": "/var/mail/alice"}, Authorized: true}
+	env := result.Env([]string{"PATH=/usr/bin", "USER=dovecot", "AUTHORIZED=1"})
+	want := []string{
+		"PATH=/usr/bin",
+		"USER=alice@example.com",
+		"userdb_home=/var/mail/alice",
+		"userdb_quota_storage_size=1024M",
+		"EXTRA=userdb_home userdb_quota_storage_size",
+		"AUTHORIZED=2",
+	}
+	if !slices.Equal(env, want) {
+		t.Fatalf("Env() = %q, want %q", env, want)
+	}
+}
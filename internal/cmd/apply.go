@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/state"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var ApplyCmd = &cobra.Command{
+	Use:   "apply -f <file> [flags]",
+	Short: "Apply a declarative configuration file",
+	Long: "Apply a declarative configuration file (YAML or JSON) describing the desired state of the mail system.\n" +
+		"The file is validated against the JSON Schema, then a plan is computed against the current state, printed and applied in a single transaction.\n" +
+		"Objects not described in the file are kept, unless --prune is given, which soft-deletes them.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagFile, _ := cmd.Flags().GetString("file")
+		flagPrune, _ := cmd.Flags().GetBool("prune")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		desired, err := LoadStateFile(flagFile)
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to load "+flagFile, err)
+			return utils.ExitError{Code: 1}
+		}
+
+		// Record a default reason for the changes
+		session := db.CurrentSession()
+		if session.Reason == "" {
+			session.Reason = "apply of " + flagFile
+			db.SetSession(session)
+		}
+
+		runner := StateRunner{
+			Desired:        desired,
+			PlanOptions:    state.PlanOptions{Prune: flagPrune},
+			DryRun:         flagDryRun,
+			ItemString:     flagFile,
+			FailureMessage: "failed to apply configuration",
+			SuccessMessage: "Successfully applied configuration",
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	ApplyCmd.Flags().StringP("file", "f", "", "Configuration file (YAML or JSON, '-' for stdin)")
+	ApplyCmd.Flags().Bool("prune", false, "Soft-delete objects not described in the file")
+	ApplyCmd.Flags().Bool("dry-run", false, "Only show the plan without applying it")
+	_ = ApplyCmd.MarkFlagRequired("file")
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var AuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the audit log",
+	Long:  "Inspect the audit log, which records every change to mail system objects.",
+}
+
+func init() {
+	// Add subcommands
+	AuditCmd.AddCommand(AuditListCmd)
+	AuditCmd.AddCommand(AuditShowCmd)
+	AuditCmd.AddCommand(AuditRevertCmd)
+	AuditCmd.AddCommand(AuditPruneCmd)
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+func listAuditLog(options db.AuditLogListOptions) ([]db.AuditLogEntry, error) {
+	dbConn, err := db.Connect()
+	if err != nil {
+		utils.PrintErrorWithMessage("failed to connect to database", err)
+		return nil, err
+	}
+	defer func() {
+		if err := dbConn.Close(); err != nil {
+			utils.PrintErrorWithMessage("failed to close database connection", err)
+		}
+	}()
+
+	entries, err := db.AuditLog(dbConn).List(options)
+	if err != nil {
+		utils.PrintErrorWithMessage("failed to list audit log", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+var AuditListCmd = &cobra.Command{
+	Use:   "list [flags] [<object>...]",
+	Short: "List audit log entries",
+	Long: "List audit log entries in chronological order.\n" +
+		"If objects (email address, domain FQDN, transport or remote name) are provided, only entries for these objects are listed. " +
+		"Entries of owned objects (alias targets, catchall targets and send grants) and entries recorded under previous names are included.",
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagTables, _ := cmd.Flags().GetStringSlice("table")
+		flagOperations, _ := cmd.Flags().GetStringSlice("operation")
+		flagChangedBy, _ := cmd.Flags().GetStringSlice("changed-by")
+		flagSince, _ := cmd.Flags().GetString("since")
+		flagUntil, _ := cmd.Flags().GetString("until")
+		flagLimit, _ := cmd.Flags().GetUint64("limit")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		objects, err := ParseAuditLogObjectArgs(args)
+		if err != nil {
+			return err
+		}
+
+		options := db.AuditLogListOptions{
+			FilterChangedBy: flagChangedBy,
+			FilterObjects:   objects,
+			Limit:           flagLimit,
+		}
+
+		for _, table := range flagTables {
+			switch table {
+			case "domains":
+				options.FilterTables = append(options.FilterTables, "domains_managed", "domains_relayed", "domains_alias", "domains_canonical")
+			case "alias-targets":
+				options.FilterTables = append(options.FilterTables, "aliases_targets_recursive", "aliases_targets_foreign")
+			default:
+				if !slices.Contains(db.AuditLogTables, table) {
+					return fmt.Errorf("invalid table %q, must be one of: domains, alias-targets, %s", table, strings.Join(db.AuditLogTables, ", "))
+				}
+				options.FilterTables = append(options.FilterTables, table)
+			}
+		}
+
+		for _, operation := range flagOperations {
+			operation = strings.ToUpper(operation)
+			if !slices.Contains(db.AuditLogOperations, operation) {
+				return fmt.Errorf("invalid operation %q, must be one of: %s", operation, strings.Join(db.AuditLogOperations, ", "))
+			}
+			options.FilterOperations = append(options.FilterOperations, operation)
+		}
+
+		now := time.Now()
+		if flagSince != "" {
+			since, err := utils.ParseTimeOrDuration(flagSince, now)
+			if err != nil {
+				return fmt.Errorf("invalid --since value: %w", err)
+			}
+			options.Since = &since
+		}
+		if flagUntil != "" {
+			until, err := utils.ParseTimeOrDuration(flagUntil, now)
+			if err != nil {
+				return fmt.Errorf("invalid --until value: %w", err)
+			}
+			options.Until = &until
+		}
+
+		entries, err := listAuditLog(options)
+		if err != nil {
+			return nil
+		}
+
+		if flagJSON {
+			out, err := json.Marshal(entries)
+			if err != nil {
+				utils.PrintErrorWithMessage("Failed to marshal audit log entries to JSON", err)
+				return nil
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No audit log entries found")
+			return nil
+		}
+
+		t := table.New().
+			Border(lipgloss.RoundedBorder()).
+			BorderStyle(utils.BlackStyle).
+			BorderRow(true).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				cellStyle := utils.TableRowStyle
+				if row == table.HeaderRow {
+					cellStyle = utils.TableHeaderStyle
+				}
+				switch col {
+				case 0: // ID
+					return cellStyle.Align(lipgloss.Right)
+				default:
+					return cellStyle.Align(lipgloss.Left)
+				}
+			}).
+			Headers("ID", "Changed", "Changed By", "Operation", "Table", "Object", "Changes", "Reason")
+
+		for _, e := range entries {
+			t.Row(
+				fmt.Sprintf("%d", e.ID),
+				utils.MaybeTimeStyle.Render(e.ChangedAt),
+				e.ChangedBy,
+				RenderAuditOperation(e.Operation),
+				e.TableName,
+				utils.MaybeEmptyStyle.Render(e.Object),
+				RenderAuditChangesCompact(e),
+				utils.MaybeEmptyStyle.Render(e.Reason),
+			)
+		}
+
+		fmt.Println(t.Render())
+		return nil
+	},
+}
+
+func init() {
+	AuditListCmd.Flags().StringSliceP("table", "t", nil, "Only show entries of these tables ('domains' and 'alias-targets' select all tables of that kind)")
+	AuditListCmd.Flags().StringSliceP("operation", "o", nil, "Only show entries of these operations (INSERT, UPDATE or DELETE)")
+	AuditListCmd.Flags().StringSlice("changed-by", nil, "Only show entries changed by these operators")
+	AuditListCmd.Flags().String("since", "", "Only show entries changed at or after this time (timestamp or duration like '24h' or '7d')")
+	AuditListCmd.Flags().String("until", "", "Only show entries changed before this time (timestamp or duration like '24h' or '7d')")
+	AuditListCmd.Flags().Uint64P("limit", "n", 100, "Only show the most recent entries (0 for no limit)")
+	AuditListCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+}
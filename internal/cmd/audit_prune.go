@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var AuditPruneCmd = &cobra.Command{
+	Use:   "prune [flags]",
+	Short: "Prune old audit log entries",
+	Long: "Prune old audit log entries by dropping the monthly partitions of the audit log, which ended before the retention period.\n" +
+		"With --export-dir the entries of each partition are exported to a gzip compressed JSON lines file before the partition is dropped. " +
+		"Partitions are only dropped, if the export succeeded.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagOlderThan, _ := cmd.Flags().GetString("older-than")
+		flagExportDir, _ := cmd.Flags().GetString("export-dir")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		retention, err := utils.ParseDuration(flagOlderThan)
+		if err != nil {
+			return fmt.Errorf("invalid --older-than value: %w", err)
+		}
+		cutoff := time.Now().Add(-retention)
+
+		if flagExportDir != "" {
+			info, err := os.Stat(flagExportDir)
+			if err != nil {
+				return fmt.Errorf("invalid --export-dir value: %w", err)
+			}
+			if !info.IsDir() {
+				return fmt.Errorf("invalid --export-dir value: %s is not a directory", flagExportDir)
+			}
+		}
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		partitions, err := db.AuditLog(dbConn).ListPartitions()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to list audit log partitions", err)
+			return utils.ExitError{Code: 1}
+		}
+
+		var prunable []db.AuditLogPartition
+		for _, partition := range partitions {
+			if !partition.RangeEnd.After(cutoff) {
+				prunable = append(prunable, partition)
+			}
+		}
+
+		if len(prunable) == 0 {
+			fmt.Println("No audit log partitions to prune.")
+			return nil
+		}
+
+		if flagDryRun {
+			for _, partition := range prunable {
+				fmt.Printf("Would prune audit log partition: %s\n", auditLogPartitionString(partition))
+			}
+			return nil
+		}
+
+		runner := db.TxForEachRunner[db.AuditLogPartition]{
+			Items: prunable,
+			Exec: func(tx *sql.Tx, partition db.AuditLogPartition) error {
+				if flagExportDir != "" {
+					if err := exportAuditLogPartition(tx, partition, flagExportDir); err != nil {
+						return err
+					}
+				}
+				return db.AuditLog(tx).DropPartition(partition.Name)
+			},
+			ItemString:     auditLogPartitionString,
+			FailureMessage: "failed to prune audit log partition",
+			SuccessMessage: "Successfully pruned audit log partition",
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	AuditPruneCmd.Flags().String("older-than", "", "Retention period (duration like '400d' or '52w'); partitions ending before it are pruned")
+	AuditPruneCmd.Flags().String("export-dir", "", "Directory to export pruned entries to (as audit-<partition>.jsonl.gz)")
+	AuditPruneCmd.Flags().Bool("dry-run", false, "Only show which partitions would be pruned")
+
+	_ = AuditPruneCmd.MarkFlagRequired("older-than")
+}
+
+func auditLogPartitionString(partition db.AuditLogPartition) string {
+	return fmt.Sprintf("%s (%s)", partition.Name, partition.RangeStart.UTC().Format("2006-01"))
+}
+
+// exportAuditLogPartition writes the entries of a partition to a compressed
+// file. The file is written under a temporary name and renamed on success, so
+// an incomplete export never looks like a complete one.
+func exportAuditLogPartition(tx *sql.Tx, partition db.AuditLogPartition, dir string) (err error) {
+	path := filepath.Join(dir, "audit-"+partition.Name+".jsonl.gz")
+
+	f, err := os.CreateTemp(dir, ".audit-"+partition.Name+"-*.jsonl.gz")
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	gw := gzip.NewWriter(f)
+	gw.Name = filepath.Base(path[:len(path)-len(".gz")])
+	gw.ModTime = partition.RangeEnd
+
+	if _, err = db.AuditLog(tx).ExportPartition(partition, gw); err != nil {
+		return fmt.Errorf("failed to export entries: %w", err)
+	}
+	if err = gw.Close(); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err = f.Sync(); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	return nil
+}
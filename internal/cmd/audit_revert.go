@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var AuditRevertCmd = &cobra.Command{
+	Use:   "revert [flags] [<id>...]",
+	Short: "Revert changes recorded in the audit log",
+	Long: "Revert changes recorded in the audit log by restoring the previous state of the changed records in a single transaction.\n" +
+		"Either provide audit log entry ids, or revert all changes of an object since a point in time using --since and --object.\n" +
+		"Reverting is refused, if a record has been changed later by a change not being reverted as well.",
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagSince, _ := cmd.Flags().GetString("since")
+		flagObject, _ := cmd.Flags().GetString("object")
+
+		var ids []int64
+		var since time.Time
+		var object db.AuditLogObject
+		var itemString string
+
+		if len(args) > 0 {
+			if flagSince != "" || flagObject != "" {
+				return fmt.Errorf("cannot use audit log ids together with --since or --object")
+			}
+
+			for _, arg := range args {
+				id, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil || id <= 0 {
+					return fmt.Errorf("invalid audit log id: %s", arg)
+				}
+				ids = append(ids, id)
+			}
+
+			itemString = formatAuditLogIDs(ids)
+		} else {
+			if flagSince == "" || flagObject == "" {
+				return fmt.Errorf("either audit log ids or both --since and --object must be provided")
+			}
+
+			var err error
+			since, err = utils.ParseTimeOrDuration(flagSince, time.Now())
+			if err != nil {
+				return fmt.Errorf("invalid --since value: %w", err)
+			}
+
+			objects, err := ParseAuditLogObjectArgs([]string{flagObject})
+			if err != nil {
+				return err
+			}
+			object = objects[0]
+
+			itemString = fmt.Sprintf("%s since %s", object.String(), utils.MaybeTimeStyle.Render(since))
+		}
+
+		// Record a default reason for the revert
+		session := db.CurrentSession()
+		if session.Reason == "" {
+			session.Reason = "revert of " + itemString
+			db.SetSession(session)
+		}
+
+		runner := db.TxRunner{
+			Exec: func(tx *sql.Tx) error {
+				revertIDs := ids
+				if len(revertIDs) == 0 {
+					entries, err := db.AuditLog(tx).List(db.AuditLogListOptions{
+						FilterObjects: []db.AuditLogObject{object},
+						Since:         &since,
+					})
+					if err != nil {
+						return err
+					}
+					if len(entries) == 0 {
+						return fmt.Errorf("no audit log entries found")
+					}
+					for _, entry := range entries {
+						revertIDs = append(revertIDs, entry.ID)
+					}
+				}
+
+				return db.AuditLog(tx).Revert(revertIDs)
+			},
+			ItemString:     itemString,
+			FailureMessage: "failed to revert changes",
+			SuccessMessage: "Successfully reverted changes",
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	AuditRevertCmd.Flags().String("since", "", "Revert all changes at or after this time (timestamp or duration like '24h' or '7d')")
+	AuditRevertCmd.Flags().String("object", "", "Object (email address, domain FQDN, transport or remote name) to revert changes of")
+}
+
+func formatAuditLogIDs(ids []int64) string {
+	strs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strs = append(strs, "#"+strconv.FormatInt(id, 10))
+	}
+	return strings.Join(strs, ", ")
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var AuditShowCmd = &cobra.Command{
+	Use:   "show [flags] <id>...",
+	Short: "Show audit log entries",
+	Long:  "Show audit log entries with a field-level diff of the old and new record data.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		ids := make([]int64, 0, len(args))
+		for _, arg := range args {
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil || id <= 0 {
+				return fmt.Errorf("invalid audit log id: %s", arg)
+			}
+			ids = append(ids, id)
+		}
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return nil
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		var entries []db.AuditLogEntry
+		for _, id := range ids {
+			found, err := db.AuditLog(dbConn).List(db.AuditLogListOptions{
+				ByID: id,
+			})
+			if err != nil {
+				utils.PrintErrorWithMessage(fmt.Sprintf("failed to get audit log entry %d", id), err)
+				continue
+			}
+			if len(found) == 0 {
+				utils.PrintError(fmt.Errorf("audit log entry %d not found", id))
+				continue
+			}
+			entries = append(entries, found[0])
+		}
+
+		if flagJSON {
+			out, err := json.Marshal(entries)
+			if err != nil {
+				utils.PrintErrorWithMessage("Failed to marshal audit log entries to JSON", err)
+				return nil
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		for _, entry := range entries {
+			fmt.Println(renderAuditLogEntry(entry))
+		}
+		return nil
+	},
+}
+
+func init() {
+	AuditShowCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+}
+
+func renderAuditLogEntry(entry db.AuditLogEntry) string {
+	title := fmt.Sprintf("Audit Log Entry #%d", entry.ID)
+
+	// Properties
+	propT := table.New().
+		Rows([][]string{
+			{"Operation:", RenderAuditOperation(entry.Operation)},
+			{"Table:", entry.TableName},
+			{"Record ID:", fmt.Sprintf("%d", entry.RecordID)},
+			{"Object:", utils.MaybeEmptyStyle.Render(entry.Object)},
+			{"Changed By:", entry.ChangedBy},
+			{"Changed At:", utils.MaybeTimeStyle.Render(entry.ChangedAt)},
+			{"Reason:", utils.MaybeEmptyStyle.Render(entry.Reason)},
+		}...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			cellStyle := utils.TableRowStyle
+			if col == 0 {
+				return cellStyle.PaddingLeft(0).PaddingRight(3)
+			}
+			return cellStyle
+		}).
+		BorderTop(false).
+		BorderBottom(false).
+		BorderLeft(false).
+		BorderRight(false).
+		BorderColumn(false)
+
+	// Changes
+	changesT := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(utils.BlackStyle).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return utils.TableHeaderStyle
+			}
+			switch col {
+			case 1: // Old
+				return utils.TableRowStyle.Foreground(utils.RedStyle.GetForeground())
+			case 2: // New
+				return utils.TableRowStyle.Foreground(utils.GreenStyle.GetForeground())
+			default:
+				return utils.TableRowStyle
+			}
+		}).
+		Headers("Field", "Old", "New")
+
+	for _, change := range entry.Changes {
+		changesT.Row(change.Field, RenderAuditValue(change.Old), RenderAuditValue(change.New))
+	}
+
+	// Output final table
+	headerStyle := lipgloss.NewStyle().Bold(true)
+	t := table.New().
+		BorderStyle(utils.BlackStyle).
+		BorderRow(true).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			return utils.TableRowStyle
+		})
+	t.Row(headerStyle.Render(title))
+	t = t.Row(headerStyle.Render("Properties") + "\n\n" + propT.Render())
+	if len(entry.Changes) > 0 {
+		t = t.Row(headerStyle.Render("Changes") + "\n\n" + changesT.Render())
+	} else {
+		t = t.Row(headerStyle.Render("Changes") + "\n\n" + utils.BlackStyle.Render("<no changes>"))
+	}
+	return t.Render()
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/gerolf-vent/mailctl/internal/checkpassword"
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/gerolf-vent/mailctl/internal/utils/crypto/passwordhash"
+	"github.com/spf13/cobra"
+)
+
+var CheckpasswordCmd = &cobra.Command{
+	Use:   "checkpassword [<reply-command> [<arg>...]]",
+	Short: "Authenticate mailboxes and remotes for Dovecot's checkpassword passdb and userdb",
+	Long: "Implements the checkpassword protocol: reads \"<username>\\0<password>\\0\" from file descriptor 3, verifies the password of the mailbox (username is an email address) or remote and executes the reply command on success.\n" +
+		"Every login attempt is recorded with $TCPREMOTEIP and $SERVICE. Logins with --max-attempts failed attempts within --interval (since the last successful one and not unlocked by 'mailctl logins unlock') or locked by the login lockout (see 'mailctl logins lockout') are rejected.\n" +
+		"Userdb lookups (AUTHORIZED=1) only check that the mailbox exists. The userdb fields are returned as userdb_* environment variables listed in EXTRA.\n" +
+		"Exits with 1 if the authentication failed, 3 if the user is unknown and 111 on temporary errors.",
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagMaxAttempts, _ := cmd.Flags().GetUint32("max-attempts")
+		flagInterval, _ := cmd.Flags().GetDuration("interval")
+		flagService, _ := cmd.Flags().GetString("service")
+
+		credentials := os.NewFile(3, "credentials")
+		if credentials == nil {
+			fmt.Fprintln(os.Stderr, "mailctl checkpassword: file descriptor 3 isn't open")
+			return utils.ExitError{Code: 2}
+		}
+		req, err := checkpassword.ReadRequest(credentials, os.Getenv("AUTHORIZED") == "1")
+		_ = credentials.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "mailctl checkpassword: failed to read credentials:", err)
+			return utils.ExitError{Code: 2}
+		}
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "mailctl checkpassword: failed to connect to database:", err)
+			return utils.ExitError{Code: checkpassword.ExitTempFailure}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				fmt.Fprintln(os.Stderr, "mailctl checkpassword: failed to close database connection:", err)
+			}
+		}()
+
+		recordOptions := db.LoginAttemptsRecordOptions{
+			RemoteIP: os.Getenv("TCPREMOTEIP"),
+			Protocol: os.Getenv("SERVICE"),
+			Service:  flagService,
+		}
+
+		// Logins with an email address are mailboxes, others are remotes. The
+		// password hash is returned by the passdb lookup, so the dovecot user
+		// can verify passwords without read access on the tables.
+		var authenticator *checkpassword.Authenticator
+		var passwordHash sql.NullString
+		if email, err := utils.ParseEmailAddress(req.Username); err == nil {
+			req.Username = email.String()
+			authenticator = &checkpassword.Authenticator{
+				Lookup: func(login string) (*checkpassword.Account, error) {
+					passdb, err := db.DovecotPassDBMailboxes(dbConn, email)
+					if errors.Is(err, sql.ErrNoRows) {
+						return nil, nil
+					} else if err != nil {
+						return nil, err
+					}
+					passwordHash = passdb.Password
+					account := &checkpassword.Account{
+						NoLogin: passdb.NoLogin.Valid && passdb.NoLogin.Bool,
+						Reason:  passdb.Reason.String,
+						Fields:  make(map[string]string),
+					}
+
+					quota, err := db.DovecotUserDBMailboxes(dbConn, email)
+					if err != nil && !errors.Is(err, sql.ErrNoRows) {
+						return nil, err
+					}
+					if quota != "" {
+						account.Fields["quota_storage_size"] = quota
+					}
+					return account, nil
+				},
+				Verify: func(login, password string) (bool, error) {
+					if !passwordHash.Valid {
+						return false, nil
+					}
+					return passwordhash.Compare(passwordHash.String, password)
+				},
+				Record: func(login string, succeeded bool, failureReason string) error {
+					return db.MailboxesLoginAttempts(dbConn).Record(email, succeeded, failureReason, recordOptions)
+				},
+			}
+			if flagMaxAttempts > 0 {
+				authenticator.Allow = func(login string) (bool, error) {
+					return db.MailboxesLoginAttempts(dbConn).CheckRateLimit(email, flagMaxAttempts, flagInterval)
+				}
+			}
+		} else {
+			authenticator = &checkpassword.Authenticator{
+				Lookup: func(login string) (*checkpassword.Account, error) {
+					passdb, err := db.DovecotPassDBRemotes(dbConn, login)
+					if errors.Is(err, sql.ErrNoRows) {
+						return nil, nil
+					} else if err != nil {
+						return nil, err
+					}
+					passwordHash = passdb.Password
+					return &checkpassword.Account{
+						NoLogin: passdb.NoLogin.Valid && passdb.NoLogin.Bool,
+						Reason:  passdb.Reason.String,
+					}, nil
+				},
+				Verify: func(login, password string) (bool, error) {
+					if !passwordHash.Valid {
+						return false, nil
+					}
+					return passwordhash.Compare(passwordHash.String, password)
+				},
+				Record: func(login string, succeeded bool, failureReason string) error {
+					return db.RemotesLoginAttempts(dbConn).Record(login, succeeded, failureReason, recordOptions)
+				},
+			}
+			if flagMaxAttempts > 0 {
+				authenticator.Allow = func(login string) (bool, error) {
+					return db.RemotesLoginAttempts(dbConn).CheckRateLimit(login, flagMaxAttempts, flagInterval)
+				}
+			}
+		}
+
+		result, err := authenticator.Authenticate(req)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "mailctl checkpassword:", err)
+			return utils.ExitError{Code: checkpassword.ExitTempFailure}
+		}
+		if result.Code != 0 {
+			return utils.ExitError{Code: result.Code}
+		}
+
+		// Without a reply command the exit code is the answer
+		if len(args) == 0 {
+			return nil
+		}
+
+		replyPath, err := exec.LookPath(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "mailctl checkpassword: failed to find reply command:", err)
+			return utils.ExitError{Code: checkpassword.ExitTempFailure}
+		}
+		_ = dbConn.Close()
+		if err := syscall.Exec(replyPath, args, result.Env(os.Environ())); err != nil {
+			fmt.Fprintln(os.Stderr, "mailctl checkpassword: failed to execute reply command:", err)
+			return utils.ExitError{Code: checkpassword.ExitTempFailure}
+		}
+		return nil
+	},
+}
+
+func init() {
+	CheckpasswordCmd.Flags().Uint32("max-attempts", 10, "Maximum number of failed login attempts of an address within --interval (0 disables the limit)")
+	CheckpasswordCmd.Flags().Duration("interval", 15*time.Minute, "Interval in which login attempts are counted")
+	CheckpasswordCmd.Flags().String("service", "dovecot", "Service recorded with the login attempts")
+	CheckpasswordCmd.Flags().SetInterspersed(false)
+}
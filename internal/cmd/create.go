@@ -8,6 +8,14 @@ var CreateCmd = &cobra.Command{
 }
 
 func init() {
+	// Add common flags
+	CreateCmd.PersistentFlags().Bool("dry-run", false, "Show the affected rows (including cascaded changes) without committing")
+	CreateCmd.PersistentFlags().BoolP("json", "j", false, "Output the result of each item in JSON format")
+	CreateCmd.PersistentFlags().Bool("atomic", false, "Process all items in a single transaction, which is rolled back on the first failure")
+	CreateCmd.PersistentFlags().Int("parallel", 1, "Process up to this many items concurrently, each in its own transaction")
+	CreateCmd.MarkFlagsMutuallyExclusive("atomic", "parallel")
+	CreateCmd.PersistentFlags().String("from-file", "", "Read the items from a CSV, JSONL or newline-separated file ('-' for stdin)")
+
 	// Add subcommands
 	CreateCmd.AddCommand(CreateDomainsCmd)
 	CreateCmd.AddCommand(CreateMailboxesCmd)
@@ -18,4 +26,5 @@ func init() {
 	CreateCmd.AddCommand(CreateTransportsCmd)
 	CreateCmd.AddCommand(CreateRemotesCmd)
 	CreateCmd.AddCommand(CreateRemoteSendGrantsCmd)
+	CreateCmd.AddCommand(CreateVacationCmd)
 }
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/gerolf-vent/mailctl/internal/utils"
@@ -14,31 +13,55 @@ var CreateAliasesCmd = &cobra.Command{
 	Aliases: []string{"alias"},
 	Short:   "Creates new aliases",
 	Long:    "Creates new aliases.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		flagDisabled, _ := cmd.Flags().GetBool("disabled")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
-		argEmails := ParseEmailArgs(args)
-		if len(argEmails) != len(args) {
-			return fmt.Errorf("invalid email arguments")
+		rows, err := ReadItemRows(cmd, args, []string{"email"}, "disabled")
+		if err != nil {
+			return err
 		}
 
-		options := db.AliasesCreateOptions{
-			Disabled: flagDisabled,
+		type aliasItem struct {
+			email   utils.EmailAddress
+			options db.AliasesCreateOptions
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddress]{
-			Items: argEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddress) error {
-				return db.Aliases(tx).Create(item, options)
+		items := make([]aliasItem, 0, len(rows))
+		for _, row := range rows {
+			email, err := row.Email(0)
+			if err != nil {
+				return err
+			}
+
+			flagDisabled, _ := row.GetBool("disabled")
+
+			items = append(items, aliasItem{
+				email: email,
+				options: db.AliasesCreateOptions{
+					Disabled: flagDisabled,
+				},
+			})
+		}
+
+		runner := db.TxForEachRunner[aliasItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item aliasItem) error {
+				return db.Aliases(tx).Create(item.email, item.options)
 			},
-			ItemString:     func(item utils.EmailAddress) string { return item.String() },
+			ItemString:     func(item aliasItem) string { return item.email.String() },
 			FailureMessage: "failed to create alias",
 			SuccessMessage: "Successfully created alias",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/gerolf-vent/mailctl/internal/utils"
@@ -14,36 +13,65 @@ var CreateAliasTargetsCmd = &cobra.Command{
 	Aliases: []string{"alias-target", "targets", "target"},
 	Short:   "Creates new alias targets for an alias",
 	Long:    "Creates new alias targets for an alias.",
-	Args:    cobra.MinimumNArgs(2),
+	Args:    ItemArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		flagForward, _ := cmd.Flags().GetBool("forward")
-		flagSend, _ := cmd.Flags().GetBool("send")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
-		argEmails := ParseEmailArgs(args)
-		if len(argEmails) != len(args) {
-			return fmt.Errorf("invalid email arguments")
+		rows, err := ReadItemRows(cmd, args, []string{"alias", "target"}, "forward", "send")
+		if err != nil {
+			return err
 		}
 
-		argAliasEmail := argEmails[0]
-		argTargetEmails := argEmails[1:]
+		type aliasTargetItem struct {
+			aliasEmail  utils.EmailAddress
+			targetEmail utils.EmailAddress
+			options     db.AliasesTargetsCreateOptions
+		}
+
+		items := make([]aliasTargetItem, 0, len(rows))
+		for _, row := range rows {
+			aliasEmail, err := row.Email(0)
+			if err != nil {
+				return err
+			}
+			targetEmail, err := row.Email(1)
+			if err != nil {
+				return err
+			}
 
-		options := db.AliasesTargetsCreateOptions{
-			ForwardEnabled: flagForward,
-			SendEnabled:    flagSend,
+			flagForward, _ := row.GetBool("forward")
+			flagSend, _ := row.GetBool("send")
+
+			items = append(items, aliasTargetItem{
+				aliasEmail:  aliasEmail,
+				targetEmail: targetEmail,
+				options: db.AliasesTargetsCreateOptions{
+					ForwardEnabled: flagForward,
+					SendEnabled:    flagSend,
+				},
+			})
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddress]{
-			Items: argTargetEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddress) error {
-				return db.AliasesTargets(tx).Create(argAliasEmail, item, options)
+		runner := db.TxForEachRunner[aliasTargetItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item aliasTargetItem) error {
+				return db.AliasesTargets(tx).Create(item.aliasEmail, item.targetEmail, item.options)
+			},
+			ItemString: func(item aliasTargetItem) string {
+				return item.aliasEmail.String() + " -> " + item.targetEmail.String()
 			},
-			ItemString:     func(item utils.EmailAddress) string { return argAliasEmail.String() + " -> " + item.String() },
 			FailureMessage: "failed to create alias target",
 			SuccessMessage: "Successfully created alias target",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/gerolf-vent/mailctl/internal/utils"
@@ -14,34 +13,61 @@ var CreateDomainCatchallTargetsCmd = &cobra.Command{
 	Aliases: []string{"catchall-target", "catchalls", "catchall"},
 	Short:   "Creates new catch-all targets for a domain",
 	Long:    "Creates new catch-all targets for a domain.",
-	Args:    cobra.MinimumNArgs(2),
+	Args:    ItemArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		flagForward, _ := cmd.Flags().GetBool("forward")
-		flagFallbackOnly, _ := cmd.Flags().GetBool("fallback-only")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
-		argDomain := args[0]
-		argEmails := ParseEmailArgs(args[1:])
-		if len(argEmails) != len(args)-1 {
-			return fmt.Errorf("invalid email arguments")
+		rows, err := ReadItemRows(cmd, args, []string{"domain", "target"}, "forward", "fallback-only")
+		if err != nil {
+			return err
 		}
 
-		options := db.DomainsCatchallTargetsCreateOptions{
-			ForwardEnabled: flagForward,
-			FallbackOnly:   flagFallbackOnly,
+		type catchallTargetItem struct {
+			domain      string
+			targetEmail utils.EmailAddress
+			options     db.DomainsCatchallTargetsCreateOptions
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddress]{
-			Items: argEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddress) error {
-				return db.DomainsCatchallTargets(tx).Create(argDomain, item, options)
+		items := make([]catchallTargetItem, 0, len(rows))
+		for _, row := range rows {
+			targetEmail, err := row.Email(1)
+			if err != nil {
+				return err
+			}
+
+			flagForward, _ := row.GetBool("forward")
+			flagFallbackOnly, _ := row.GetBool("fallback-only")
+
+			items = append(items, catchallTargetItem{
+				domain:      row.Args[0],
+				targetEmail: targetEmail,
+				options: db.DomainsCatchallTargetsCreateOptions{
+					ForwardEnabled: flagForward,
+					FallbackOnly:   flagFallbackOnly,
+				},
+			})
+		}
+
+		runner := db.TxForEachRunner[catchallTargetItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item catchallTargetItem) error {
+				return db.DomainsCatchallTargets(tx).Create(item.domain, item.targetEmail, item.options)
+			},
+			ItemString: func(item catchallTargetItem) string {
+				return "@" + item.domain + " -> " + item.targetEmail.String()
 			},
-			ItemString:     func(item utils.EmailAddress) string { return "@" + argDomain + " -> " + item.String() },
 			FailureMessage: "failed to create catchall target",
 			SuccessMessage: "Successfully created catchall target",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
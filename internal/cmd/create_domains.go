@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 	"strings"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
@@ -14,54 +13,78 @@ var CreateDomainsCmd = &cobra.Command{
 	Aliases: []string{"domain"},
 	Short:   "Creates new domains",
 	Long:    "Creates new domains. Supported domain types are 'managed', 'relayed', 'alias' and 'canonical'.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		flagDisabled, _ := cmd.Flags().GetBool("disabled")
-		flagType, _ := cmd.Flags().GetString("type")
-		flagTransport, _ := cmd.Flags().GetString("transport")
-		flagTargetDomain, _ := cmd.Flags().GetString("target-domain")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
-		domainType := strings.ToLower(flagType)
-		switch domainType {
-		case "managed", "relayed":
-			if flagTransport == "" {
-				return fmt.Errorf("transport name is required for %s domains", domainType)
-			}
-		case "canonical":
-			if flagTargetDomain == "" {
-				return fmt.Errorf("target domain FQDN is required for canonical domains")
-			}
-		case "alias":
-			// No specific flags required
-		default:
-			return fmt.Errorf("invalid domain type: %s (must be 'managed', 'relayed', 'alias' or 'canonical')", flagType)
+		rows, err := ReadItemRows(cmd, args, []string{"fqdn"}, "type", "transport", "target-domain", "disabled")
+		if err != nil {
+			return err
 		}
 
-		// Parse and validate all domain FQDNs
-		argDomains := ParseDomainFQDNArgs(args)
-		if len(argDomains) != len(args) {
-			return fmt.Errorf("invalid domain arguments")
+		type domainItem struct {
+			fqdn    string
+			options db.DomainsCreateOptions
 		}
 
-		options := db.DomainsCreateOptions{
-			DomainType:       domainType,
-			TransportName:    flagTransport,
-			TargetDomainFQDN: flagTargetDomain,
-			Enabled:          !flagDisabled,
+		items := make([]domainItem, 0, len(rows))
+		for _, row := range rows {
+			flagDisabled, _ := row.GetBool("disabled")
+			flagType, _ := row.GetString("type")
+			flagTransport, _ := row.GetString("transport")
+			flagTargetDomain, _ := row.GetString("target-domain")
+
+			domainType := strings.ToLower(flagType)
+			switch domainType {
+			case "managed", "relayed":
+				if flagTransport == "" {
+					return row.Errorf("transport name is required for %s domains", domainType)
+				}
+			case "canonical":
+				if flagTargetDomain == "" {
+					return row.Errorf("target domain FQDN is required for canonical domains")
+				}
+			case "alias":
+				// No specific flags required
+			default:
+				return row.Errorf("invalid domain type: %s (must be 'managed', 'relayed', 'alias' or 'canonical')", flagType)
+			}
+
+			// Parse and validate the domain FQDN
+			fqdn, err := row.DomainFQDN(0)
+			if err != nil {
+				return err
+			}
+
+			items = append(items, domainItem{
+				fqdn: fqdn,
+				options: db.DomainsCreateOptions{
+					DomainType:       domainType,
+					TransportName:    flagTransport,
+					TargetDomainFQDN: flagTargetDomain,
+					Enabled:          !flagDisabled,
+				},
+			})
 		}
 
-		runner := db.TxForEachRunner[string]{
-			Items: argDomains,
-			Exec: func(tx *sql.Tx, item string) error {
-				return db.Domains(tx).Create(item, options)
+		runner := db.TxForEachRunner[domainItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item domainItem) error {
+				return db.Domains(tx).Create(item.fqdn, item.options)
 			},
-			ItemString:     func(item string) string { return item },
+			ItemString:     func(item domainItem) string { return item.fqdn },
 			FailureMessage: "failed to create domain",
 			SuccessMessage: "Successfully created domain",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
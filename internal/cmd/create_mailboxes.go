@@ -14,69 +14,105 @@ var CreateMailboxesCmd = &cobra.Command{
 	Aliases: []string{"mailbox"},
 	Short:   "Creates new mailboxes",
 	Long:    "Creates new mailboxes.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagPassword, _ := cmd.Flags().GetBool("password")
 		flagPasswordStdin, _ := cmd.Flags().GetBool("password-stdin")
 		flagPasswordMethod, _ := cmd.Flags().GetString("password-method")
 		flagPasswordHashOptions, _ := cmd.Flags().GetString("password-hash-options")
-		flagQuota, _ := cmd.Flags().GetInt32("quota")
-		flagTransportName, _ := cmd.Flags().GetString("transport")
-		flagLoginDisabled, _ := cmd.Flags().GetBool("login-disabled")
-		flagReceivingDisabled, _ := cmd.Flags().GetBool("receiving-disabled")
-		flagSendingDisabled, _ := cmd.Flags().GetBool("sending-disabled")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
 		if flagPassword && flagPasswordStdin {
 			return fmt.Errorf("cannot use both --password and --password-stdin")
 		}
 
-		if len(args) > 1 && (flagPassword || flagPasswordStdin) {
-			return fmt.Errorf("cannot set password while creating multiple mailboxes")
+		if (flagPassword || flagPasswordStdin) && cmd.Flags().Changed("password-hash") {
+			return fmt.Errorf("cannot use --password-hash with --password or --password-stdin")
 		}
 
-		argEmails := ParseEmailArgs(args)
-		if len(argEmails) != len(args) {
-			return fmt.Errorf("invalid email arguments")
+		rows, err := ReadItemRows(cmd, args, []string{"email"},
+			"password-hash", "quota", "transport", "login-disabled", "receiving-disabled", "sending-disabled")
+		if err != nil {
+			return err
 		}
 
-		options := db.MailboxesCreateOptions{
-			LoginEnabled:     !flagLoginDisabled,
-			ReceivingEnabled: !flagReceivingDisabled,
-			SendingEnabled:   !flagSendingDisabled,
+		if len(rows) > 1 && (flagPassword || flagPasswordStdin) {
+			return fmt.Errorf("cannot set password while creating multiple mailboxes")
 		}
 
+		var passwordHash string
 		if flagPassword || flagPasswordStdin {
-			passwordHash, err := ReadPasswordHashed(flagPasswordMethod, flagPasswordHashOptions, flagPasswordStdin)
+			passwordHash, err = ReadPasswordHashed(flagPasswordMethod, flagPasswordHashOptions, flagPasswordStdin)
 			if err != nil {
 				utils.PrintErrorWithMessage("failed to read password", err)
-				return nil
+				return utils.ExitError{Code: 1}
 			}
-			options.PasswordHash.Valid = true
-			options.PasswordHash.String = passwordHash
 		}
 
-		if flagQuota > 0 {
-			options.Quota.Valid = true
-			options.Quota.Int32 = flagQuota
+		type mailboxItem struct {
+			email   utils.EmailAddress
+			options db.MailboxesCreateOptions
 		}
 
-		if flagTransportName != "" {
-			options.TransportName.Valid = true
-			options.TransportName.String = flagTransportName
+		items := make([]mailboxItem, 0, len(rows))
+		for _, row := range rows {
+			email, err := row.Email(0)
+			if err != nil {
+				return err
+			}
+
+			flagPasswordHash, _ := row.GetString("password-hash")
+			flagQuota, _ := row.GetInt32("quota")
+			flagTransportName, _ := row.GetString("transport")
+			flagLoginDisabled, _ := row.GetBool("login-disabled")
+			flagReceivingDisabled, _ := row.GetBool("receiving-disabled")
+			flagSendingDisabled, _ := row.GetBool("sending-disabled")
+
+			options := db.MailboxesCreateOptions{
+				LoginEnabled:     !flagLoginDisabled,
+				ReceivingEnabled: !flagReceivingDisabled,
+				SendingEnabled:   !flagSendingDisabled,
+			}
+
+			if passwordHash != "" {
+				options.PasswordHash.Valid = true
+				options.PasswordHash.String = passwordHash
+			} else if flagPasswordHash != "" {
+				options.PasswordHash.Valid = true
+				options.PasswordHash.String = flagPasswordHash
+			}
+
+			if flagQuota > 0 {
+				options.Quota.Valid = true
+				options.Quota.Int32 = flagQuota
+			}
+
+			if flagTransportName != "" {
+				options.TransportName.Valid = true
+				options.TransportName.String = flagTransportName
+			}
+
+			items = append(items, mailboxItem{email: email, options: options})
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddress]{
-			Items: argEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddress) error {
-				return db.Mailboxes(tx).Create(item, options)
+		runner := db.TxForEachRunner[mailboxItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item mailboxItem) error {
+				return db.Mailboxes(tx).Create(item.email, item.options)
 			},
-			ItemString:     func(item utils.EmailAddress) string { return item.String() },
+			ItemString:     func(item mailboxItem) string { return item.email.String() },
 			FailureMessage: "failed to create mailbox",
 			SuccessMessage: "Successfully created mailbox",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
@@ -85,6 +121,7 @@ func init() {
 	CreateMailboxesCmd.Flags().String("password-method", "argon2id", "Password hashing method (default: \"argon2id\", options: \"bcrypt\" or \"argon2id\")")
 	CreateMailboxesCmd.Flags().String("password-hash-options", "", "Password hash options (bcrypt: <cost>; argon2id: m=<number>,t=<number>,p=<number>)")
 	CreateMailboxesCmd.Flags().Bool("password-stdin", false, "Read password from stdin")
+	CreateMailboxesCmd.Flags().String("password-hash", "", "Set an already hashed password (bcrypt or argon2id)")
 	CreateMailboxesCmd.Flags().Int32("quota", 0, "Mailbox quota in bytes")
 	CreateMailboxesCmd.Flags().String("transport", "", "Transport name for this mailbox")
 	CreateMailboxesCmd.Flags().BoolP("login-disabled", "l", false, "Disable login (authentication)")
@@ -14,50 +14,87 @@ var CreateRemotesCmd = &cobra.Command{
 	Aliases: []string{"remote"},
 	Short:   "Creates new remotes",
 	Long:    "Creates new remotes.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagPassword, _ := cmd.Flags().GetBool("password")
 		flagPasswordStdin, _ := cmd.Flags().GetBool("password-stdin")
 		flagPasswordMethod, _ := cmd.Flags().GetString("password-method")
 		flagPasswordHashOptions, _ := cmd.Flags().GetString("password-hash-options")
-		flagDisabled, _ := cmd.Flags().GetBool("disabled")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
 		if flagPassword && flagPasswordStdin {
 			return fmt.Errorf("cannot use both --password and --password-stdin")
 		}
 
-		if len(args) > 1 && (flagPassword || flagPasswordStdin) {
-			return fmt.Errorf("cannot set password while creating multiple remotes")
+		if (flagPassword || flagPasswordStdin) && cmd.Flags().Changed("password-hash") {
+			return fmt.Errorf("cannot use --password-hash with --password or --password-stdin")
+		}
+
+		rows, err := ReadItemRows(cmd, args, []string{"name"}, "password-hash", "disabled")
+		if err != nil {
+			return err
 		}
 
-		options := db.RemotesCreateOptions{
-			Enabled: !flagDisabled,
+		if len(rows) > 1 && (flagPassword || flagPasswordStdin) {
+			return fmt.Errorf("cannot set password while creating multiple remotes")
 		}
 
+		var passwordHash string
 		if flagPassword || flagPasswordStdin {
-			passwordHash, err := ReadPasswordHashed(flagPasswordMethod, flagPasswordHashOptions, flagPasswordStdin)
+			passwordHash, err = ReadPasswordHashed(flagPasswordMethod, flagPasswordHashOptions, flagPasswordStdin)
 			if err != nil {
 				utils.PrintErrorWithMessage("failed to read password", err)
-				return nil
+				return utils.ExitError{Code: 1}
+			}
+		}
+
+		type remoteItem struct {
+			name    string
+			options db.RemotesCreateOptions
+		}
+
+		items := make([]remoteItem, 0, len(rows))
+		for _, row := range rows {
+			flagPasswordHash, _ := row.GetString("password-hash")
+			flagDisabled, _ := row.GetBool("disabled")
+
+			options := db.RemotesCreateOptions{
+				Enabled: !flagDisabled,
 			}
-			options.PasswordHash = sql.NullString{
-				String: passwordHash,
-				Valid:  true,
+
+			if passwordHash != "" {
+				options.PasswordHash = sql.NullString{
+					String: passwordHash,
+					Valid:  true,
+				}
+			} else if flagPasswordHash != "" {
+				options.PasswordHash = sql.NullString{
+					String: flagPasswordHash,
+					Valid:  true,
+				}
 			}
+
+			items = append(items, remoteItem{name: row.Args[0], options: options})
 		}
 
-		runner := db.TxForEachRunner[string]{
-			Items: args,
-			Exec: func(tx *sql.Tx, item string) error {
-				return db.Remotes(tx).Create(item, options)
+		runner := db.TxForEachRunner[remoteItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item remoteItem) error {
+				return db.Remotes(tx).Create(item.name, item.options)
 			},
-			ItemString:     func(item string) string { return item },
+			ItemString:     func(item remoteItem) string { return item.name },
 			FailureMessage: "failed to create remote",
 			SuccessMessage: "Successfully created remote",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
@@ -66,5 +103,6 @@ func init() {
 	CreateRemotesCmd.Flags().String("password-method", "bcrypt", "Password hashing method (default: \"bcrypt\", options: \"bcrypt\" or \"argon2id\")")
 	CreateRemotesCmd.Flags().String("password-hash-options", "", "Password hash options (bcrypt: <cost>; argon2id: m=<number>,t=<number>,p=<number>)")
 	CreateRemotesCmd.Flags().Bool("password-stdin", false, "Read password from stdin")
+	CreateRemotesCmd.Flags().String("password-hash", "", "Set an already hashed password (bcrypt or argon2id)")
 	CreateRemotesCmd.Flags().BoolP("disabled", "d", false, "Create the remote in disabled state")
 }
@@ -8,45 +8,70 @@ import (
 )
 
 var CreateTransportsCmd = &cobra.Command{
-	Use:   "transport <name>",
-	Short: "Creates a new transport",
-	Long:  "Creates a new transport.",
-	Args:  cobra.ExactArgs(1),
+	Use:   "transport [flags] <name> [<name>...]",
+	Short: "Creates new transports",
+	Long:  "Creates new transports.",
+	Args:  ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		name := args[0]
-		flagMethod, _ := cmd.Flags().GetString("method")
-		flagHost, _ := cmd.Flags().GetString("host")
-		flagPort, _ := cmd.Flags().GetUint16("port")
-		flagMxLookup, _ := cmd.Flags().GetBool("mx-lookup")
-
-		options := db.TransportsCreateOptions{
-			Method:   flagMethod,
-			Host:     flagHost,
-			MxLookup: flagMxLookup,
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+
+		rows, err := ReadItemRows(cmd, args, []string{"name"}, "method", "host", "port", "mx-lookup")
+		if err != nil {
+			return err
+		}
+
+		type transportItem struct {
+			name    string
+			options db.TransportsCreateOptions
 		}
-		if flagPort > 0 {
-			options.Port = sql.NullInt32{Int32: int32(flagPort), Valid: true}
+
+		items := make([]transportItem, 0, len(rows))
+		for _, row := range rows {
+			flagMethod, _ := row.GetString("method")
+			flagHost, _ := row.GetString("host")
+			flagPort, _ := row.GetUint16("port")
+			flagMxLookup, _ := row.GetBool("mx-lookup")
+
+			if flagMethod == "" || flagHost == "" {
+				return row.Errorf("method and host are required for transports")
+			}
+
+			options := db.TransportsCreateOptions{
+				Method:   flagMethod,
+				Host:     flagHost,
+				MxLookup: flagMxLookup,
+			}
+			if flagPort > 0 {
+				options.Port = sql.NullInt32{Int32: int32(flagPort), Valid: true}
+			}
+
+			items = append(items, transportItem{name: row.Args[0], options: options})
 		}
 
-		runner := db.TxRunner{
-			Exec: func(tx *sql.Tx) error {
-				return db.Transports(tx).Create(name, options)
+		runner := db.TxForEachRunner[transportItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item transportItem) error {
+				return db.Transports(tx).Create(item.name, item.options)
 			},
-			ItemString:     name,
+			ItemString:     func(item transportItem) string { return item.name },
 			FailureMessage: "failed to create transport",
 			SuccessMessage: "Successfully created transport",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
 func init() {
 	CreateTransportsCmd.Flags().StringP("method", "m", "", "Transport method (required, e.g. 'lmtp', 'smtp', or 'relay')")
-	CreateTransportsCmd.MarkFlagRequired("method")
 	CreateTransportsCmd.Flags().String("host", "", "Remote server hostname (required)")
-	CreateTransportsCmd.MarkFlagRequired("host")
 	CreateTransportsCmd.Flags().Uint16("port", 0, "Transport port")
 	CreateTransportsCmd.Flags().Bool("mx-lookup", false, "Enable MX lookup")
 }
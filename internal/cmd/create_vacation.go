@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var CreateVacationCmd = &cobra.Command{
+	Use:     "vacation <email> [<email>...]",
+	Aliases: []string{"vacations"},
+	Short:   "Creates vacation autoresponders of mailboxes",
+	Long:    "Creates vacation autoresponders of mailboxes, which answer incoming mails with the subject and body between the start and end date.",
+	Args:    ItemArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+
+		rows, err := ReadItemRows(cmd, args, []string{"email"},
+			"subject", "body", "body-file", "start", "end", "reply-interval", "exclude", "disabled")
+		if err != nil {
+			return err
+		}
+
+		if flagBodyFile, _ := cmd.Flags().GetString("body-file"); flagBodyFile == "-" && len(rows) > 1 {
+			return fmt.Errorf("cannot read body from stdin while creating multiple vacation autoresponders")
+		}
+
+		type vacationItem struct {
+			email   utils.EmailAddress
+			options db.MailboxesVacationCreateOptions
+		}
+
+		items := make([]vacationItem, 0, len(rows))
+		for _, row := range rows {
+			email, err := row.Email(0)
+			if err != nil {
+				return err
+			}
+
+			flagSubject, _ := row.GetString("subject")
+			flagStart, _ := row.GetString("start")
+			flagEnd, _ := row.GetString("end")
+			flagExclude, _ := row.GetStringSlice("exclude")
+			flagDisabled, _ := row.GetBool("disabled")
+
+			if flagSubject == "" {
+				return row.Errorf("missing subject of %s, use --subject", email.String())
+			}
+
+			body, ok, err := readVacationBody(row)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return row.Errorf("missing body of %s, use --body or --body-file", email.String())
+			}
+
+			options := db.MailboxesVacationCreateOptions{
+				Enabled: !flagDisabled,
+				Subject: flagSubject,
+				Body:    body,
+			}
+
+			if options.StartDate, err = parseVacationDate(flagStart); err != nil {
+				return row.Errorf("%w", err)
+			}
+			if options.EndDate, err = parseVacationDate(flagEnd); err != nil {
+				return row.Errorf("%w", err)
+			}
+			if options.StartDate.Valid && options.EndDate.Valid && options.EndDate.Time.Before(options.StartDate.Time) {
+				return row.Errorf("end date must not be before the start date")
+			}
+
+			if row.Changed("reply-interval") {
+				flagReplyInterval, _ := row.GetString("reply-interval")
+				replyInterval, err := parseVacationReplyInterval(flagReplyInterval)
+				if err != nil {
+					return row.Errorf("%w", err)
+				}
+				options.ReplyInterval = &replyInterval
+			}
+
+			if options.ExcludedSenders, err = parseVacationExcludedSenders(flagExclude); err != nil {
+				return row.Errorf("%w", err)
+			}
+
+			items = append(items, vacationItem{email: email, options: options})
+		}
+
+		runner := db.TxForEachRunner[vacationItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item vacationItem) error {
+				return db.MailboxesVacation(tx).Create(item.email, item.options)
+			},
+			ItemString:     func(item vacationItem) string { return item.email.String() },
+			FailureMessage: "failed to create vacation autoresponder",
+			SuccessMessage: "Successfully created vacation autoresponder",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	CreateVacationCmd.Flags().String("subject", "", "Subject of the replies")
+	CreateVacationCmd.Flags().String("body", "", "Body of the replies")
+	CreateVacationCmd.Flags().String("body-file", "", "Read the body of the replies from a file ('-' for stdin)")
+	CreateVacationCmd.Flags().String("start", "", "First day of the vacation (YYYY-MM-DD, default: none)")
+	CreateVacationCmd.Flags().String("end", "", "Last day of the vacation (YYYY-MM-DD, default: none)")
+	CreateVacationCmd.Flags().String("reply-interval", "7d", "Minimum interval between two replies to the same sender (e.g. \"1d\", \"12h\")")
+	CreateVacationCmd.Flags().StringSlice("exclude", nil, "Senders, which are never answered (mail addresses or \"@domain\")")
+	CreateVacationCmd.Flags().BoolP("disabled", "d", false, "Create the vacation autoresponder disabled")
+}
@@ -11,6 +11,12 @@ func init() {
 	// Add common flags
 	DeleteCmd.PersistentFlags().BoolP("permanent", "p", false, "Perform permanent deletion instead of soft delete")
 	DeleteCmd.PersistentFlags().BoolP("force", "f", false, "Delete even if already soft-deleted")
+	DeleteCmd.PersistentFlags().Bool("dry-run", false, "Show the affected rows (including cascaded changes) without committing")
+	DeleteCmd.PersistentFlags().BoolP("json", "j", false, "Output the result of each item in JSON format")
+	DeleteCmd.PersistentFlags().Bool("atomic", false, "Process all items in a single transaction, which is rolled back on the first failure")
+	DeleteCmd.PersistentFlags().Int("parallel", 1, "Process up to this many items concurrently, each in its own transaction")
+	DeleteCmd.MarkFlagsMutuallyExclusive("atomic", "parallel")
+	DeleteCmd.PersistentFlags().String("from-file", "", "Read the items from a CSV, JSONL or newline-separated file ('-' for stdin)")
 
 	// Add subcommands
 	DeleteCmd.AddCommand(DeleteDomainsCmd)
@@ -22,4 +28,5 @@ func init() {
 	DeleteCmd.AddCommand(DeleteTransportsCmd)
 	DeleteCmd.AddCommand(DeleteRemotesCmd)
 	DeleteCmd.AddCommand(DeleteRemoteSendGrantsCmd)
+	DeleteCmd.AddCommand(DeleteVacationCmd)
 }
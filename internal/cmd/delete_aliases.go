@@ -14,18 +14,22 @@ var DeleteAliasesCmd = &cobra.Command{
 	Aliases: []string{"alias"},
 	Short:   "Deletes aliases",
 	Long:    "Deletes aliases. By default performs a soft delete. Use --permanent for hard delete.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagPermanent, _ := cmd.Flags().GetBool("permanent")
 		flagForce, _ := cmd.Flags().GetBool("force")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
 		if flagPermanent && flagForce {
 			return fmt.Errorf("cannot use --permanent and --force flags together")
 		}
 
-		argEmails := ParseEmailArgs(args)
-		if len(argEmails) != len(args) {
-			return fmt.Errorf("invalid email arguments")
+		argEmails, err := ReadItemEmails(cmd, args, "email")
+		if err != nil {
+			return err
 		}
 
 		options := db.DeleteOptions{
@@ -41,13 +45,16 @@ var DeleteAliasesCmd = &cobra.Command{
 			ItemString:     func(item utils.EmailAddress) string { return item.String() },
 			FailureMessage: "failed to delete alias",
 			SuccessMessage: "Successfully deleted alias",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
 		if flagPermanent {
 			runner.SuccessMessage += " permanently"
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
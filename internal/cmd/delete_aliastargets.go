@@ -14,43 +14,68 @@ var DeleteAliasTargetsCmd = &cobra.Command{
 	Aliases: []string{"alias-target", "targets", "target"},
 	Short:   "Deletes alias targets from an alias",
 	Long:    `Deletes alias targets from an alias. By default performs a soft delete. Use --permanent for hard delete.`,
-	Args:    cobra.MinimumNArgs(2),
+	Args:    ItemArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagPermanent, _ := cmd.Flags().GetBool("permanent")
 		flagForce, _ := cmd.Flags().GetBool("force")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
 		if flagPermanent && flagForce {
 			return fmt.Errorf("cannot use --permanent and --force flags together")
 		}
 
-		argEmails := ParseEmailArgs(args)
-		if len(argEmails) != len(args) {
-			return fmt.Errorf("invalid email arguments")
+		rows, err := ReadItemRows(cmd, args, []string{"alias", "target"})
+		if err != nil {
+			return err
 		}
 
-		argAliasEmail := argEmails[0]
-		argTargetEmails := argEmails[1:]
+		type aliasTargetItem struct {
+			aliasEmail  utils.EmailAddress
+			targetEmail utils.EmailAddress
+		}
+
+		items := make([]aliasTargetItem, 0, len(rows))
+		for _, row := range rows {
+			aliasEmail, err := row.Email(0)
+			if err != nil {
+				return err
+			}
+			targetEmail, err := row.Email(1)
+			if err != nil {
+				return err
+			}
+
+			items = append(items, aliasTargetItem{aliasEmail: aliasEmail, targetEmail: targetEmail})
+		}
 
 		options := db.DeleteOptions{
 			Permanent: flagPermanent,
 			Force:     flagForce,
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddress]{
-			Items: argTargetEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddress) error {
-				return db.AliasesTargets(tx).Delete(argAliasEmail, item, options)
+		runner := db.TxForEachRunner[aliasTargetItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item aliasTargetItem) error {
+				return db.AliasesTargets(tx).Delete(item.aliasEmail, item.targetEmail, options)
+			},
+			ItemString: func(item aliasTargetItem) string {
+				return item.aliasEmail.String() + " -> " + item.targetEmail.String()
 			},
-			ItemString:     func(item utils.EmailAddress) string { return argAliasEmail.String() + " -> " + item.String() },
 			FailureMessage: "failed to delete alias target",
 			SuccessMessage: "Successfully deleted alias target",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
 		if flagPermanent {
 			runner.SuccessMessage += " permanently"
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
@@ -14,19 +14,37 @@ var DeleteDomainCatchallTargetsCmd = &cobra.Command{
 	Aliases: []string{"catchall-target", "catchalls", "catchall"},
 	Short:   "Deletes catch-all targets from a domain",
 	Long:    "Deletes catch-all targets from a domain. By default performs a soft delete. Use --permanent for hard delete.",
-	Args:    cobra.MinimumNArgs(2),
+	Args:    ItemArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagPermanent, _ := cmd.Flags().GetBool("permanent")
 		flagForce, _ := cmd.Flags().GetBool("force")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
 		if flagPermanent && flagForce {
 			return fmt.Errorf("cannot use --permanent and --force flags together")
 		}
 
-		argDomain := args[0]
-		argEmails := ParseEmailArgs(args[1:])
-		if len(argEmails) != len(args)-1 {
-			return fmt.Errorf("invalid email arguments")
+		rows, err := ReadItemRows(cmd, args, []string{"domain", "target"})
+		if err != nil {
+			return err
+		}
+
+		type catchallTargetItem struct {
+			domain      string
+			targetEmail utils.EmailAddress
+		}
+
+		items := make([]catchallTargetItem, 0, len(rows))
+		for _, row := range rows {
+			targetEmail, err := row.Email(1)
+			if err != nil {
+				return err
+			}
+
+			items = append(items, catchallTargetItem{domain: row.Args[0], targetEmail: targetEmail})
 		}
 
 		options := db.DeleteOptions{
@@ -34,21 +52,24 @@ var DeleteDomainCatchallTargetsCmd = &cobra.Command{
 			Force:     flagForce,
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddress]{
-			Items: argEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddress) error {
-				return db.DomainsCatchallTargets(tx).Delete(argDomain, item, options)
+		runner := db.TxForEachRunner[catchallTargetItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item catchallTargetItem) error {
+				return db.DomainsCatchallTargets(tx).Delete(item.domain, item.targetEmail, options)
 			},
-			ItemString:     func(item utils.EmailAddress) string { return "@" + argDomain + " -> " + item.String() },
+			ItemString:     func(item catchallTargetItem) string { return "@" + item.domain + " -> " + item.targetEmail.String() },
 			FailureMessage: "failed to delete catchall target",
 			SuccessMessage: "Successfully deleted catchall target",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
 		if flagPermanent {
 			runner.SuccessMessage += " permanently"
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
@@ -12,19 +12,25 @@ var DeleteDomainsCmd = &cobra.Command{
 	Use:     "domains [flags] <fqdn> [<fqdn>...]",
 	Aliases: []string{"domain"},
 	Short:   "Deletes domains",
-	Long:    "Deletes domains. By default performs a soft delete. Use --permanent for hard delete.",
-	Args:    cobra.MinimumNArgs(1),
+	Long:    "Deletes domains. By default performs a soft delete. Use --permanent for hard delete.\nDependent objects affected by the cascade are listed first, many of them require a confirmation.",
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagPermanent, _ := cmd.Flags().GetBool("permanent")
 		flagForce, _ := cmd.Flags().GetBool("force")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+		flagYes, _ := cmd.Flags().GetBool("yes")
+		flagConfirmThreshold, _ := cmd.Flags().GetInt("confirm-threshold")
 
 		if flagPermanent && flagForce {
 			return fmt.Errorf("cannot use --permanent and --force flags together")
 		}
 
-		argDomains := ParseDomainFQDNArgs(args)
-		if len(argDomains) != len(args) {
-			return fmt.Errorf("invalid domain arguments")
+		argDomains, err := ReadItemDomainFQDNs(cmd, args, "fqdn")
+		if err != nil {
+			return err
 		}
 
 		options := db.DeleteOptions{
@@ -40,13 +46,26 @@ var DeleteDomainsCmd = &cobra.Command{
 			ItemString:     func(item string) string { return item },
 			FailureMessage: "failed to delete domain",
 			SuccessMessage: "Successfully deleted domain",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
+			Impact: &db.ImpactOptions{
+				Action:    "Deleting domain",
+				Threshold: flagConfirmThreshold,
+				Yes:       flagYes,
+			},
 		}
 
 		if flagPermanent {
 			runner.SuccessMessage += " permanently"
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
+
+func init() {
+	DeleteDomainsCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation, if many dependent objects are deleted as well")
+	DeleteDomainsCmd.Flags().Int("confirm-threshold", 10, "Ask for confirmation, if more dependent objects are deleted as well")
+}
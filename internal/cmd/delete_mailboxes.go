@@ -13,19 +13,25 @@ var DeleteMailboxesCmd = &cobra.Command{
 	Use:     "mailboxes [flags] <email> [<email>...]",
 	Aliases: []string{"mailbox"},
 	Short:   "Deletes mailboxes",
-	Long:    "Deletes mailboxes. By default performs a soft delete. Use --permanent for hard delete.",
-	Args:    cobra.MinimumNArgs(1),
+	Long:    "Deletes mailboxes. By default performs a soft delete. Use --permanent for hard delete.\nDependent objects affected by the cascade are listed first, many of them require a confirmation.",
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagPermanent, _ := cmd.Flags().GetBool("permanent")
 		flagForce, _ := cmd.Flags().GetBool("force")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+		flagYes, _ := cmd.Flags().GetBool("yes")
+		flagConfirmThreshold, _ := cmd.Flags().GetInt("confirm-threshold")
 
 		if flagPermanent && flagForce {
 			return fmt.Errorf("cannot use --permanent and --force flags together")
 		}
 
-		argEmails := ParseEmailArgs(args)
-		if len(argEmails) != len(args) {
-			return fmt.Errorf("invalid email arguments")
+		argEmails, err := ReadItemEmails(cmd, args, "email")
+		if err != nil {
+			return err
 		}
 
 		options := db.DeleteOptions{
@@ -41,13 +47,26 @@ var DeleteMailboxesCmd = &cobra.Command{
 			ItemString:     func(item utils.EmailAddress) string { return item.String() },
 			FailureMessage: "failed to delete mailbox",
 			SuccessMessage: "Successfully deleted mailbox",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
+			Impact: &db.ImpactOptions{
+				Action:    "Deleting mailbox",
+				Threshold: flagConfirmThreshold,
+				Yes:       flagYes,
+			},
 		}
 
 		if flagPermanent {
 			runner.SuccessMessage += " permanently"
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
+
+func init() {
+	DeleteMailboxesCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation, if many dependent objects are deleted as well")
+	DeleteMailboxesCmd.Flags().Int("confirm-threshold", 10, "Ask for confirmation, if more dependent objects are deleted as well")
+}
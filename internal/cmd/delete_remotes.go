@@ -12,10 +12,19 @@ var DeleteRemotesCmd = &cobra.Command{
 	Aliases: []string{"remote"},
 	Short:   "Deletes remotes",
 	Long:    "Deletes remotes. By default performs a soft delete. Use --permanent for hard delete.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagPermanent, _ := cmd.Flags().GetBool("permanent")
 		flagForce, _ := cmd.Flags().GetBool("force")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+
+		argNames, err := ReadItemNames(cmd, args, "name")
+		if err != nil {
+			return err
+		}
 
 		options := db.DeleteOptions{
 			Permanent: flagPermanent,
@@ -23,16 +32,19 @@ var DeleteRemotesCmd = &cobra.Command{
 		}
 
 		runner := db.TxForEachRunner[string]{
-			Items: args,
+			Items: argNames,
 			Exec: func(tx *sql.Tx, item string) error {
 				return db.Remotes(tx).Delete(item, options)
 			},
 			ItemString:     func(item string) string { return item },
 			FailureMessage: "failed to delete remote",
 			SuccessMessage: "Successfully deleted remote",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
@@ -14,19 +14,37 @@ var DeleteRemoteSendGrantsCmd = &cobra.Command{
 	Aliases: []string{"send-grant"},
 	Short:   "Deletes send grants from a remote",
 	Long:    "Deletes send grants from a remote. By default performs a soft delete. Use --permanent --force for hard delete.",
-	Args:    cobra.MinimumNArgs(2),
+	Args:    ItemArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagPermanent, _ := cmd.Flags().GetBool("permanent")
 		flagForce, _ := cmd.Flags().GetBool("force")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
 		if flagPermanent && flagForce {
 			return fmt.Errorf("cannot use --permanent and --force flags together")
 		}
 
-		argRemoteName := args[0]
-		argEmails := ParseEmailOrWildcardArgs(args[1:])
-		if len(argEmails) != len(args[1:]) {
-			return fmt.Errorf("invalid email argument")
+		rows, err := ReadItemRows(cmd, args, []string{"remote", "email"})
+		if err != nil {
+			return err
+		}
+
+		type sendGrantItem struct {
+			remoteName string
+			email      utils.EmailAddressOrWildcard
+		}
+
+		items := make([]sendGrantItem, 0, len(rows))
+		for _, row := range rows {
+			email, err := row.EmailOrWildcard(1)
+			if err != nil {
+				return err
+			}
+
+			items = append(items, sendGrantItem{remoteName: row.Args[0], email: email})
 		}
 
 		options := db.DeleteOptions{
@@ -34,17 +52,20 @@ var DeleteRemoteSendGrantsCmd = &cobra.Command{
 			Force:     flagForce,
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddressOrWildcard]{
-			Items: argEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddressOrWildcard) error {
-				return db.RemotesSendGrants(tx).Delete(argRemoteName, item, options)
+		runner := db.TxForEachRunner[sendGrantItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item sendGrantItem) error {
+				return db.RemotesSendGrants(tx).Delete(item.remoteName, item.email, options)
 			},
-			ItemString:     func(item utils.EmailAddressOrWildcard) string { return argRemoteName + " -> " + item.String() },
+			ItemString:     func(item sendGrantItem) string { return item.remoteName + " -> " + item.email.String() },
 			FailureMessage: "failed to delete send grant",
 			SuccessMessage: "Successfully deleted send grant",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"database/sql"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var DeleteVacationCmd = &cobra.Command{
+	Use:     "vacation <email> [<email>...]",
+	Aliases: []string{"vacations"},
+	Short:   "Deletes vacation autoresponders of mailboxes",
+	Long:    "Deletes vacation autoresponders of mailboxes. They are always deleted permanently, but can be restored from the audit log. Use patch vacation --enabled=false to keep them for later.",
+	Args:    ItemArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+
+		emails, err := ReadItemEmails(cmd, args, "email")
+		if err != nil {
+			return err
+		}
+
+		runner := db.TxForEachRunner[utils.EmailAddress]{
+			Items: emails,
+			Exec: func(tx *sql.Tx, email utils.EmailAddress) error {
+				return db.MailboxesVacation(tx).Delete(email)
+			},
+			ItemString:     func(email utils.EmailAddress) string { return email.String() },
+			FailureMessage: "failed to delete vacation autoresponder",
+			SuccessMessage: "Successfully deleted vacation autoresponder",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
+		}
+
+		return runner.Run()
+	},
+}
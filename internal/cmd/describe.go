@@ -12,11 +12,23 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// DescribeOptions controls which optional sections are rendered by the
+// describe helpers.
+type DescribeOptions struct {
+	History bool
+}
+
 var DescribeCmd = &cobra.Command{
 	Use:   "describe",
 	Short: "Describe mail system objects",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		flagHistory, _ := cmd.Flags().GetBool("history")
+
+		describeOptions := DescribeOptions{
+			History: flagHistory,
+		}
+
 		dbConn, err := db.Connect()
 		if err != nil {
 			utils.PrintErrorWithMessage("failed to connect to database", err)
@@ -39,7 +51,7 @@ var DescribeCmd = &cobra.Command{
 
 			if emailOrWildcard.IsWildcard() {
 				// A wildcard email address can only be a catchall address
-				if described, err := describeDomainscatchalltargets(dbConn, emailOrWildcard.DomainFQDN); described || err != nil {
+				if described, err := describeDomainscatchalltargets(dbConn, emailOrWildcard.DomainFQDN, describeOptions); described || err != nil {
 					if err != nil {
 						utils.PrintError(err)
 					}
@@ -54,7 +66,7 @@ var DescribeCmd = &cobra.Command{
 				}
 
 				// Try aliases first
-				if described, err := describeAliases(dbConn, email); described || err != nil {
+				if described, err := describeAliases(dbConn, email, describeOptions); described || err != nil {
 					if err != nil {
 						utils.PrintError(err)
 					}
@@ -62,7 +74,7 @@ var DescribeCmd = &cobra.Command{
 				}
 
 				// Try canonical addresses next
-				if described, err := DescribeCanonicalAddress(dbConn, email); described || err != nil {
+				if described, err := DescribeCanonicalAddress(dbConn, email, describeOptions); described || err != nil {
 					if err != nil {
 						utils.PrintError(err)
 					}
@@ -70,7 +82,7 @@ var DescribeCmd = &cobra.Command{
 				}
 
 				// Try mailboxes next
-				if described, err := describeMailboxes(dbConn, email); described || err != nil {
+				if described, err := describeMailboxes(dbConn, email, describeOptions); described || err != nil {
 					if err != nil {
 						utils.PrintError(err)
 					}
@@ -78,7 +90,7 @@ var DescribeCmd = &cobra.Command{
 				}
 
 				// Try relayed recipients last
-				if described, err := describeRecipientsrelayed(dbConn, email); described || err != nil {
+				if described, err := describeRecipientsrelayed(dbConn, email, describeOptions); described || err != nil {
 					if err != nil {
 						utils.PrintError(err)
 					}
@@ -93,7 +105,7 @@ var DescribeCmd = &cobra.Command{
 			// A plain string might be a fqdn (for domains) or a a name of a transport or remote
 
 			// Try domains first
-			if described, err := describeDomains(dbConn, args[0]); described || err != nil {
+			if described, err := describeDomains(dbConn, args[0], describeOptions); described || err != nil {
 				if err != nil {
 					utils.PrintError(err)
 				}
@@ -101,7 +113,7 @@ var DescribeCmd = &cobra.Command{
 			}
 
 			// Try transports next
-			if described, err := describeTransports(dbConn, args[0]); described || err != nil {
+			if described, err := describeTransports(dbConn, args[0], describeOptions); described || err != nil {
 				if err != nil {
 					utils.PrintError(err)
 				}
@@ -109,7 +121,7 @@ var DescribeCmd = &cobra.Command{
 			}
 
 			// Try remotes last
-			if described, err := describeRemotes(dbConn, args[0]); described || err != nil {
+			if described, err := describeRemotes(dbConn, args[0], describeOptions); described || err != nil {
 				if err != nil {
 					utils.PrintError(err)
 				}
@@ -235,3 +247,7 @@ func describeUnknown(r sq.BaseRunner, arg string) {
 	t = t.Row(headerStyle.Render("Functions") + "\n\n" + funcsT.Render())
 	fmt.Println(t.Render())
 }
+
+func init() {
+	DescribeCmd.Flags().Bool("history", false, "Show the audit log timeline of the object")
+}
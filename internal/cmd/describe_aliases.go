@@ -13,7 +13,7 @@ import (
 // Describe prints a detailed view for a single alias (name@domain).
 // Returns (true, nil) when the alias was found and printed, (false, nil) when
 // the alias was not found, or (true, err) on error.
-func describeAliases(r sq.BaseRunner, email utils.EmailAddress) (bool, error) {
+func describeAliases(r sq.BaseRunner, email utils.EmailAddress, describeOptions DescribeOptions) (bool, error) {
 	options := db.AliasesListOptions{
 		ByEmail:    &email,
 		IncludeAll: true,
@@ -157,6 +157,12 @@ func describeAliases(r sq.BaseRunner, email utils.EmailAddress) (bool, error) {
 		t.Row(headerStyle.Render("Targets") + "\n\n" + utils.BlackStyle.Render("No targets configured."))
 	}
 	t.Row(headerStyle.Render("Functions") + "\n\n" + funcsT.Render())
+	if describeOptions.History {
+		t.Row(headerStyle.Render("History") + "\n\n" + RenderHistorySection(r, db.AuditLogListOptions{
+			FilterObjects: []db.AuditLogObject{{Email: &email}},
+			FilterTables:  []string{"aliases", "aliases_targets_recursive", "aliases_targets_foreign"},
+		}))
+	}
 	fmt.Println(t.Render())
 	return true, nil
 }
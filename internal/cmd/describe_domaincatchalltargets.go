@@ -13,7 +13,7 @@ import (
 // Describe prints a detailed view for a catchall address (*@domain).
 // Returns (true, nil) when the catchall was found and printed, (false, nil) when
 // the catchall was not found, or (true, err) on error.
-func describeDomainscatchalltargets(r sq.BaseRunner, domain string) (bool, error) {
+func describeDomainscatchalltargets(r sq.BaseRunner, domain string, describeOptions DescribeOptions) (bool, error) {
 	options := db.DomainsCatchallTargetsListOptions{
 		FilterDomains: []string{domain},
 		IncludeAll:    true,
@@ -86,6 +86,12 @@ func describeDomainscatchalltargets(r sq.BaseRunner, domain string) (bool, error
 	t.Row(lipgloss.NewStyle().Bold(true).Render("Status: ") + statusStr)
 	t.Row(lipgloss.NewStyle().Bold(true).Render("Address: ") + utils.MaybeWildcardNameStyle.Render(nil) + "@" + domain)
 	t.Row(targetsT.Render())
+	if describeOptions.History {
+		t.Row(lipgloss.NewStyle().Bold(true).Render("History") + "\n\n" + RenderHistorySection(r, db.AuditLogListOptions{
+			FilterObjects: []db.AuditLogObject{{DomainFQDN: domain}},
+			FilterTables:  []string{"domains_catchall_targets"},
+		}))
+	}
 	fmt.Println(t.Render())
 	return true, nil
 }
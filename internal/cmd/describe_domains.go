@@ -11,7 +11,7 @@ import (
 	"github.com/gerolf-vent/mailctl/internal/utils"
 )
 
-func describeDomains(r sq.BaseRunner, fqdn string) (bool, error) {
+func describeDomains(r sq.BaseRunner, fqdn string, describeOptions DescribeOptions) (bool, error) {
 	options := db.DomainsListOptions{
 		ByFQDN:     fqdn,
 		IncludeAll: true,
@@ -208,11 +208,17 @@ func describeDomains(r sq.BaseRunner, fqdn string) (bool, error) {
 		t.Row(headerStyle.Render("References") + "\n\n" + referencesT.Render())
 	}
 	t.Row(headerStyle.Render("Functions") + "\n\n" + funcsT.Render())
+	if describeOptions.History {
+		t.Row(headerStyle.Render("History") + "\n\n" + RenderHistorySection(r, db.AuditLogListOptions{
+			FilterObjects: []db.AuditLogObject{{DomainFQDN: fqdn}},
+			FilterTables:  []string{"domains_managed", "domains_relayed", "domains_alias", "domains_canonical", "domains_catchall_targets"},
+		}))
+	}
 	fmt.Println(t.Render())
 	return true, nil
 }
 
-func DescribeCanonicalAddress(r sq.BaseRunner, email utils.EmailAddress) (bool, error) {
+func DescribeCanonicalAddress(r sq.BaseRunner, email utils.EmailAddress, describeOptions DescribeOptions) (bool, error) {
 	options := db.DomainsListOptions{
 		ByFQDN:     email.DomainFQDN,
 		IncludeAll: true,
@@ -296,6 +302,12 @@ func DescribeCanonicalAddress(r sq.BaseRunner, email utils.EmailAddress) (bool,
 	t.Row(headerStyle.Render("Status: ") + statusStr)
 	t.Row(headerStyle.Render("Properties") + "\n\n" + propT.Render())
 	t.Row(headerStyle.Render("Functions") + "\n\n" + funcsT.Render())
+	if describeOptions.History {
+		t.Row(headerStyle.Render("History") + "\n\n" + RenderHistorySection(r, db.AuditLogListOptions{
+			FilterObjects: []db.AuditLogObject{{DomainFQDN: email.DomainFQDN}},
+			FilterTables:  []string{"domains_canonical"},
+		}))
+	}
 	fmt.Println(t.Render())
 	return true, nil
 }
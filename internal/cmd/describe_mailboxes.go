@@ -13,7 +13,7 @@ import (
 // Describe prints a detailed view for a single mailbox (name@domain).
 // Returns (true, nil) when the mailbox was found and printed, (false, nil)
 // when the mailbox was not found, or (true, err) on error.
-func describeMailboxes(r sq.BaseRunner, email utils.EmailAddress) (bool, error) {
+func describeMailboxes(r sq.BaseRunner, email utils.EmailAddress, describeOptions DescribeOptions) (bool, error) {
 	options := db.MailboxesListOptions{
 		ByEmail:    &email,
 		IncludeAll: true,
@@ -107,6 +107,12 @@ func describeMailboxes(r sq.BaseRunner, email utils.EmailAddress) (bool, error)
 	t = t.Row(headerStyle.Render("Properties") + "\n\n" + propT.Render())
 	t = t.Row(headerStyle.Render("Meta") + "\n\n" + RenderMetaSection(mailbox.CreatedAt, mailbox.UpdatedAt, mailbox.DeletedAt))
 	t = t.Row(headerStyle.Render("Functions") + "\n\n" + funcsT.Render())
+	if describeOptions.History {
+		t = t.Row(headerStyle.Render("History") + "\n\n" + RenderHistorySection(r, db.AuditLogListOptions{
+			FilterObjects: []db.AuditLogObject{{Email: &email}},
+			FilterTables:  []string{"mailboxes"},
+		}))
+	}
 	fmt.Println(t.Render())
 	return true, nil
 }
@@ -13,7 +13,7 @@ import (
 // Describe prints a detailed view for a single relayed recipient (name@domain).
 // Returns (true, nil) when the recipient was found and printed, (false, nil)
 // when the recipient was not found, or (true, err) on error.
-func describeRecipientsrelayed(r sq.BaseRunner, email utils.EmailAddress) (bool, error) {
+func describeRecipientsrelayed(r sq.BaseRunner, email utils.EmailAddress, describeOptions DescribeOptions) (bool, error) {
 	options := db.RecipientsRelayedListOptions{
 		ByEmail:    &email,
 		IncludeAll: true,
@@ -103,6 +103,12 @@ func describeRecipientsrelayed(r sq.BaseRunner, email utils.EmailAddress) (bool,
 	t = t.Row(propT.Render())
 	t = t.Row(lipgloss.NewStyle().Bold(true).Render("Meta") + "\n\n" + RenderMetaSection(recipient.CreatedAt, recipient.UpdatedAt, recipient.DeletedAt))
 	t = t.Row(lipgloss.NewStyle().Bold(true).Render("Functions") + "\n\n" + funcsT.Render())
+	if describeOptions.History {
+		t = t.Row(lipgloss.NewStyle().Bold(true).Render("History") + "\n\n" + RenderHistorySection(r, db.AuditLogListOptions{
+			FilterObjects: []db.AuditLogObject{{Email: &email}},
+			FilterTables:  []string{"recipients_relayed"},
+		}))
+	}
 	fmt.Println(t.Render())
 	return true, nil
 }
@@ -13,7 +13,7 @@ import (
 // Describe prints a detailed view for a single remote by name.
 // Returns (true, nil) when the remote was found and printed, (false, nil) when
 // the remote was not found, or (true, err) on error.
-func describeRemotes(r sq.BaseRunner, name string) (bool, error) {
+func describeRemotes(r sq.BaseRunner, name string, describeOptions DescribeOptions) (bool, error) {
 	options := db.RemotesListOptions{
 		ByName:     name,
 		IncludeAll: true,
@@ -119,6 +119,12 @@ func describeRemotes(r sq.BaseRunner, name string) (bool, error) {
 	} else {
 		t.Row(headerStyle.Render("Send Grants") + "\n\n" + utils.BlackStyle.Render("No send grants configured."))
 	}
+	if describeOptions.History {
+		t.Row(headerStyle.Render("History") + "\n\n" + RenderHistorySection(r, db.AuditLogListOptions{
+			FilterObjects: []db.AuditLogObject{{Name: name}},
+			FilterTables:  []string{"remotes", "remotes_send_grants"},
+		}))
+	}
 	fmt.Println(t.Render())
 	return true, nil
 }
@@ -13,7 +13,7 @@ import (
 // Describe prints a detailed view for a single transport by name.
 // Returns (true, nil) when the transport was found and printed, (false, nil) when
 // the transport was not found, or (true, err) on error.
-func describeTransports(r sq.BaseRunner, name string) (bool, error) {
+func describeTransports(r sq.BaseRunner, name string, describeOptions DescribeOptions) (bool, error) {
 	options := db.TransportsListOptions{
 		ByName:     name,
 		IncludeAll: true,
@@ -109,6 +109,12 @@ func describeTransports(r sq.BaseRunner, name string) (bool, error) {
 	t.Row(headerStyle.Render("Properties") + "\n\n" + propT.Render())
 	t.Row(headerStyle.Render("Meta") + "\n\n" + RenderMetaSection(transport.CreatedAt, transport.UpdatedAt, transport.DeletedAt))
 	t.Row(headerStyle.Render("References") + "\n\n" + referencesT.Render())
+	if describeOptions.History {
+		t.Row(headerStyle.Render("History") + "\n\n" + RenderHistorySection(r, db.AuditLogListOptions{
+			FilterObjects: []db.AuditLogObject{{Name: name}},
+			FilterTables:  []string{"transports"},
+		}))
+	}
 	fmt.Println(t.Render())
 	return true, nil
 }
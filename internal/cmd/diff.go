@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/state"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the diff command (like 'diff' and 'kubectl diff')
+const (
+	diffExitDrift = 1
+	diffExitError = 2
+)
+
+var DiffCmd = &cobra.Command{
+	Use:   "diff -f <file> [flags]",
+	Short: "Show differences between a configuration file and the database",
+	Long: "Show a diff between the objects described in a declarative configuration file (see 'mailctl apply') and the database for each differing object.\n" +
+		"Exits with status 0 if there are no differences, 1 if there are differences and 2 if an error occurred.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagFile, _ := cmd.Flags().GetString("file")
+		flagPrune, _ := cmd.Flags().GetBool("prune")
+
+		desired, err := LoadStateFile(flagFile)
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to load "+flagFile, err)
+			return utils.ExitError{Code: diffExitError}
+		}
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: diffExitError}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		current, err := state.Current(dbConn, state.CurrentOptions{
+			IncludeDeleted:        true,
+			IncludePasswordHashes: true,
+		})
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to read objects", err)
+			return utils.ExitError{Code: diffExitError}
+		}
+
+		plan, err := state.NewPlan(current, desired, state.PlanOptions{Prune: flagPrune})
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to compute differences", err)
+			return utils.ExitError{Code: diffExitError}
+		}
+
+		diffs, err := plan.Diffs()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to render differences", err)
+			return utils.ExitError{Code: diffExitError}
+		}
+
+		for _, d := range diffs {
+			PrintObjectDiff(d, flagFile)
+		}
+
+		if len(diffs) > 0 {
+			return utils.ExitError{Code: diffExitDrift}
+		}
+		return nil
+	},
+}
+
+func init() {
+	DiffCmd.Flags().StringP("file", "f", "", "Configuration file (YAML or JSON, '-' for stdin)")
+	DiffCmd.Flags().Bool("prune", false, "Also show objects not described in the file (which 'apply --prune' deletes)")
+	_ = DiffCmd.MarkFlagRequired("file")
+}
+
+// PrintObjectDiff prints a unified diff of an object between the database
+// and a file.
+func PrintObjectDiff(d state.ObjectDiff, file string) {
+	headerStyle := utils.WhiteStyle.Bold(true)
+	path := strings.ReplaceAll(d.ObjectType, " ", "-") + "/" + d.Name
+
+	fmt.Println(headerStyle.Render("--- database/" + path))
+	fmt.Println(headerStyle.Render("+++ " + file + "/" + path))
+
+	for _, line := range utils.DiffLines(splitLines(d.Current), splitLines(d.Desired)) {
+		text := string(line.Op) + line.Text
+		switch line.Op {
+		case utils.LineDelete:
+			fmt.Println(utils.RedStyle.Render(text))
+		case utils.LineInsert:
+			fmt.Println(utils.GreenStyle.Render(text))
+		default:
+			fmt.Println(text)
+		}
+	}
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -8,6 +8,14 @@ var DisableCmd = &cobra.Command{
 }
 
 func init() {
+	// Add common flags
+	DisableCmd.PersistentFlags().Bool("dry-run", false, "Show the affected rows (including cascaded changes) without committing")
+	DisableCmd.PersistentFlags().BoolP("json", "j", false, "Output the result of each item in JSON format")
+	DisableCmd.PersistentFlags().Bool("atomic", false, "Process all items in a single transaction, which is rolled back on the first failure")
+	DisableCmd.PersistentFlags().Int("parallel", 1, "Process up to this many items concurrently, each in its own transaction")
+	DisableCmd.MarkFlagsMutuallyExclusive("atomic", "parallel")
+	DisableCmd.PersistentFlags().String("from-file", "", "Read the items from a CSV, JSONL or newline-separated file ('-' for stdin)")
+
 	// Add subcommands
 	DisableCmd.AddCommand(DisableDomainsCmd)
 	DisableCmd.AddCommand(DisableMailboxesCmd)
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/gerolf-vent/mailctl/internal/utils"
@@ -14,11 +13,16 @@ var DisableAliasesCmd = &cobra.Command{
 	Aliases: []string{"alias"},
 	Short:   "Disables aliases",
 	Long:    "Disables aliases.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		argEmails := ParseEmailArgs(args)
-		if len(argEmails) != len(args) {
-			return fmt.Errorf("invalid email arguments")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+
+		argEmails, err := ReadItemEmails(cmd, args, "email")
+		if err != nil {
+			return err
 		}
 
 		enabled := false
@@ -34,9 +38,12 @@ var DisableAliasesCmd = &cobra.Command{
 			ItemString:     func(item utils.EmailAddress) string { return item.String() },
 			FailureMessage: "failed to disable alias",
 			SuccessMessage: "Successfully disabled alias",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
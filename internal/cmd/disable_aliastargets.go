@@ -14,22 +14,42 @@ var DisableAliasTargetsCmd = &cobra.Command{
 	Aliases: []string{"alias-target", "targets", "target"},
 	Short:   "Disables features on alias targets of an alias",
 	Long:    `Disable forwarding and/or sending on an alias target. Use flags to select which property to disable.`,
-	Args:    cobra.MinimumNArgs(2),
+	Args:    ItemArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagForward, _ := cmd.Flags().GetBool("forward")
 		flagSend, _ := cmd.Flags().GetBool("send")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
 		if !flagForward && !flagSend {
 			return fmt.Errorf("at least one of --forward or --send flags must be specified")
 		}
 
-		argEmails := ParseEmailArgs(args)
-		if len(argEmails) != len(args) {
-			return fmt.Errorf("invalid email arguments")
+		rows, err := ReadItemRows(cmd, args, []string{"alias", "target"})
+		if err != nil {
+			return err
 		}
 
-		argAliasEmail := argEmails[0]
-		argTargetEmails := argEmails[1:]
+		type aliasTargetItem struct {
+			aliasEmail  utils.EmailAddress
+			targetEmail utils.EmailAddress
+		}
+
+		items := make([]aliasTargetItem, 0, len(rows))
+		for _, row := range rows {
+			aliasEmail, err := row.Email(0)
+			if err != nil {
+				return err
+			}
+			targetEmail, err := row.Email(1)
+			if err != nil {
+				return err
+			}
+
+			items = append(items, aliasTargetItem{aliasEmail: aliasEmail, targetEmail: targetEmail})
+		}
 
 		disabled := false
 		options := db.AliasesTargetsPatchOptions{}
@@ -40,18 +60,23 @@ var DisableAliasTargetsCmd = &cobra.Command{
 			options.SendingFromTargetEnabled = &disabled
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddress]{
-			Items: argTargetEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddress) error {
-				return db.AliasesTargets(tx).Patch(argAliasEmail, item, options)
+		runner := db.TxForEachRunner[aliasTargetItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item aliasTargetItem) error {
+				return db.AliasesTargets(tx).Patch(item.aliasEmail, item.targetEmail, options)
+			},
+			ItemString: func(item aliasTargetItem) string {
+				return item.aliasEmail.String() + " -> " + item.targetEmail.String()
 			},
-			ItemString:     func(item utils.EmailAddress) string { return argAliasEmail.String() + " -> " + item.String() },
 			FailureMessage: "failed to disable alias target",
 			SuccessMessage: "Successfully disable alias target",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
@@ -14,19 +14,23 @@ var DisableMailboxesCmd = &cobra.Command{
 	Aliases: []string{"mailbox"},
 	Short:   "Disables features on mailboxes",
 	Long:    "Disables login, receiving, and/or sending for mailboxes. Use flags to select which property to disable.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagLogin, _ := cmd.Flags().GetBool("login")
 		flagReceiving, _ := cmd.Flags().GetBool("receiving")
 		flagSending, _ := cmd.Flags().GetBool("sending")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
 		if !flagLogin && !flagReceiving && !flagSending {
 			return fmt.Errorf("at least one of --login, --receiving, or --sending must be true when specified")
 		}
 
-		argEmails := ParseEmailArgs(args)
-		if len(argEmails) != len(args) {
-			return fmt.Errorf("invalid email arguments")
+		argEmails, err := ReadItemEmails(cmd, args, "email")
+		if err != nil {
+			return err
 		}
 
 		disabled := false
@@ -49,10 +53,13 @@ var DisableMailboxesCmd = &cobra.Command{
 			ItemString:     func(item utils.EmailAddress) string { return item.String() },
 			FailureMessage: "failed to patch mailbox",
 			SuccessMessage: "Successfully patched mailbox",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
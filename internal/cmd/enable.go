@@ -8,6 +8,14 @@ var EnableCmd = &cobra.Command{
 }
 
 func init() {
+	// Add common flags
+	EnableCmd.PersistentFlags().Bool("dry-run", false, "Show the affected rows (including cascaded changes) without committing")
+	EnableCmd.PersistentFlags().BoolP("json", "j", false, "Output the result of each item in JSON format")
+	EnableCmd.PersistentFlags().Bool("atomic", false, "Process all items in a single transaction, which is rolled back on the first failure")
+	EnableCmd.PersistentFlags().Int("parallel", 1, "Process up to this many items concurrently, each in its own transaction")
+	EnableCmd.MarkFlagsMutuallyExclusive("atomic", "parallel")
+	EnableCmd.PersistentFlags().String("from-file", "", "Read the items from a CSV, JSONL or newline-separated file ('-' for stdin)")
+
 	// Add enable subcommands
 	EnableCmd.AddCommand(EnableDomainsCmd)
 	EnableCmd.AddCommand(EnableMailboxesCmd)
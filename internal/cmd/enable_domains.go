@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/spf13/cobra"
@@ -13,11 +12,16 @@ var EnableDomainsCmd = &cobra.Command{
 	Aliases: []string{"domain"},
 	Short:   "Enables domains",
 	Long:    "Enables domains.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		argDomains := ParseDomainFQDNArgs(args)
-		if len(argDomains) != len(args) {
-			return fmt.Errorf("invalid domain arguments")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+
+		argDomains, err := ReadItemDomainFQDNs(cmd, args, "fqdn")
+		if err != nil {
+			return err
 		}
 
 		enabled := true
@@ -33,9 +37,12 @@ var EnableDomainsCmd = &cobra.Command{
 			ItemString:     func(item string) string { return item },
 			FailureMessage: "failed to enable domain",
 			SuccessMessage: "Successfully enabled domain",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
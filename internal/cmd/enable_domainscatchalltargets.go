@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/gerolf-vent/mailctl/internal/utils"
@@ -14,12 +13,31 @@ var EnableDomainCatchallTargetsCmd = &cobra.Command{
 	Aliases: []string{"catchall-target", "catchalls", "catchall"},
 	Short:   "Enables forwarding on catch-all targets of a domain",
 	Long:    "Enables forwarding on catch-all targets of a domain.",
-	Args:    cobra.MinimumNArgs(2),
+	Args:    ItemArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		argDomain := args[0]
-		argEmails := ParseEmailArgs(args[1:])
-		if len(argEmails) != len(args)-1 {
-			return fmt.Errorf("invalid email arguments")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+
+		rows, err := ReadItemRows(cmd, args, []string{"domain", "target"})
+		if err != nil {
+			return err
+		}
+
+		type catchallTargetItem struct {
+			domain      string
+			targetEmail utils.EmailAddress
+		}
+
+		items := make([]catchallTargetItem, 0, len(rows))
+		for _, row := range rows {
+			targetEmail, err := row.Email(1)
+			if err != nil {
+				return err
+			}
+
+			items = append(items, catchallTargetItem{domain: row.Args[0], targetEmail: targetEmail})
 		}
 
 		enabled := true
@@ -27,17 +45,20 @@ var EnableDomainCatchallTargetsCmd = &cobra.Command{
 			ForwardingToTargetEnabled: &enabled,
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddress]{
-			Items: argEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddress) error {
-				return db.DomainsCatchallTargets(tx).Patch(argDomain, item, options)
+		runner := db.TxForEachRunner[catchallTargetItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item catchallTargetItem) error {
+				return db.DomainsCatchallTargets(tx).Patch(item.domain, item.targetEmail, options)
 			},
-			ItemString:     func(item utils.EmailAddress) string { return "@" + argDomain + " -> " + item.String() },
+			ItemString:     func(item catchallTargetItem) string { return "@" + item.domain + " -> " + item.targetEmail.String() },
 			FailureMessage: "failed to enable catchall target",
 			SuccessMessage: "Successfully enabled catchall target",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
@@ -12,24 +12,37 @@ var EnableRemotesCmd = &cobra.Command{
 	Aliases: []string{"remote"},
 	Short:   "Enables remotes",
 	Long:    "Enables remotes.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+
+		argNames, err := ReadItemNames(cmd, args, "name")
+		if err != nil {
+			return err
+		}
+
 		enabled := true
 		options := db.RemotesPatchOptions{
 			Enabled: &enabled,
 		}
 
 		runner := db.TxForEachRunner[string]{
-			Items: args,
+			Items: argNames,
 			Exec: func(tx *sql.Tx, item string) error {
 				return db.Remotes(tx).Patch(item, options)
 			},
 			ItemString:     func(item string) string { return item },
 			FailureMessage: "failed to enable remote",
 			SuccessMessage: "Successfully enabled remote",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
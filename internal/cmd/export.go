@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/state"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var ExportCmd = &cobra.Command{
+	Use:   "export [flags]",
+	Short: "Export the configuration of all objects",
+	Long: "Export all mail system objects to a single YAML or JSON document, which can be loaded with 'mailctl import' or 'mailctl apply'.\n" +
+		"The objects are read from a consistent snapshot of the database. Password hashes are only exported with --include-secrets.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagOutput, _ := cmd.Flags().GetString("output")
+		flagFormat, _ := cmd.Flags().GetString("format")
+		flagAll, _ := cmd.Flags().GetBool("all")
+		flagIncludeSecrets, _ := cmd.Flags().GetBool("include-secrets")
+
+		format := flagFormat
+		if format == "" {
+			format = "yaml"
+			if strings.EqualFold(filepath.Ext(flagOutput), ".json") {
+				format = "json"
+			}
+		}
+		if format != "yaml" && format != "json" {
+			return fmt.Errorf("invalid format %q, must be one of: yaml, json", format)
+		}
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		// Read all objects from the same snapshot
+		tx, err := dbConn.BeginTx(context.Background(), &sql.TxOptions{
+			Isolation: sql.LevelRepeatableRead,
+			ReadOnly:  true,
+		})
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to begin transaction", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		doc, err := state.Current(tx, state.CurrentOptions{
+			IncludeDeleted:        flagAll,
+			IncludePasswordHashes: flagIncludeSecrets,
+		})
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to read objects", err)
+			return utils.ExitError{Code: 1}
+		}
+		doc.Schema = state.SchemaURL
+
+		data, err := state.Marshal(doc, format)
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to encode document", err)
+			return utils.ExitError{Code: 1}
+		}
+
+		if flagOutput == "" || flagOutput == "-" {
+			_, err = os.Stdout.Write(data)
+		} else {
+			// Password hashes must only be readable by the owner, also if the
+			// file already exists
+			var perm os.FileMode = 0644
+			if flagIncludeSecrets {
+				perm = 0600
+			}
+			err = writeFileAtomic(flagOutput, data, perm)
+		}
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to write export", err)
+			return utils.ExitError{Code: 1}
+		}
+
+		if flagOutput != "" && flagOutput != "-" {
+			utils.PrintSuccess(fmt.Sprintf("Successfully exported configuration: %s", flagOutput))
+		}
+		return nil
+	},
+}
+
+func init() {
+	ExportCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+	ExportCmd.Flags().String("format", "", "Output format (yaml or json, default: by file extension or yaml)")
+	ExportCmd.Flags().Bool("all", false, "Include soft-deleted objects")
+	ExportCmd.Flags().Bool("include-secrets", false, "Include password hashes")
+}
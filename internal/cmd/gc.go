@@ -0,0 +1,386 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// gcObject is a soft-deleted object, which can be deleted permanently.
+type gcObject struct {
+	Name      string
+	DeletedAt time.Time
+	Delete    func(tx *sql.Tx) error
+}
+
+// gcListDeleted lists the soft-deleted objects of a type (see db.GCObjectTypes).
+var gcListDeleted = map[string]func(tx *sql.Tx) ([]gcObject, error){
+	"alias-targets": func(tx *sql.Tx) ([]gcObject, error) {
+		targets, err := db.AliasesTargets(tx).List(db.AliasesTargetsListOptions{IncludeDeleted: true})
+		if err != nil {
+			return nil, err
+		}
+		var out []gcObject
+		for _, target := range targets {
+			aliasEmail, err := utils.ParseEmailAddress(target.AliasEmail)
+			if err != nil {
+				return nil, err
+			}
+			targetEmail, err := utils.ParseEmailAddress(target.TargetEmail)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, gcObject{
+				Name:      aliasEmail.String() + " -> " + targetEmail.String(),
+				DeletedAt: *target.DeletedAt,
+				Delete: func(tx *sql.Tx) error {
+					return db.AliasesTargets(tx).Delete(aliasEmail, targetEmail, db.DeleteOptions{Permanent: true})
+				},
+			})
+		}
+		return out, nil
+	},
+	"catchall-targets": func(tx *sql.Tx) ([]gcObject, error) {
+		targets, err := db.DomainsCatchallTargets(tx).List(db.DomainsCatchallTargetsListOptions{IncludeDeleted: true})
+		if err != nil {
+			return nil, err
+		}
+		var out []gcObject
+		for _, target := range targets {
+			targetEmail, err := utils.ParseEmailAddress(target.TargetEmail)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, gcObject{
+				Name:      "@" + target.DomainFQDN + " -> " + targetEmail.String(),
+				DeletedAt: *target.DeletedAt,
+				Delete: func(tx *sql.Tx) error {
+					return db.DomainsCatchallTargets(tx).Delete(target.DomainFQDN, targetEmail, db.DeleteOptions{Permanent: true})
+				},
+			})
+		}
+		return out, nil
+	},
+	"send-grants": func(tx *sql.Tx) ([]gcObject, error) {
+		grants, err := db.RemotesSendGrants(tx).List(db.RemotesSendGrantsListOptions{IncludeDeleted: true})
+		if err != nil {
+			return nil, err
+		}
+		var out []gcObject
+		for _, grant := range grants {
+			email := utils.EmailAddressOrWildcard{LocalPart: &grant.Name, DomainFQDN: grant.DomainFQDN}
+			out = append(out, gcObject{
+				Name:      grant.RemoteName + ": " + email.String(),
+				DeletedAt: *grant.DeletedAt,
+				Delete: func(tx *sql.Tx) error {
+					return db.RemotesSendGrants(tx).Delete(grant.RemoteName, email, db.DeleteOptions{Permanent: true})
+				},
+			})
+		}
+		return out, nil
+	},
+	"recipients-relayed": func(tx *sql.Tx) ([]gcObject, error) {
+		recipients, err := db.RecipientsRelayed(tx).List(db.RecipientsRelayedListOptions{IncludeDeleted: true})
+		if err != nil {
+			return nil, err
+		}
+		var out []gcObject
+		for _, recipient := range recipients {
+			email := utils.EmailAddress{LocalPart: recipient.Name, DomainFQDN: recipient.DomainFQDN}
+			out = append(out, gcObject{
+				Name:      email.String(),
+				DeletedAt: *recipient.DeletedAt,
+				Delete: func(tx *sql.Tx) error {
+					return db.RecipientsRelayed(tx).Delete(email, db.DeleteOptions{Permanent: true})
+				},
+			})
+		}
+		return out, nil
+	},
+	"aliases": func(tx *sql.Tx) ([]gcObject, error) {
+		aliases, err := db.Aliases(tx).List(db.AliasesListOptions{IncludeDeleted: true})
+		if err != nil {
+			return nil, err
+		}
+		var out []gcObject
+		for _, alias := range aliases {
+			if alias.Name == nil {
+				continue
+			}
+			email := utils.EmailAddress{LocalPart: *alias.Name, DomainFQDN: alias.DomainFQDN}
+			out = append(out, gcObject{
+				Name:      email.String(),
+				DeletedAt: *alias.DeletedAt,
+				Delete: func(tx *sql.Tx) error {
+					return db.Aliases(tx).Delete(email, db.DeleteOptions{Permanent: true})
+				},
+			})
+		}
+		return out, nil
+	},
+	"mailboxes": func(tx *sql.Tx) ([]gcObject, error) {
+		mailboxes, err := db.Mailboxes(tx).List(db.MailboxesListOptions{IncludeDeleted: true})
+		if err != nil {
+			return nil, err
+		}
+		var out []gcObject
+		for _, mailbox := range mailboxes {
+			email := utils.EmailAddress{LocalPart: mailbox.Name, DomainFQDN: mailbox.DomainFQDN}
+			out = append(out, gcObject{
+				Name:      email.String(),
+				DeletedAt: *mailbox.DeletedAt,
+				Delete: func(tx *sql.Tx) error {
+					return db.Mailboxes(tx).Delete(email, db.DeleteOptions{Permanent: true})
+				},
+			})
+		}
+		return out, nil
+	},
+	"domains": func(tx *sql.Tx) ([]gcObject, error) {
+		domains, err := db.Domains(tx).List(db.DomainsListOptions{IncludeDeleted: true})
+		if err != nil {
+			return nil, err
+		}
+		var out []gcObject
+		for _, domain := range domains {
+			out = append(out, gcObject{
+				Name:      domain.FQDN,
+				DeletedAt: *domain.DeletedAt,
+				Delete: func(tx *sql.Tx) error {
+					return db.Domains(tx).Delete(domain.FQDN, db.DeleteOptions{Permanent: true})
+				},
+			})
+		}
+		return out, nil
+	},
+	"remotes": func(tx *sql.Tx) ([]gcObject, error) {
+		remotes, err := db.Remotes(tx).List(db.RemotesListOptions{IncludeDeleted: true})
+		if err != nil {
+			return nil, err
+		}
+		var out []gcObject
+		for _, remote := range remotes {
+			out = append(out, gcObject{
+				Name:      remote.Name,
+				DeletedAt: *remote.DeletedAt,
+				Delete: func(tx *sql.Tx) error {
+					return db.Remotes(tx).Delete(remote.Name, db.DeleteOptions{Permanent: true})
+				},
+			})
+		}
+		return out, nil
+	},
+	"transports": func(tx *sql.Tx) ([]gcObject, error) {
+		transports, err := db.Transports(tx).List(db.TransportsListOptions{IncludeDeleted: true})
+		if err != nil {
+			return nil, err
+		}
+		var out []gcObject
+		for _, transport := range transports {
+			out = append(out, gcObject{
+				Name:      transport.Name,
+				DeletedAt: *transport.DeletedAt,
+				Delete: func(tx *sql.Tx) error {
+					return db.Transports(tx).Delete(transport.Name, db.DeleteOptions{Permanent: true})
+				},
+			})
+		}
+		return out, nil
+	},
+}
+
+// gcResult counts the garbage collected objects of a type.
+type gcResult struct {
+	ObjectType string
+	Retention  time.Duration
+	Deleted    int
+	Skipped    int
+}
+
+func (r *gcResult) String() string {
+	s := fmt.Sprintf("%s older than %s (%d deleted", r.ObjectType, utils.FormatDuration(r.Retention), r.Deleted)
+	if r.Skipped > 0 {
+		s += fmt.Sprintf(", %d skipped", r.Skipped)
+	}
+	return s + ")"
+}
+
+// collectGarbage permanently deletes the soft-deleted objects of a type, which
+// have been deleted before the cutoff. Every object is deleted within a
+// savepoint, so objects which can't be deleted (e.g. because they are still
+// referenced) are skipped with a warning.
+func collectGarbage(tx *sql.Tx, result *gcResult, cutoff time.Time) error {
+	objects, err := gcListDeleted[result.ObjectType](tx)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		if !object.DeletedAt.Before(cutoff) {
+			continue
+		}
+
+		if _, err := tx.Exec("SAVEPOINT gc_object"); err != nil {
+			return err
+		}
+
+		if err := object.Delete(tx); err != nil {
+			if _, rbErr := tx.Exec("ROLLBACK TO SAVEPOINT gc_object"); rbErr != nil {
+				return rbErr
+			}
+			utils.PrintWarning(fmt.Sprintf("skipped %s %s: %v", result.ObjectType, object.Name, err))
+			result.Skipped++
+			continue
+		}
+
+		if _, err := tx.Exec("RELEASE SAVEPOINT gc_object"); err != nil {
+			return err
+		}
+		result.Deleted++
+	}
+
+	return nil
+}
+
+var GCCmd = &cobra.Command{
+	Use:   "gc [flags]",
+	Short: "Permanently delete old soft-deleted objects",
+	Long: "Permanently delete objects, which have been soft-deleted for longer than the retention period.\n" +
+		"Without --older-than the retention periods stored in the database are used (see 'mailctl gc retention'); object types without a retention period are kept.\n" +
+		"Object types are processed in dependency order. Permanently deleting an object also deletes the objects depending on it.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagOlderThan, _ := cmd.Flags().GetString("older-than")
+		flagTypes, _ := cmd.Flags().GetStringSlice("type")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		for _, objectType := range flagTypes {
+			if !slices.Contains(db.GCObjectTypes, objectType) {
+				return fmt.Errorf("invalid type %q, must be one of: %s", objectType, strings.Join(db.GCObjectTypes, ", "))
+			}
+		}
+
+		var olderThan time.Duration
+		if flagOlderThan != "" {
+			var err error
+			olderThan, err = utils.ParseDuration(flagOlderThan)
+			if err != nil {
+				return fmt.Errorf("invalid --older-than value: %w", err)
+			}
+		}
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		retentions := make(map[string]time.Duration)
+		if flagOlderThan == "" {
+			stored, err := db.GCRetentions(dbConn).List()
+			if err != nil {
+				utils.PrintErrorWithMessage("failed to list retention periods", err)
+				return utils.ExitError{Code: 1}
+			}
+			for _, retention := range stored {
+				retentions[retention.ObjectType] = retention.Retention
+			}
+		}
+
+		// Select the object types in dependency order
+		var results []*gcResult
+		for _, objectType := range db.GCObjectTypes {
+			if len(flagTypes) > 0 && !slices.Contains(flagTypes, objectType) {
+				continue
+			}
+
+			retention := olderThan
+			if flagOlderThan == "" {
+				var ok bool
+				if retention, ok = retentions[objectType]; !ok {
+					continue
+				}
+			}
+
+			results = append(results, &gcResult{ObjectType: objectType, Retention: retention})
+		}
+
+		if len(results) == 0 {
+			return fmt.Errorf("no retention period configured, use --older-than or 'mailctl gc retention set'")
+		}
+
+		now := time.Now()
+
+		if flagDryRun {
+			tx, err := dbConn.Begin()
+			if err != nil {
+				utils.PrintErrorWithMessage("failed to begin transaction", err)
+				return utils.ExitError{Code: 1}
+			}
+			defer func() {
+				_ = tx.Rollback()
+			}()
+
+			t := table.New().
+				Border(lipgloss.RoundedBorder()).
+				BorderStyle(utils.BlackStyle).
+				Headers("Type", "Retention", "Object", "Deleted").
+				StyleFunc(func(row, col int) lipgloss.Style {
+					if row == table.HeaderRow {
+						return utils.TableHeaderStyle
+					}
+					return utils.TableRowStyle
+				})
+
+			for _, result := range results {
+				objects, err := gcListDeleted[result.ObjectType](tx)
+				if err != nil {
+					utils.PrintErrorWithMessage("failed to list deleted "+result.ObjectType, err)
+					return utils.ExitError{Code: 1}
+				}
+				for _, object := range objects {
+					if object.DeletedAt.Before(now.Add(-result.Retention)) {
+						t.Row(result.ObjectType, utils.FormatDuration(result.Retention), object.Name, utils.MaybeTimeStyle.Render(object.DeletedAt))
+					}
+				}
+			}
+
+			fmt.Println(t.Render())
+			return nil
+		}
+
+		runner := db.TxForEachRunner[*gcResult]{
+			Items: results,
+			Exec: func(tx *sql.Tx, result *gcResult) error {
+				return collectGarbage(tx, result, now.Add(-result.Retention))
+			},
+			ItemString:     func(result *gcResult) string { return result.String() },
+			FailureMessage: "failed to collect garbage",
+			SuccessMessage: "Successfully collected garbage",
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	GCCmd.Flags().String("older-than", "", "Retention period (duration like '90d' or '12w'), overrides the stored retention periods")
+	GCCmd.Flags().StringSliceP("type", "t", nil, "Object types to collect (comma-separated): "+strings.Join(db.GCObjectTypes, ", "))
+	GCCmd.Flags().Bool("dry-run", false, "Only show which objects would be deleted permanently")
+
+	// Add subcommands
+	GCCmd.AddCommand(GCRetentionCmd)
+}
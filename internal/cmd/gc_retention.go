@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var GCRetentionCmd = &cobra.Command{
+	Use:   "retention [flags]",
+	Short: "Show retention periods for garbage collection",
+	Long:  "Show the retention periods per object type, which are used by 'mailctl gc' without --older-than.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return nil
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		retentions, err := db.GCRetentions(dbConn).List()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to list retention periods", err)
+			return nil
+		}
+
+		if flagJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			if err := encoder.Encode(retentions); err != nil {
+				utils.PrintErrorWithMessage("failed to encode JSON", err)
+			}
+			return nil
+		}
+
+		t := table.New().
+			Border(lipgloss.RoundedBorder()).
+			BorderStyle(utils.BlackStyle).
+			Headers("Type", "Retention", "Last Updated").
+			StyleFunc(func(row, col int) lipgloss.Style {
+				if row == table.HeaderRow {
+					return utils.TableHeaderStyle
+				}
+				return utils.TableRowStyle
+			})
+
+		for _, retention := range retentions {
+			t.Row(
+				retention.ObjectType,
+				utils.FormatDuration(retention.Retention),
+				utils.MaybeTimeStyle.Render(retention.UpdatedAt),
+			)
+		}
+
+		fmt.Println(t.Render())
+		return nil
+	},
+}
+
+var GCRetentionSetCmd = &cobra.Command{
+	Use:   "set <type> [<type>...] <duration>",
+	Short: "Set retention periods for garbage collection",
+	Long:  "Set the retention period of object types. Use 'all' to set it for all object types.",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		retention, err := utils.ParseDuration(args[len(args)-1])
+		if err != nil {
+			return fmt.Errorf("invalid retention period: %w", err)
+		}
+		if retention <= 0 {
+			return fmt.Errorf("retention period must be positive")
+		}
+
+		objectTypes, err := parseGCObjectTypeArgs(args[:len(args)-1])
+		if err != nil {
+			return err
+		}
+
+		runner := db.TxForEachRunner[string]{
+			Items: objectTypes,
+			Exec: func(tx *sql.Tx, objectType string) error {
+				return db.GCRetentions(tx).Set(objectType, retention)
+			},
+			ItemString:     func(objectType string) string { return objectType + " (" + utils.FormatDuration(retention) + ")" },
+			FailureMessage: "failed to set retention period",
+			SuccessMessage: "Successfully set retention period",
+		}
+
+		return runner.Run()
+	},
+}
+
+var GCRetentionUnsetCmd = &cobra.Command{
+	Use:   "unset <type> [<type>...]",
+	Short: "Unset retention periods for garbage collection",
+	Long:  "Unset the retention period of object types, so they are kept by 'mailctl gc' without --older-than. Use 'all' to unset it for all object types.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		objectTypes, err := parseGCObjectTypeArgs(args)
+		if err != nil {
+			return err
+		}
+
+		runner := db.TxForEachRunner[string]{
+			Items: objectTypes,
+			Exec: func(tx *sql.Tx, objectType string) error {
+				return db.GCRetentions(tx).Delete(objectType)
+			},
+			ItemString:     func(objectType string) string { return objectType },
+			FailureMessage: "failed to unset retention period",
+			SuccessMessage: "Successfully unset retention period",
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	GCRetentionCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+
+	// Add subcommands
+	GCRetentionCmd.AddCommand(GCRetentionSetCmd)
+	GCRetentionCmd.AddCommand(GCRetentionUnsetCmd)
+}
+
+func parseGCObjectTypeArgs(args []string) ([]string, error) {
+	if slices.Contains(args, "all") {
+		return db.GCObjectTypes, nil
+	}
+
+	for _, arg := range args {
+		if !slices.Contains(db.GCObjectTypes, arg) {
+			return nil, fmt.Errorf("invalid type %q, must be one of: all, %s", arg, strings.Join(db.GCObjectTypes, ", "))
+		}
+	}
+	return args, nil
+}
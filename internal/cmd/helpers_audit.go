@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+var (
+	// Fields which change on every update and are therefore omitted in compact diffs
+	auditLogNoiseFields = []string{"updated_at"}
+)
+
+// ParseAuditLogObjectArgs parses object references for audit log filters.
+// Email addresses reference recipients, everything else a domain, transport
+// or remote.
+func ParseAuditLogObjectArgs(args []string) ([]db.AuditLogObject, error) {
+	objects := make([]db.AuditLogObject, 0, len(args))
+	for _, arg := range args {
+		if strings.Contains(arg, "@") {
+			email, err := utils.ParseEmailAddress(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid email format: %s: %w", arg, err)
+			}
+			objects = append(objects, db.AuditLogObject{Email: &email})
+			continue
+		}
+
+		object := db.AuditLogObject{Name: arg}
+		if domainFQDN, err := utils.ParseDomainFQDN(arg); err == nil {
+			object.DomainFQDN = domainFQDN
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// RenderAuditValue renders a single JSON value of an audit log record.
+func RenderAuditValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return utils.BlackStyle.Render("-")
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// RenderAuditOperation renders the operation of an audit log entry in a
+// color matching its impact.
+func RenderAuditOperation(operation string) string {
+	switch operation {
+	case "INSERT":
+		return utils.GreenStyle.Render(operation)
+	case "UPDATE":
+		return utils.YellowStyle.Render(operation)
+	case "DELETE":
+		return utils.RedStyle.Render(operation)
+	default:
+		return operation
+	}
+}
+
+// RenderAuditChangesCompact renders the changed fields of an update as one
+// line per field. Inserts and deletes are summarized, because every field
+// changes for them.
+func RenderAuditChangesCompact(entry db.AuditLogEntry) string {
+	switch entry.Operation {
+	case "INSERT":
+		return utils.BlackStyle.Render("created")
+	case "DELETE":
+		return utils.BlackStyle.Render("deleted permanently")
+	}
+
+	var lines []string
+	for _, change := range entry.Changes {
+		if slices.Contains(auditLogNoiseFields, change.Field) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s %s %s",
+			change.Field,
+			RenderAuditValue(change.Old),
+			utils.BlackStyle.Render("→"),
+			RenderAuditValue(change.New),
+		))
+	}
+
+	if len(lines) == 0 {
+		return utils.BlackStyle.Render("<no changes>")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AuditEventString classifies an audit log entry as a lifecycle event of its
+// object (e.g. created, renamed, soft-deleted or restored).
+func AuditEventString(entry db.AuditLogEntry) string {
+	switch entry.Operation {
+	case "INSERT":
+		return utils.GreenStyle.Render("Created")
+	case "DELETE":
+		return utils.RedStyle.Render("Deleted permanently")
+	}
+
+	for _, change := range entry.Changes {
+		switch change.Field {
+		case "deleted_at":
+			if change.New != nil {
+				return utils.RedStyle.Render("Deleted")
+			}
+			return utils.GreenStyle.Render("Restored")
+		}
+	}
+
+	for _, change := range entry.Changes {
+		switch change.Field {
+		case "name", "fqdn":
+			return utils.BlueStyle.Render("Renamed")
+		}
+	}
+
+	return utils.YellowStyle.Render("Patched")
+}
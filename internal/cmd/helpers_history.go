@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+const (
+	historyLimit = 100
+)
+
+// RenderHistorySection returns a timeline of the audit log entries matching
+// the given options, limited to the most recent entries.
+func RenderHistorySection(r sq.BaseRunner, options db.AuditLogListOptions) string {
+	options.Limit = historyLimit
+
+	entries, err := db.AuditLog(r).List(options)
+	if err != nil {
+		return utils.RedStyle.Render("Failed to load history: " + err.Error())
+	}
+	if len(entries) == 0 {
+		return utils.BlackStyle.Render("No history recorded.")
+	}
+
+	historyT := table.New().
+		StyleFunc(func(row, col int) lipgloss.Style {
+			cellStyle := utils.TableRowStyle
+			if row == 0 {
+				cellStyle = cellStyle.Bold(true).PaddingBottom(1)
+			}
+			if col == 0 {
+				cellStyle = cellStyle.PaddingLeft(0)
+			}
+			return cellStyle
+		}).
+		Row("Changed", "Changed By", "Event", "Object", "Changes").
+		BorderTop(false).
+		BorderBottom(false).
+		BorderLeft(false).
+		BorderRight(false).
+		BorderColumn(false)
+
+	for _, e := range entries {
+		historyT.Row(
+			utils.MaybeTimeStyle.Render(e.ChangedAt),
+			e.ChangedBy,
+			AuditEventString(e),
+			utils.MaybeEmptyStyle.Render(e.Object),
+			RenderAuditChangesCompact(e),
+		)
+	}
+
+	return historyT.Render()
+}
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"bufio"
+	"crypto/rand"
 	"fmt"
 	"io"
+	"math/big"
 	"os"
 	"strconv"
 	"strings"
@@ -109,3 +111,19 @@ func PasswordHash(password, method string, options string) (string, error) {
 
 	return "", fmt.Errorf("unsupported password hashing method: %s", method)
 }
+
+const generatedPasswordChars = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GeneratePassword returns a random password of the given length. Characters,
+// which are easily confused (e.g. "l" and "1"), aren't used.
+func GeneratePassword(length int) (string, error) {
+	password := make([]byte, length)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(generatedPasswordChars))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate password: %w", err)
+		}
+		password[i] = generatedPasswordChars[n.Int64()]
+	}
+	return string(password), nil
+}
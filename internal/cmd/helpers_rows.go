@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ItemRow is a single item of a command, given by positional arguments or
+// read by --from-file. Args holds the values of the key columns (e.g. the
+// alias and the target email), Fields the other columns of the row, which
+// override the flags of the same name.
+type ItemRow struct {
+	Args   []string
+	Fields map[string]string
+	Line   int // Line in the input file, 0 for positional arguments
+	flags  *pflag.FlagSet
+}
+
+// Changed reports whether the row has the column or the flag was set.
+func (r ItemRow) Changed(name string) bool {
+	if _, ok := r.Fields[name]; ok {
+		return true
+	}
+	return r.flags.Changed(name)
+}
+
+func (r ItemRow) GetString(name string) (string, error) {
+	if value, ok := r.Fields[name]; ok {
+		return value, nil
+	}
+	return r.flags.GetString(name)
+}
+
+func (r ItemRow) GetBool(name string) (bool, error) {
+	if value, ok := r.Fields[name]; ok {
+		return strconv.ParseBool(value)
+	}
+	return r.flags.GetBool(name)
+}
+
+// GetStringSlice returns the values of a column, which are separated by
+// commas, or of a string slice flag.
+func (r ItemRow) GetStringSlice(name string) ([]string, error) {
+	if value, ok := r.Fields[name]; ok {
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values, nil
+	}
+	return r.flags.GetStringSlice(name)
+}
+
+func (r ItemRow) GetInt32(name string) (int32, error) {
+	if value, ok := r.Fields[name]; ok {
+		v, err := strconv.ParseInt(value, 10, 32)
+		return int32(v), err
+	}
+	return r.flags.GetInt32(name)
+}
+
+func (r ItemRow) GetUint16(name string) (uint16, error) {
+	if value, ok := r.Fields[name]; ok {
+		v, err := strconv.ParseUint(value, 10, 16)
+		return uint16(v), err
+	}
+	return r.flags.GetUint16(name)
+}
+
+// Email parses the i-th key column as email address.
+func (r ItemRow) Email(i int) (utils.EmailAddress, error) {
+	email, err := utils.ParseEmailAddress(r.Args[i])
+	if err != nil {
+		return email, r.Errorf("invalid email format: %s: %w", r.Args[i], err)
+	}
+	return email, nil
+}
+
+// EmailOrWildcard parses the i-th key column as email address or wildcard.
+func (r ItemRow) EmailOrWildcard(i int) (utils.EmailAddressOrWildcard, error) {
+	email, err := utils.ParseEmailAddressOrWildcard(r.Args[i])
+	if err != nil {
+		return email, r.Errorf("invalid email format: %s: %w", r.Args[i], err)
+	}
+	return email, nil
+}
+
+// DomainFQDN parses the i-th key column as domain FQDN.
+func (r ItemRow) DomainFQDN(i int) (string, error) {
+	domainFQDN, err := utils.ParseDomainFQDN(r.Args[i])
+	if err != nil {
+		return domainFQDN, r.Errorf("invalid domain format: %s: %w", r.Args[i], err)
+	}
+	return domainFQDN, nil
+}
+
+// Errorf returns an error referencing the line of the row.
+func (r ItemRow) Errorf(format string, a ...any) error {
+	err := fmt.Errorf(format, a...)
+	if r.Line > 0 {
+		return fmt.Errorf("line %d: %w", r.Line, err)
+	}
+	return err
+}
+
+// ItemArgs requires at least n positional arguments. With --from-file less
+// than n are required, because the file provides the remaining key columns.
+func ItemArgs(n int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if flagFromFile, _ := cmd.Flags().GetString("from-file"); flagFromFile != "" {
+			return cobra.MaximumNArgs(n-1)(cmd, args)
+		}
+		return cobra.MinimumNArgs(n)(cmd, args)
+	}
+}
+
+// ReadItemRows returns the items of a command. Without --from-file, the
+// leading positional arguments are shared by all items and each remaining
+// argument is the last key column of an item. With --from-file, each row of
+// the file is an item, which has to provide the key columns not given as
+// positional arguments. Besides the key columns, only the given field columns
+// are accepted.
+func ReadItemRows(cmd *cobra.Command, args []string, keyColumns []string, fieldColumns ...string) ([]ItemRow, error) {
+	flagFromFile, _ := cmd.Flags().GetString("from-file")
+
+	if flagFromFile == "" {
+		shared := args[:len(keyColumns)-1]
+		rows := make([]ItemRow, 0, len(args)-len(shared))
+		for _, arg := range args[len(shared):] {
+			rows = append(rows, ItemRow{
+				Args:  append(slices.Clone(shared), arg),
+				flags: cmd.Flags(),
+			})
+		}
+		return rows, nil
+	}
+
+	var data []byte
+	var err error
+	if flagFromFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(flagFromFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := utils.ParseRecords(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", flagFromFile, err)
+	}
+
+	// Newline-separated rows provide the last key column
+	fileColumns := keyColumns[len(args):]
+	if utils.DetectRecordFormat(data) == utils.RecordFormatLines && len(fileColumns) > 1 {
+		return nil, fmt.Errorf("newline-separated input requires the columns %v as arguments", keyColumns[:len(keyColumns)-1])
+	}
+
+	rows := make([]ItemRow, 0, len(records))
+	for _, record := range records {
+		row := ItemRow{
+			Args:   slices.Clone(args),
+			Fields: make(map[string]string),
+			Line:   record.Line,
+			flags:  cmd.Flags(),
+		}
+
+		for name, value := range record.Fields {
+			switch {
+			case name == "":
+				row.Args = append(row.Args, value)
+			case slices.Contains(fileColumns, name):
+				row.Fields[name] = value
+			case slices.Contains(fieldColumns, name):
+				if err := validateFlagValue(cmd.Flags().Lookup(name), value); err != nil {
+					return nil, row.Errorf("invalid value of column %q: %w", name, err)
+				}
+				row.Fields[name] = value
+			case slices.Contains(keyColumns, name):
+				return nil, row.Errorf("column %q is already given as argument", name)
+			default:
+				return nil, row.Errorf("unknown column %q", name)
+			}
+		}
+
+		for _, name := range fileColumns {
+			if value, ok := row.Fields[name]; ok {
+				row.Args = append(row.Args, value)
+				delete(row.Fields, name)
+			} else if len(row.Args) < len(keyColumns) {
+				return nil, row.Errorf("missing column %q", name)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no items in %s", flagFromFile)
+	}
+
+	return rows, nil
+}
+
+// validateFlagValue checks, whether a column value is valid for the flag it
+// overrides, so the values can be read like flags later on.
+func validateFlagValue(flag *pflag.Flag, value string) error {
+	var err error
+	switch flag.Value.Type() {
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "int32":
+		_, err = strconv.ParseInt(value, 10, 32)
+	case "uint16":
+		_, err = strconv.ParseUint(value, 10, 16)
+	}
+	return err
+}
+
+// ReadItemEmails returns the email addresses of a command with a single key
+// column.
+func ReadItemEmails(cmd *cobra.Command, args []string, column string) ([]utils.EmailAddress, error) {
+	rows, err := ReadItemRows(cmd, args, []string{column})
+	if err != nil {
+		return nil, err
+	}
+
+	emails := make([]utils.EmailAddress, 0, len(rows))
+	for _, row := range rows {
+		email, err := row.Email(0)
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+	return emails, nil
+}
+
+// ReadItemDomainFQDNs returns the domain FQDNs of a command with a single key
+// column.
+func ReadItemDomainFQDNs(cmd *cobra.Command, args []string, column string) ([]string, error) {
+	rows, err := ReadItemRows(cmd, args, []string{column})
+	if err != nil {
+		return nil, err
+	}
+
+	domains := make([]string, 0, len(rows))
+	for _, row := range rows {
+		domainFQDN, err := row.DomainFQDN(0)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, domainFQDN)
+	}
+	return domains, nil
+}
+
+// ReadItemNames returns the names of a command with a single key column.
+func ReadItemNames(cmd *cobra.Command, args []string, column string) ([]string, error) {
+	rows, err := ReadItemRows(cmd, args, []string{column})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.Args[0])
+	}
+	return names, nil
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/state"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+// LoadStateFile loads a state document from a YAML or JSON file ("-" reads
+// from stdin).
+func LoadStateFile(path string) (*state.Document, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return state.Load(data)
+}
+
+// PrintStatePlan prints the changes of a plan with a summary line.
+func PrintStatePlan(plan *state.Plan) {
+	if len(plan.Changes) == 0 {
+		fmt.Println(utils.BlackStyle.Render("No changes"))
+		return
+	}
+
+	for _, c := range plan.Changes {
+		object := c.ObjectType + " " + c.Name
+		switch c.Action {
+		case state.ActionCreate:
+			fmt.Println(utils.GreenStyle.Render("+ " + object))
+		case state.ActionUpdate:
+			fmt.Println(utils.YellowStyle.Render("~ " + object))
+		case state.ActionRestore:
+			fmt.Println(utils.BlueStyle.Render("↺ " + object))
+		case state.ActionDelete:
+			fmt.Println(utils.RedStyle.Render("- " + object))
+		}
+		for _, f := range c.Fields {
+			fmt.Printf("    %s: %s → %s\n", f.Field, RenderAuditValue(f.Old), RenderAuditValue(f.New))
+		}
+	}
+
+	fmt.Printf("\n%d to create, %d to update, %d to restore, %d to delete\n",
+		plan.Count(state.ActionCreate),
+		plan.Count(state.ActionUpdate),
+		plan.Count(state.ActionRestore),
+		plan.Count(state.ActionDelete),
+	)
+}
+
+// StateRunner computes a plan against the current state, prints it and
+// applies it in a single transaction. With DryRun the plan is only printed.
+type StateRunner struct {
+	Desired        *state.Document
+	PlanOptions    state.PlanOptions
+	DryRun         bool
+	ItemString     string
+	FailureMessage string
+	SuccessMessage string
+}
+
+func (r StateRunner) computePlan(tx *sql.Tx) (*state.Plan, error) {
+	current, err := state.Current(tx, state.CurrentOptions{
+		IncludeDeleted:        true,
+		IncludePasswordHashes: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state.NewPlan(current, r.Desired, r.PlanOptions)
+}
+
+// Run computes and applies the plan. If it fails, the failure is reported and
+// an ExitError is returned.
+func (r StateRunner) Run() error {
+	if r.DryRun {
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		tx, err := dbConn.Begin()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to begin transaction", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		plan, err := r.computePlan(tx)
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to compute plan", err)
+			return utils.ExitError{Code: 1}
+		}
+		PrintStatePlan(plan)
+		return nil
+	}
+
+	runner := db.TxRunner{
+		Exec: func(tx *sql.Tx) error {
+			plan, err := r.computePlan(tx)
+			if err != nil {
+				return err
+			}
+			PrintStatePlan(plan)
+			return plan.Apply(tx)
+		},
+		ItemString:     r.ItemString,
+		FailureMessage: r.FailureMessage,
+		SuccessMessage: r.SuccessMessage,
+	}
+
+	return runner.Run()
+}
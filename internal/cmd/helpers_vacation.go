@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+// parseVacationDate parses a date ("YYYY-MM-DD"). An empty value or "-"
+// means no date.
+func parseVacationDate(value string) (sql.NullTime, error) {
+	if value == "" || value == "-" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return sql.NullTime{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	return sql.NullTime{Valid: true, Time: t}, nil
+}
+
+// parseVacationReplyInterval parses the reply interval, which has to be at
+// least one second.
+func parseVacationReplyInterval(value string) (time.Duration, error) {
+	d, err := utils.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < time.Second {
+		return 0, fmt.Errorf("reply interval must be at least 1s")
+	}
+	return d.Truncate(time.Second), nil
+}
+
+// parseVacationExcludedSenders normalizes the excluded senders, which are
+// mail addresses or "@domain".
+func parseVacationExcludedSenders(values []string) ([]string, error) {
+	senders := make([]string, 0, len(values))
+	for _, value := range values {
+		sender, err := utils.ParseEmailAddressOrWildcard(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid excluded sender %q: %w", value, err)
+		}
+		senders = append(senders, sender.String())
+	}
+	return senders, nil
+}
+
+// readVacationBody returns the body of a row given by --body or read from
+// --body-file ('-' for stdin).
+func readVacationBody(row ItemRow) (body string, ok bool, err error) {
+	if row.Changed("body") && row.Changed("body-file") {
+		return "", false, row.Errorf("cannot use both --body and --body-file")
+	}
+
+	if row.Changed("body-file") {
+		path, _ := row.GetString("body-file")
+		var data []byte
+		if path == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return "", false, row.Errorf("failed to read body: %w", err)
+		}
+		return string(data), true, nil
+	}
+
+	if row.Changed("body") {
+		body, _ := row.GetString("body")
+		return body, true, nil
+	}
+
+	return "", false, nil
+}
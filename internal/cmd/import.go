@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/state"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var ImportCmd = &cobra.Command{
+	Use:   "import -f <file> [flags]",
+	Short: "Import a configuration export",
+	Long: "Import a document created by 'mailctl export' into an empty or existing database in a single transaction.\n" +
+		"Soft-deleted objects are imported as soft-deleted with their original deletion time.\n" +
+		"Objects, which already exist in a different state, are handled by the conflict policy (skip, overwrite or fail).",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagFile, _ := cmd.Flags().GetString("file")
+		flagOnConflict, _ := cmd.Flags().GetString("on-conflict")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		onConflict := state.ConflictPolicy(flagOnConflict)
+		if !slices.Contains(state.ConflictPolicies, onConflict) {
+			return fmt.Errorf("invalid --on-conflict value %q, must be one of: skip, overwrite, fail", flagOnConflict)
+		}
+
+		desired, err := LoadStateFile(flagFile)
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to load "+flagFile, err)
+			return utils.ExitError{Code: 1}
+		}
+
+		// Record a default reason for the changes
+		session := db.CurrentSession()
+		if session.Reason == "" {
+			session.Reason = "import of " + flagFile
+			db.SetSession(session)
+		}
+
+		runner := StateRunner{
+			Desired:        desired,
+			PlanOptions:    state.PlanOptions{OnConflict: onConflict},
+			DryRun:         flagDryRun,
+			ItemString:     flagFile,
+			FailureMessage: "failed to import configuration",
+			SuccessMessage: "Successfully imported configuration",
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	ImportCmd.Flags().StringP("file", "f", "", "Export file (YAML or JSON, '-' for stdin)")
+	ImportCmd.Flags().String("on-conflict", string(state.ConflictFail), "How to handle objects existing in a different state (skip, overwrite or fail)")
+	ImportCmd.Flags().Bool("dry-run", false, "Only show the plan without importing")
+	_ = ImportCmd.MarkFlagRequired("file")
+
+	// Add subcommands
+	ImportCmd.AddCommand(ImportMailboxesCmd)
+	ImportCmd.AddCommand(ImportPostfixMapsCmd)
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/gerolf-vent/mailctl/internal/utils/crypto/passwordhash"
+	"github.com/spf13/cobra"
+)
+
+var importMailboxesColumns = []string{"email", "password", "quota", "transport", "login", "receiving", "sending"}
+
+var ImportMailboxesCmd = &cobra.Command{
+	Use:     "mailboxes [flags] <file>",
+	Aliases: []string{"mailbox"},
+	Short:   "Import mailboxes from a CSV file",
+	Long: "Creates a mailbox for each row of a CSV file with the columns email, password, quota, transport, login, receiving and sending.\n" +
+		"Plaintext passwords are hashed, existing hashes (e.g. $2y$..., $argon2id$... or {SHA512-CRYPT}...) are stored as is, crypt hashes like $6$... get their Dovecot scheme prefix.\n" +
+		"Mailboxes with a blank password get a generated one, which is only written to the file given by --credentials-file.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagPasswordMethod, _ := cmd.Flags().GetString("password-method")
+		flagPasswordHashOptions, _ := cmd.Flags().GetString("password-hash-options")
+		flagPasswordLength, _ := cmd.Flags().GetInt("password-length")
+		flagCredentialsFile, _ := cmd.Flags().GetString("credentials-file")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+
+		if flagPasswordLength < 8 {
+			return fmt.Errorf("--password-length must be at least 8")
+		}
+
+		argFile := args[0]
+
+		var data []byte
+		var err error
+		if argFile == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(argFile)
+		}
+		if err != nil {
+			return err
+		}
+
+		if utils.DetectRecordFormat(data) != utils.RecordFormatCSV {
+			return fmt.Errorf("%s is not a CSV file with a header", argFile)
+		}
+		records, err := utils.ParseRecords(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", argFile, err)
+		}
+		if len(records) == 0 {
+			return fmt.Errorf("no mailboxes in %s", argFile)
+		}
+
+		type mailboxItem struct {
+			email    utils.EmailAddress
+			password string // Plaintext password to hash or an existing hash
+			options  db.MailboxesCreateOptions
+		}
+
+		type credential struct {
+			email    utils.EmailAddress
+			password string
+		}
+
+		items := make([]mailboxItem, 0, len(records))
+		var credentials []credential
+		for _, record := range records {
+			row := ItemRow{Fields: record.Fields, Line: record.Line}
+
+			for name := range record.Fields {
+				if !slices.Contains(importMailboxesColumns, name) {
+					return row.Errorf("unknown column %q", name)
+				}
+			}
+
+			row.Args = []string{record.Fields["email"]}
+			if row.Args[0] == "" {
+				return row.Errorf("missing column %q", "email")
+			}
+			email, err := row.Email(0)
+			if err != nil {
+				return err
+			}
+
+			options := db.MailboxesCreateOptions{
+				LoginEnabled:     true,
+				ReceivingEnabled: true,
+				SendingEnabled:   true,
+			}
+
+			if value, ok := record.Fields["quota"]; ok {
+				quota, err := strconv.ParseInt(value, 10, 32)
+				if err != nil {
+					return row.Errorf("invalid value of column %q: %w", "quota", err)
+				}
+				if quota > 0 {
+					options.Quota.Valid = true
+					options.Quota.Int32 = int32(quota)
+				}
+			}
+
+			if value, ok := record.Fields["transport"]; ok {
+				options.TransportName.Valid = true
+				options.TransportName.String = value
+			}
+
+			for name, enabled := range map[string]*bool{
+				"login":     &options.LoginEnabled,
+				"receiving": &options.ReceivingEnabled,
+				"sending":   &options.SendingEnabled,
+			} {
+				if value, ok := record.Fields[name]; ok {
+					if *enabled, err = strconv.ParseBool(value); err != nil {
+						return row.Errorf("invalid value of column %q: %w", name, err)
+					}
+				}
+			}
+
+			password := record.Fields["password"]
+			if password == "" {
+				password, err = GeneratePassword(flagPasswordLength)
+				if err != nil {
+					return err
+				}
+				credentials = append(credentials, credential{email: email, password: password})
+			}
+
+			items = append(items, mailboxItem{email: email, password: password, options: options})
+		}
+
+		// Generated passwords are never printed, so they have to be written
+		// before any mailbox is created, to not lose them on a failure
+		if len(credentials) > 0 && !flagDryRun {
+			if flagCredentialsFile == "" {
+				return fmt.Errorf("%d mailboxes have a blank password, use --credentials-file to write the generated passwords to", len(credentials))
+			}
+
+			file, err := os.OpenFile(flagCredentialsFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+			if err != nil {
+				utils.PrintErrorWithMessage("failed to create credentials file", err)
+				return utils.ExitError{Code: 1}
+			}
+
+			w := csv.NewWriter(file)
+			_ = w.Write([]string{"email", "password"})
+			for _, c := range credentials {
+				_ = w.Write([]string{c.email.String(), c.password})
+			}
+			w.Flush()
+			err = w.Error()
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				utils.PrintErrorWithMessage("failed to write credentials file", err)
+				return utils.ExitError{Code: 1}
+			}
+		}
+
+		// Record a default reason for the changes
+		session := db.CurrentSession()
+		if session.Reason == "" {
+			session.Reason = "import of " + argFile
+			db.SetSession(session)
+		}
+
+		runner := db.TxForEachRunner[mailboxItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item mailboxItem) error {
+				// Hash in the runner, so it runs concurrently with --parallel
+				options := item.options
+				options.PasswordHash.Valid = true
+				if passwordhash.IsHash(item.password) {
+					options.PasswordHash.String = passwordhash.Normalize(item.password)
+				} else {
+					passwordHash, err := PasswordHash(item.password, flagPasswordMethod, flagPasswordHashOptions)
+					if err != nil {
+						return err
+					}
+					options.PasswordHash.String = passwordHash
+				}
+
+				return db.Mailboxes(tx).Create(item.email, options)
+			},
+			ItemString:     func(item mailboxItem) string { return item.email.String() },
+			FailureMessage: "failed to import mailbox",
+			SuccessMessage: "Successfully imported mailbox",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	ImportMailboxesCmd.Flags().String("password-method", "argon2id", "Password hashing method for plaintext passwords (default: \"argon2id\", options: \"bcrypt\" or \"argon2id\")")
+	ImportMailboxesCmd.Flags().String("password-hash-options", "", "Password hash options (bcrypt: <cost>; argon2id: m=<number>,t=<number>,p=<number>)")
+	ImportMailboxesCmd.Flags().Int("password-length", 20, "Length of generated passwords")
+	ImportMailboxesCmd.Flags().String("credentials-file", "", "File to write the generated passwords to (must not exist)")
+	ImportMailboxesCmd.Flags().Bool("dry-run", false, "Show the affected rows without committing")
+	ImportMailboxesCmd.Flags().BoolP("json", "j", false, "Output the result of each mailbox in JSON format")
+	ImportMailboxesCmd.Flags().Bool("atomic", false, "Import all mailboxes in a single transaction, which is rolled back on the first failure")
+	ImportMailboxesCmd.Flags().Int("parallel", 1, "Import up to this many mailboxes concurrently, each in its own transaction")
+	ImportMailboxesCmd.MarkFlagsMutuallyExclusive("atomic", "parallel")
+}
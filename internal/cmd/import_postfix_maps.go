@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/migrate"
+	"github.com/gerolf-vent/mailctl/internal/state"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var ImportPostfixMapsCmd = &cobra.Command{
+	Use:   "postfix-maps [flags]",
+	Short: "Import Postfix lookup tables and aliases files",
+	Long: "Reads the plain-text sources of Postfix hash: tables and imports them in a single transaction.\n" +
+		"Entries of virtual_alias_maps become aliases or catchall targets (\"@domain\" keys), keys of virtual_mailbox_maps mailboxes,\n" +
+		"keys of relay_recipient_maps relayed recipients and entries of transport_maps transports of their domain.\n" +
+		"Managed and relayed domains without an entry in transport_maps get the transport given by --default-transport.\n" +
+		"Entries of an aliases file are imported as aliases of the domain given by --aliases-domain.\n" +
+		"Everything, which can't be mapped (e.g. commands, files or forwardings of mailboxes), is reported.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagAliasesDomain, _ := cmd.Flags().GetString("aliases-domain")
+		flagDefaultTransport, _ := cmd.Flags().GetString("default-transport")
+		flagOnConflict, _ := cmd.Flags().GetString("on-conflict")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		onConflict := state.ConflictPolicy(flagOnConflict)
+		if !slices.Contains(state.ConflictPolicies, onConflict) {
+			return fmt.Errorf("invalid --on-conflict value %q, must be one of: skip, overwrite, fail", flagOnConflict)
+		}
+
+		maps := &migrate.PostfixMaps{DefaultTransport: flagDefaultTransport}
+		sources := []struct {
+			flag    string
+			aliases bool
+			into    *[]migrate.PostfixMapEntry
+		}{
+			{"virtual", false, &maps.Virtual},
+			{"virtual-mailbox-maps", false, &maps.VirtualMailbox},
+			{"relay-recipient-maps", false, &maps.RelayRecipient},
+			{"transport", false, &maps.Transport},
+			{"aliases", true, &maps.Aliases},
+		}
+
+		read := 0
+		stdinUsed := false
+		for _, source := range sources {
+			file, _ := cmd.Flags().GetString(source.flag)
+			if file == "" {
+				continue
+			}
+
+			var data []byte
+			var err error
+			if file == "-" {
+				if stdinUsed {
+					return fmt.Errorf("only one file can be read from stdin")
+				}
+				stdinUsed = true
+				data, err = io.ReadAll(os.Stdin)
+			} else {
+				data, err = os.ReadFile(file)
+			}
+			if err != nil {
+				utils.PrintErrorWithMessage("failed to read "+file, err)
+				return utils.ExitError{Code: 1}
+			}
+
+			if *source.into, err = migrate.ParsePostfixMap(file, data, source.aliases); err != nil {
+				utils.PrintErrorWithMessage("failed to parse "+file, err)
+				return utils.ExitError{Code: 1}
+			}
+			read++
+		}
+
+		if read == 0 {
+			return fmt.Errorf("at least one of --virtual, --virtual-mailbox-maps, --relay-recipient-maps, --transport or --aliases is required")
+		}
+
+		if cmd.Flags().Changed("aliases") {
+			if flagAliasesDomain == "" {
+				return fmt.Errorf("--aliases-domain is required with --aliases")
+			}
+			domainFQDN, err := utils.ParseDomainFQDN(flagAliasesDomain)
+			if err != nil {
+				return fmt.Errorf("invalid --aliases-domain: %w", err)
+			}
+			maps.AliasesDomain = domainFQDN
+		}
+
+		desired, issues := maps.Document()
+		for _, issue := range issues {
+			utils.PrintWarning(issue.String())
+		}
+
+		if err := desired.Validate(); err != nil {
+			utils.PrintErrorWithMessage("failed to map Postfix maps", err)
+			return utils.ExitError{Code: 1}
+		}
+		if err := desired.Normalize(); err != nil {
+			utils.PrintErrorWithMessage("failed to map Postfix maps", err)
+			return utils.ExitError{Code: 1}
+		}
+
+		// Record a default reason for the changes
+		session := db.CurrentSession()
+		if session.Reason == "" {
+			session.Reason = "import of Postfix maps"
+			db.SetSession(session)
+		}
+
+		runner := StateRunner{
+			Desired:        desired,
+			PlanOptions:    state.PlanOptions{OnConflict: onConflict},
+			DryRun:         flagDryRun,
+			ItemString:     "Postfix maps",
+			FailureMessage: "failed to import",
+			SuccessMessage: "Successfully imported",
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	ImportPostfixMapsCmd.Flags().String("virtual", "", "Source of virtual_alias_maps ('-' for stdin)")
+	ImportPostfixMapsCmd.Flags().String("virtual-mailbox-maps", "", "Source of virtual_mailbox_maps ('-' for stdin)")
+	ImportPostfixMapsCmd.Flags().String("relay-recipient-maps", "", "Source of relay_recipient_maps ('-' for stdin)")
+	ImportPostfixMapsCmd.Flags().String("transport", "", "Source of transport_maps ('-' for stdin)")
+	ImportPostfixMapsCmd.Flags().String("default-transport", "", "Name of the transport of domains without an entry in transport_maps (must exist)")
+	ImportPostfixMapsCmd.Flags().String("aliases", "", "Aliases file, e.g. /etc/aliases ('-' for stdin)")
+	ImportPostfixMapsCmd.Flags().String("aliases-domain", "", "Domain of the aliases in the aliases file")
+	ImportPostfixMapsCmd.Flags().String("on-conflict", string(state.ConflictFail), "How to handle objects existing in a different state (skip, overwrite or fail)")
+	ImportPostfixMapsCmd.Flags().Bool("dry-run", false, "Only show the report and the plan without importing")
+}
@@ -24,4 +24,5 @@ func init() {
 	ListCmd.AddCommand(ListTransportsCmd)
 	ListCmd.AddCommand(ListRemotesCmd)
 	ListCmd.AddCommand(ListRemoteSendGrantsCmd)
+	ListCmd.AddCommand(ListVacationCmd)
 }
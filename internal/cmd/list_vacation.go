@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var ListVacationCmd = &cobra.Command{
+	Use:     "vacation [flags] [<domain>...]",
+	Aliases: []string{"vacations"},
+	Short:   "List vacation autoresponders of mailboxes",
+	Long:    "List vacation autoresponders of mailboxes.\nIf domains are provided, only vacation autoresponders of mailboxes in these domains are listed.",
+	Args:    cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDeleted, _ := cmd.Flags().GetBool("deleted")
+		flagAll, _ := cmd.Flags().GetBool("all")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagVerbose, _ := cmd.Flags().GetBool("verbose")
+
+		if flagDeleted || flagAll {
+			return fmt.Errorf("vacation autoresponders are deleted permanently, --deleted and --all are not supported")
+		}
+
+		filterDomains := ParseDomainFQDNArgs(args)
+		if len(filterDomains) != len(args) {
+			return fmt.Errorf("invalid domain arguments")
+		}
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		vacations, err := db.MailboxesVacation(dbConn).List(db.MailboxesVacationListOptions{
+			FilterDomains: filterDomains,
+		})
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to list vacation autoresponders", err)
+			return utils.ExitError{Code: 1}
+		}
+
+		if flagJSON {
+			out, err := json.Marshal(vacations)
+			if err != nil {
+				utils.PrintErrorWithMessage("Failed to marshal vacation autoresponders to JSON", err)
+				return utils.ExitError{Code: 1}
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(vacations) == 0 {
+			fmt.Println("No vacation autoresponders found")
+			return nil
+		}
+
+		headers := []string{"Domain", "Name", "Enabled", "Start", "End", "Reply Interval", "Subject", "Excluded Senders"}
+		if flagVerbose {
+			headers = append(headers, "Created", "Last Updated")
+		}
+
+		t := table.New().
+			Border(lipgloss.RoundedBorder()).
+			BorderStyle(utils.BlackStyle).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				cellStyle := utils.TableRowStyle
+				if row == table.HeaderRow {
+					cellStyle = utils.TableHeaderStyle
+				}
+				switch col {
+				case 2: // Enabled
+					return cellStyle.Align(lipgloss.Center)
+				case 5: // Reply Interval
+					return cellStyle.Align(lipgloss.Right)
+				default:
+					return cellStyle.Align(lipgloss.Left)
+				}
+			}).
+			Headers(headers...)
+
+		for _, v := range vacations {
+			excludedSenders := utils.BlackStyle.Render("-")
+			if len(v.ExcludedSenders) > 0 {
+				excludedSenders = strings.Join(v.ExcludedSenders, "\n")
+			}
+
+			row := []string{
+				v.DomainFQDN,
+				v.Name,
+				utils.MaybeEnabledTableStyle.Render(v.Enabled),
+				formatVacationDate(v.StartDate),
+				formatVacationDate(v.EndDate),
+				utils.FormatDuration(v.ReplyInterval),
+				v.Subject,
+				excludedSenders,
+			}
+
+			if flagVerbose {
+				row = append(row,
+					utils.MaybeTimeStyle.Render(v.CreatedAt),
+					utils.MaybeTimeStyle.Render(v.UpdatedAt),
+				)
+			}
+
+			t.Row(row...)
+		}
+
+		fmt.Println(t.Render())
+		return nil
+	},
+}
+
+func formatVacationDate(date *time.Time) string {
+	if date == nil {
+		return utils.BlackStyle.Render("-")
+	}
+	return date.Format("2006-01-02")
+}
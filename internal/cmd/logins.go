@@ -0,0 +1,16 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var LoginsCmd = &cobra.Command{
+	Use:   "logins",
+	Short: "Inspect login attempts and manage login lockouts",
+	Long:  "Inspect the login attempts of mailboxes and remotes recorded by 'mailctl checkpassword', unlock locked logins and manage the login lockouts.",
+}
+
+func init() {
+	// Add subcommands
+	LoginsCmd.AddCommand(LoginsListCmd)
+	LoginsCmd.AddCommand(LoginsUnlockCmd)
+	LoginsCmd.AddCommand(LoginsLockoutCmd)
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// loginAttempt is a login attempt of a mailbox or remote.
+type loginAttempt struct {
+	ObjectType    string     `json:"objectType"`
+	Login         string     `json:"login"` // Email of the mailbox or name of the remote
+	Succeeded     bool       `json:"succeeded"`
+	FailureReason string     `json:"failureReason"`
+	RemoteIP      *string    `json:"remoteIP,omitempty"`
+	Protocol      string     `json:"protocol"`
+	Service       string     `json:"service"`
+	UnlockedAt    *time.Time `json:"unlockedAt,omitempty"`
+	AttemptedAt   time.Time  `json:"attemptedAt"`
+}
+
+var LoginsListCmd = &cobra.Command{
+	Use:   "list [flags] [<email|remote>...]",
+	Short: "List login attempts",
+	Long: "List the login attempts of mailboxes and remotes in chronological order.\n" +
+		"If logins (email address of a mailbox or name of a remote) are provided, only their attempts are listed.",
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagTypes, _ := cmd.Flags().GetStringSlice("type")
+		flagFailed, _ := cmd.Flags().GetBool("failed")
+		flagSince, _ := cmd.Flags().GetString("since")
+		flagLimit, _ := cmd.Flags().GetUint64("limit")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		for _, objectType := range flagTypes {
+			if !slices.Contains(db.LoginLockoutObjectTypes, objectType) {
+				return fmt.Errorf("invalid type %q, must be one of: %s", objectType, strings.Join(db.LoginLockoutObjectTypes, ", "))
+			}
+		}
+		if len(flagTypes) == 0 {
+			flagTypes = db.LoginLockoutObjectTypes
+		}
+
+		mailboxOptions := db.MailboxesLoginAttemptsListOptions{OnlyFailed: flagFailed, Limit: flagLimit}
+		remoteOptions := db.RemotesLoginAttemptsListOptions{OnlyFailed: flagFailed, Limit: flagLimit}
+		emails, remotes, err := parseLoginArgs(args)
+		if err != nil {
+			return err
+		}
+		mailboxOptions.FilterEmails = emails
+		remoteOptions.FilterNames = remotes
+
+		if flagSince != "" {
+			since, err := utils.ParseTimeOrDuration(flagSince, time.Now())
+			if err != nil {
+				return fmt.Errorf("invalid --since value: %w", err)
+			}
+			mailboxOptions.Since = &since
+			remoteOptions.Since = &since
+		}
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		// Only list the attempts of the given kinds of logins
+		var attempts []loginAttempt
+		if slices.Contains(flagTypes, "mailbox") && (len(args) == 0 || len(emails) > 0) {
+			mailboxAttempts, err := db.MailboxesLoginAttempts(dbConn).List(mailboxOptions)
+			if err != nil {
+				utils.PrintErrorWithMessage("failed to list login attempts of mailboxes", err)
+				return utils.ExitError{Code: 1}
+			}
+			for _, a := range mailboxAttempts {
+				attempts = append(attempts, loginAttempt{
+					ObjectType:    "mailbox",
+					Login:         a.Name + "@" + a.DomainFQDN,
+					Succeeded:     a.Succeeded,
+					FailureReason: a.FailureReason,
+					RemoteIP:      a.RemoteIP,
+					Protocol:      a.Protocol,
+					Service:       a.Service,
+					UnlockedAt:    a.UnlockedAt,
+					AttemptedAt:   a.AttemptedAt,
+				})
+			}
+		}
+		if slices.Contains(flagTypes, "remote") && (len(args) == 0 || len(remotes) > 0) {
+			remoteAttempts, err := db.RemotesLoginAttempts(dbConn).List(remoteOptions)
+			if err != nil {
+				utils.PrintErrorWithMessage("failed to list login attempts of remotes", err)
+				return utils.ExitError{Code: 1}
+			}
+			for _, a := range remoteAttempts {
+				attempts = append(attempts, loginAttempt{
+					ObjectType:    "remote",
+					Login:         a.Name,
+					Succeeded:     a.Succeeded,
+					FailureReason: a.FailureReason,
+					RemoteIP:      a.RemoteIP,
+					Protocol:      a.Protocol,
+					Service:       a.Service,
+					UnlockedAt:    a.UnlockedAt,
+					AttemptedAt:   a.AttemptedAt,
+				})
+			}
+		}
+
+		// Keep the most recent attempts of both kinds in chronological order
+		slices.SortStableFunc(attempts, func(a, b loginAttempt) int {
+			return b.AttemptedAt.Compare(a.AttemptedAt)
+		})
+		if flagLimit > 0 && uint64(len(attempts)) > flagLimit {
+			attempts = attempts[:flagLimit]
+		}
+		slices.Reverse(attempts)
+
+		if flagJSON {
+			out, err := json.Marshal(attempts)
+			if err != nil {
+				utils.PrintErrorWithMessage("failed to marshal login attempts to JSON", err)
+				return utils.ExitError{Code: 1}
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(attempts) == 0 {
+			fmt.Println("No login attempts found")
+			return nil
+		}
+
+		t := table.New().
+			Border(lipgloss.RoundedBorder()).
+			BorderStyle(utils.BlackStyle).
+			Headers("Attempted", "Type", "Login", "Result", "Reason", "Remote IP", "Protocol", "Service").
+			StyleFunc(func(row, col int) lipgloss.Style {
+				if row == table.HeaderRow {
+					return utils.TableHeaderStyle
+				}
+				return utils.TableRowStyle
+			})
+
+		for _, a := range attempts {
+			result := utils.GreenStyle.Render("succeeded")
+			if !a.Succeeded {
+				result = utils.RedStyle.Render("failed")
+				if a.UnlockedAt != nil {
+					result += utils.BlackStyle.Render(" (unlocked)")
+				}
+			}
+			t.Row(
+				utils.MaybeTimeStyle.Render(a.AttemptedAt),
+				a.ObjectType,
+				a.Login,
+				result,
+				utils.MaybeEmptyStyle.Render(a.FailureReason),
+				utils.MaybeEmptyStyle.Render(a.RemoteIP),
+				utils.MaybeEmptyStyle.Render(a.Protocol),
+				utils.MaybeEmptyStyle.Render(a.Service),
+			)
+		}
+
+		fmt.Println(t.Render())
+		return nil
+	},
+}
+
+func init() {
+	LoginsListCmd.Flags().StringSliceP("type", "t", nil, "Only show attempts of these types (mailbox or remote)")
+	LoginsListCmd.Flags().Bool("failed", false, "Only show failed attempts")
+	LoginsListCmd.Flags().String("since", "", "Only show attempts at or after this time (timestamp or duration like '24h' or '7d')")
+	LoginsListCmd.Flags().Uint64P("limit", "n", 100, "Only show the most recent attempts (0 for no limit)")
+	LoginsListCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+}
+
+// parseLoginArgs splits logins into email addresses of mailboxes and names of
+// remotes.
+func parseLoginArgs(args []string) ([]*utils.EmailAddress, []string, error) {
+	var emails []*utils.EmailAddress
+	var remotes []string
+	for _, arg := range args {
+		if strings.Contains(arg, "@") {
+			email, err := utils.ParseEmailAddress(arg)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid email format: %s: %w", arg, err)
+			}
+			emails = append(emails, &email)
+			continue
+		}
+		remotes = append(remotes, arg)
+	}
+	return emails, remotes, nil
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var LoginsLockoutCmd = &cobra.Command{
+	Use:   "lockout [flags]",
+	Short: "Show login lockouts",
+	Long:  "Show the login lockouts per object type. Logins are locked, once the maximum number of failed attempts has been recorded within the period since the last successful attempt.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		lockouts, err := db.LoginLockouts(dbConn).List()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to list login lockouts", err)
+			return utils.ExitError{Code: 1}
+		}
+
+		if flagJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			if err := encoder.Encode(lockouts); err != nil {
+				utils.PrintErrorWithMessage("failed to encode JSON", err)
+			}
+			return nil
+		}
+
+		t := table.New().
+			Border(lipgloss.RoundedBorder()).
+			BorderStyle(utils.BlackStyle).
+			Headers("Type", "Max Failures", "Period", "Last Updated").
+			StyleFunc(func(row, col int) lipgloss.Style {
+				if row == table.HeaderRow {
+					return utils.TableHeaderStyle
+				}
+				return utils.TableRowStyle
+			})
+
+		for _, lockout := range lockouts {
+			t.Row(
+				lockout.ObjectType,
+				strconv.Itoa(int(lockout.MaxFailures)),
+				utils.FormatDuration(lockout.Period),
+				utils.MaybeTimeStyle.Render(lockout.UpdatedAt),
+			)
+		}
+
+		fmt.Println(t.Render())
+		return nil
+	},
+}
+
+var LoginsLockoutSetCmd = &cobra.Command{
+	Use:   "set <type> [<type>...] <max-failures> <period>",
+	Short: "Set login lockouts",
+	Long:  "Lock the logins of object types (mailbox or remote), once max-failures failed attempts have been recorded within the period. Use 'all' to set it for all object types.",
+	Args:  cobra.MinimumNArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		maxFailures, err := strconv.ParseInt(args[len(args)-2], 10, 32)
+		if err != nil || maxFailures <= 0 {
+			return fmt.Errorf("invalid max failures %q, must be a positive number", args[len(args)-2])
+		}
+
+		period, err := utils.ParseDuration(args[len(args)-1])
+		if err != nil {
+			return fmt.Errorf("invalid period: %w", err)
+		}
+		if period < time.Second {
+			return fmt.Errorf("period must be at least one second")
+		}
+
+		objectTypes, err := parseLoginLockoutObjectTypeArgs(args[:len(args)-2])
+		if err != nil {
+			return err
+		}
+
+		runner := db.TxForEachRunner[string]{
+			Items: objectTypes,
+			Exec: func(tx *sql.Tx, objectType string) error {
+				return db.LoginLockouts(tx).Set(objectType, int32(maxFailures), period)
+			},
+			ItemString: func(objectType string) string {
+				return objectType + " (" + strconv.FormatInt(maxFailures, 10) + " per " + utils.FormatDuration(period) + ")"
+			},
+			FailureMessage: "failed to set login lockout",
+			SuccessMessage: "Successfully set login lockout",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+		}
+
+		return runner.Run()
+	},
+}
+
+var LoginsLockoutUnsetCmd = &cobra.Command{
+	Use:   "unset <type> [<type>...]",
+	Short: "Unset login lockouts",
+	Long:  "Unset the login lockout of object types, so their logins aren't locked anymore. Use 'all' to unset it for all object types.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		objectTypes, err := parseLoginLockoutObjectTypeArgs(args)
+		if err != nil {
+			return err
+		}
+
+		runner := db.TxForEachRunner[string]{
+			Items: objectTypes,
+			Exec: func(tx *sql.Tx, objectType string) error {
+				return db.LoginLockouts(tx).Delete(objectType)
+			},
+			ItemString:     func(objectType string) string { return objectType },
+			FailureMessage: "failed to unset login lockout",
+			SuccessMessage: "Successfully unset login lockout",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	LoginsLockoutCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+
+	LoginsLockoutSetCmd.Flags().Bool("dry-run", false, "Show the affected rows without committing")
+	LoginsLockoutSetCmd.Flags().BoolP("json", "j", false, "Output the result of each item in JSON format")
+	LoginsLockoutUnsetCmd.Flags().Bool("dry-run", false, "Show the affected rows without committing")
+	LoginsLockoutUnsetCmd.Flags().BoolP("json", "j", false, "Output the result of each item in JSON format")
+
+	// Add subcommands
+	LoginsLockoutCmd.AddCommand(LoginsLockoutSetCmd)
+	LoginsLockoutCmd.AddCommand(LoginsLockoutUnsetCmd)
+}
+
+func parseLoginLockoutObjectTypeArgs(args []string) ([]string, error) {
+	if slices.Contains(args, "all") {
+		return db.LoginLockoutObjectTypes, nil
+	}
+
+	for _, arg := range args {
+		if !slices.Contains(db.LoginLockoutObjectTypes, arg) {
+			return nil, fmt.Errorf("invalid type %q, must be one of: all, %s", arg, strings.Join(db.LoginLockoutObjectTypes, ", "))
+		}
+	}
+	return args, nil
+}
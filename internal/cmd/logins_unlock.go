@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"database/sql"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var LoginsUnlockCmd = &cobra.Command{
+	Use:   "unlock <email|remote> [<email|remote>...]",
+	Short: "Unlock logins",
+	Long:  "Unlock the logins of mailboxes (by email) or remotes (by name). Their failed login attempts are kept, but don't count towards the login lockout and the --max-attempts limit of 'mailctl checkpassword' anymore. The unlock is recorded in the audit log of the mailbox or remote.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		if _, _, err := parseLoginArgs(args); err != nil {
+			return err
+		}
+
+		runner := db.TxForEachRunner[string]{
+			Items: args,
+			Exec: func(tx *sql.Tx, login string) error {
+				if email, err := utils.ParseEmailAddress(login); err == nil {
+					return db.MailboxesLoginAttempts(tx).Unlock(email)
+				}
+				return db.RemotesLoginAttempts(tx).Unlock(login)
+			},
+			ItemString:     func(login string) string { return login },
+			FailureMessage: "failed to unlock login",
+			SuccessMessage: "Successfully unlocked login",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	LoginsUnlockCmd.Flags().Bool("dry-run", false, "Show the affected rows without committing")
+	LoginsUnlockCmd.Flags().BoolP("json", "j", false, "Output the result of each item in JSON format")
+}
@@ -0,0 +1,13 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var MigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate objects from other mail management systems",
+}
+
+func init() {
+	// Add subcommands
+	MigrateCmd.AddCommand(MigratePostfixAdminCmd)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/migrate"
+	"github.com/gerolf-vent/mailctl/internal/state"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var MigratePostfixAdminCmd = &cobra.Command{
+	Use:   "postfixadmin --source-dsn <dsn> [flags]",
+	Short: "Migrate from a PostfixAdmin database",
+	Long: "Reads the domain, mailbox, alias and alias_domain tables of a PostfixAdmin database (PostgreSQL) and imports them in a single transaction.\n" +
+		"Domains become managed domains with the transport given by --transport, alias domains canonical domains and aliases of a domain catchall targets.\n" +
+		"Everything, which can't be mapped (e.g. vacation messages or fetchmail configurations), is reported.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagSourceDSN, _ := cmd.Flags().GetString("source-dsn")
+		flagTransport, _ := cmd.Flags().GetString("transport")
+		flagOnConflict, _ := cmd.Flags().GetString("on-conflict")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		onConflict := state.ConflictPolicy(flagOnConflict)
+		if !slices.Contains(state.ConflictPolicies, onConflict) {
+			return fmt.Errorf("invalid --on-conflict value %q, must be one of: skip, overwrite, fail", flagOnConflict)
+		}
+
+		sourceConn, err := sql.Open("postgres", flagSourceDSN)
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to PostfixAdmin database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := sourceConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close PostfixAdmin database connection", err)
+			}
+		}()
+
+		pfa, err := migrate.ReadPostfixAdmin(sourceConn)
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to read PostfixAdmin database", err)
+			return utils.ExitError{Code: 1}
+		}
+
+		desired, issues := pfa.Document(flagTransport)
+		for _, issue := range issues {
+			utils.PrintWarning(issue.String())
+		}
+
+		if err := desired.Validate(); err != nil {
+			utils.PrintErrorWithMessage("failed to map PostfixAdmin database", err)
+			return utils.ExitError{Code: 1}
+		}
+		if err := desired.Normalize(); err != nil {
+			utils.PrintErrorWithMessage("failed to map PostfixAdmin database", err)
+			return utils.ExitError{Code: 1}
+		}
+
+		// Record a default reason for the changes
+		session := db.CurrentSession()
+		if session.Reason == "" {
+			session.Reason = "migration from PostfixAdmin"
+			db.SetSession(session)
+		}
+
+		runner := StateRunner{
+			Desired:        desired,
+			PlanOptions:    state.PlanOptions{OnConflict: onConflict},
+			DryRun:         flagDryRun,
+			ItemString:     "PostfixAdmin",
+			FailureMessage: "failed to migrate",
+			SuccessMessage: "Successfully migrated",
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	MigratePostfixAdminCmd.Flags().String("source-dsn", "", "Connection string of the PostfixAdmin database (e.g. \"host=old-db user=postfix dbname=postfix\")")
+	MigratePostfixAdminCmd.Flags().String("transport", "", "Name of the transport of managed domains (must exist)")
+	MigratePostfixAdminCmd.Flags().String("on-conflict", string(state.ConflictFail), "How to handle objects existing in a different state (skip, overwrite or fail)")
+	MigratePostfixAdminCmd.Flags().Bool("dry-run", false, "Only show the report and the plan without migrating")
+	_ = MigratePostfixAdminCmd.MarkFlagRequired("source-dsn")
+	_ = MigratePostfixAdminCmd.MarkFlagRequired("transport")
+}
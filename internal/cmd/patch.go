@@ -8,6 +8,14 @@ var PatchCmd = &cobra.Command{
 }
 
 func init() {
+	// Add common flags
+	PatchCmd.PersistentFlags().Bool("dry-run", false, "Show the affected rows (including cascaded changes) without committing")
+	PatchCmd.PersistentFlags().BoolP("json", "j", false, "Output the result of each item in JSON format")
+	PatchCmd.PersistentFlags().Bool("atomic", false, "Process all items in a single transaction, which is rolled back on the first failure")
+	PatchCmd.PersistentFlags().Int("parallel", 1, "Process up to this many items concurrently, each in its own transaction")
+	PatchCmd.MarkFlagsMutuallyExclusive("atomic", "parallel")
+	PatchCmd.PersistentFlags().String("from-file", "", "Read the items from a CSV, JSONL or newline-separated file ('-' for stdin)")
+
 	// Add subcommands
 	PatchCmd.AddCommand(PatchDomainsCmd)
 	PatchCmd.AddCommand(PatchMailboxesCmd)
@@ -17,4 +25,5 @@ func init() {
 	PatchCmd.AddCommand(PatchRecipientsRelayedCmd)
 	PatchCmd.AddCommand(PatchTransportsCmd)
 	PatchCmd.AddCommand(PatchRemotesCmd)
+	PatchCmd.AddCommand(PatchVacationCmd)
 }
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/gerolf-vent/mailctl/internal/utils"
@@ -14,37 +13,58 @@ var PatchAliasesCmd = &cobra.Command{
 	Aliases: []string{"alias"},
 	Short:   "Updates existing aliases",
 	Long:    "Updates specified properties of existing aliases.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		flagEnabled, _ := cmd.Flags().GetBool("enabled")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
-		if !cmd.Flags().Changed("enabled") {
-			return fmt.Errorf("no changes specified")
+		rows, err := ReadItemRows(cmd, args, []string{"email"}, "enabled")
+		if err != nil {
+			return err
 		}
 
-		argEmails := ParseEmailArgs(args)
-		if len(argEmails) != len(args) {
-			return fmt.Errorf("invalid email arguments")
+		type aliasItem struct {
+			email   utils.EmailAddress
+			options db.AliasesPatchOptions
 		}
 
-		options := db.AliasesPatchOptions{}
+		items := make([]aliasItem, 0, len(rows))
+		for _, row := range rows {
+			if !row.Changed("enabled") {
+				return row.Errorf("no changes specified")
+			}
 
-		if cmd.Flags().Changed("enabled") {
-			options.Enabled = &flagEnabled
+			email, err := row.Email(0)
+			if err != nil {
+				return err
+			}
+
+			options := db.AliasesPatchOptions{}
+			if row.Changed("enabled") {
+				flagEnabled, _ := row.GetBool("enabled")
+				options.Enabled = &flagEnabled
+			}
+
+			items = append(items, aliasItem{email: email, options: options})
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddress]{
-			Items: argEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddress) error {
-				return db.Aliases(tx).Patch(item, options)
+		runner := db.TxForEachRunner[aliasItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item aliasItem) error {
+				return db.Aliases(tx).Patch(item.email, item.options)
 			},
-			ItemString:     func(item utils.EmailAddress) string { return item.String() },
+			ItemString:     func(item aliasItem) string { return item.email.String() },
 			FailureMessage: "failed to patch alias",
 			SuccessMessage: "Successfully patched alias",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/gerolf-vent/mailctl/internal/utils"
@@ -10,46 +9,73 @@ import (
 )
 
 var PatchAliasTargetsCmd = &cobra.Command{
-	Use:     "alias-targets [flags] <email> [<email>...]",
+	Use:     "alias-targets [flags] <alias-email> <target-email> [<target-email>...]",
 	Aliases: []string{"alias-target", "targets", "target"},
 	Short:   "Updates existing alias targets",
 	Long:    "Updates specified properties for existing alias targets.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if !cmd.Flags().Changed("forward") && !cmd.Flags().Changed("send") {
-			return fmt.Errorf("at least one of --forward or --send flags must be specified")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+
+		rows, err := ReadItemRows(cmd, args, []string{"alias", "target"}, "forward", "send")
+		if err != nil {
+			return err
 		}
 
-		argEmails := ParseEmailArgs(args)
-		if len(argEmails) != len(args) {
-			return fmt.Errorf("invalid email arguments")
+		type aliasTargetItem struct {
+			aliasEmail  utils.EmailAddress
+			targetEmail utils.EmailAddress
+			options     db.AliasesTargetsPatchOptions
 		}
 
-		argAliasEmail := argEmails[0]
-		argTargetEmails := argEmails[1:]
+		items := make([]aliasTargetItem, 0, len(rows))
+		for _, row := range rows {
+			if !row.Changed("forward") && !row.Changed("send") {
+				return row.Errorf("at least one of --forward or --send flags must be specified")
+			}
 
-		options := db.AliasesTargetsPatchOptions{}
-		if cmd.Flags().Changed("forward") {
-			flagForward, _ := cmd.Flags().GetBool("forward")
-			options.ForwardingToTargetEnabled = &flagForward
-		}
-		if cmd.Flags().Changed("send") {
-			flagSend, _ := cmd.Flags().GetBool("send")
-			options.SendingFromTargetEnabled = &flagSend
+			aliasEmail, err := row.Email(0)
+			if err != nil {
+				return err
+			}
+			targetEmail, err := row.Email(1)
+			if err != nil {
+				return err
+			}
+
+			options := db.AliasesTargetsPatchOptions{}
+			if row.Changed("forward") {
+				flagForward, _ := row.GetBool("forward")
+				options.ForwardingToTargetEnabled = &flagForward
+			}
+			if row.Changed("send") {
+				flagSend, _ := row.GetBool("send")
+				options.SendingFromTargetEnabled = &flagSend
+			}
+
+			items = append(items, aliasTargetItem{aliasEmail: aliasEmail, targetEmail: targetEmail, options: options})
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddress]{
-			Items: argTargetEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddress) error {
-				return db.AliasesTargets(tx).Patch(argAliasEmail, item, options)
+		runner := db.TxForEachRunner[aliasTargetItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item aliasTargetItem) error {
+				return db.AliasesTargets(tx).Patch(item.aliasEmail, item.targetEmail, item.options)
+			},
+			ItemString: func(item aliasTargetItem) string {
+				return item.aliasEmail.String() + " -> " + item.targetEmail.String()
 			},
-			ItemString:     func(item utils.EmailAddress) string { return argAliasEmail.String() + " -> " + item.String() },
 			FailureMessage: "failed to patch alias target",
 			SuccessMessage: "Successfully patch alias target",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/spf13/cobra"
@@ -13,45 +12,67 @@ var PatchDomainsCmd = &cobra.Command{
 	Aliases: []string{"domain"},
 	Short:   "Updates existing domains",
 	Long:    "Updates specified properties for existing domains.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		flagEnabled, _ := cmd.Flags().GetBool("enabled")
-		flagTransport, _ := cmd.Flags().GetString("transport")
-		flagTargetDomain, _ := cmd.Flags().GetString("target-domain")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
-		// Check if at least one flag was changed
-		if !cmd.Flags().Changed("enabled") && !cmd.Flags().Changed("transport") && !cmd.Flags().Changed("target-domain") {
-			return fmt.Errorf("no changes specified")
+		rows, err := ReadItemRows(cmd, args, []string{"fqdn"}, "enabled", "transport", "target-domain")
+		if err != nil {
+			return err
 		}
 
-		argDomains := ParseDomainFQDNArgs(args)
-		if len(argDomains) != len(args) {
-			return fmt.Errorf("invalid domain arguments")
+		type domainItem struct {
+			fqdn    string
+			options db.DomainsPatchOptions
 		}
 
-		var options db.DomainsPatchOptions
-		if cmd.Flags().Changed("enabled") {
-			options.Enabled = &flagEnabled
-		}
-		if cmd.Flags().Changed("transport") {
-			options.TransportName = &flagTransport
-		}
-		if cmd.Flags().Changed("target-domain") {
-			options.TargetDomainFQDN = &flagTargetDomain
+		items := make([]domainItem, 0, len(rows))
+		for _, row := range rows {
+			// Check if at least one flag was changed
+			if !row.Changed("enabled") && !row.Changed("transport") && !row.Changed("target-domain") {
+				return row.Errorf("no changes specified")
+			}
+
+			fqdn, err := row.DomainFQDN(0)
+			if err != nil {
+				return err
+			}
+
+			var options db.DomainsPatchOptions
+			if row.Changed("enabled") {
+				flagEnabled, _ := row.GetBool("enabled")
+				options.Enabled = &flagEnabled
+			}
+			if row.Changed("transport") {
+				flagTransport, _ := row.GetString("transport")
+				options.TransportName = &flagTransport
+			}
+			if row.Changed("target-domain") {
+				flagTargetDomain, _ := row.GetString("target-domain")
+				options.TargetDomainFQDN = &flagTargetDomain
+			}
+
+			items = append(items, domainItem{fqdn: fqdn, options: options})
 		}
 
-		runner := db.TxForEachRunner[string]{
-			Items: argDomains,
-			Exec: func(tx *sql.Tx, item string) error {
-				return db.Domains(tx).Patch(item, options)
+		runner := db.TxForEachRunner[domainItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item domainItem) error {
+				return db.Domains(tx).Patch(item.fqdn, item.options)
 			},
-			ItemString:     func(item string) string { return item },
+			ItemString:     func(item domainItem) string { return item.fqdn },
 			FailureMessage: "failed to patch domain",
 			SuccessMessage: "Successfully patched domain",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
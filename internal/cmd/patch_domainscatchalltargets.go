@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/gerolf-vent/mailctl/internal/utils"
@@ -14,40 +13,65 @@ var PatchDomainCatchallTargetsCmd = &cobra.Command{
 	Aliases: []string{"catchall-target", "catchalls", "catchall"},
 	Short:   "Updates existing catchall targets",
 	Long:    "Updates specified properties for existing catchall targets.",
-	Args:    cobra.MinimumNArgs(2),
+	Args:    ItemArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if !cmd.Flags().Changed("forward") && !cmd.Flags().Changed("fallback-only") {
-			return fmt.Errorf("at least one of --forward or --fallback-only flags must be specified")
-		}
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
-		argDomain := args[0]
-		argEmails := ParseEmailArgs(args[1:])
-		if len(argEmails) != len(args)-1 {
-			return fmt.Errorf("invalid email arguments")
+		rows, err := ReadItemRows(cmd, args, []string{"domain", "target"}, "forward", "fallback-only")
+		if err != nil {
+			return err
 		}
 
-		options := db.DomainsCatchallTargetsPatchOptions{}
-		if cmd.Flags().Changed("forward") {
-			flagForward, _ := cmd.Flags().GetBool("forward")
-			options.ForwardingToTargetEnabled = &flagForward
+		type catchallTargetItem struct {
+			domain      string
+			targetEmail utils.EmailAddress
+			options     db.DomainsCatchallTargetsPatchOptions
 		}
-		if cmd.Flags().Changed("fallback-only") {
-			flagOnly, _ := cmd.Flags().GetBool("fallback-only")
-			options.FallbackOnly = &flagOnly
+
+		items := make([]catchallTargetItem, 0, len(rows))
+		for _, row := range rows {
+			if !row.Changed("forward") && !row.Changed("fallback-only") {
+				return row.Errorf("at least one of --forward or --fallback-only flags must be specified")
+			}
+
+			targetEmail, err := row.Email(1)
+			if err != nil {
+				return err
+			}
+
+			options := db.DomainsCatchallTargetsPatchOptions{}
+			if row.Changed("forward") {
+				flagForward, _ := row.GetBool("forward")
+				options.ForwardingToTargetEnabled = &flagForward
+			}
+			if row.Changed("fallback-only") {
+				flagOnly, _ := row.GetBool("fallback-only")
+				options.FallbackOnly = &flagOnly
+			}
+
+			items = append(items, catchallTargetItem{domain: row.Args[0], targetEmail: targetEmail, options: options})
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddress]{
-			Items: argEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddress) error {
-				return db.DomainsCatchallTargets(tx).Patch(argDomain, item, options)
+		runner := db.TxForEachRunner[catchallTargetItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item catchallTargetItem) error {
+				return db.DomainsCatchallTargets(tx).Patch(item.domain, item.targetEmail, item.options)
+			},
+			ItemString: func(item catchallTargetItem) string {
+				return "@" + item.domain + " -> " + item.targetEmail.String()
 			},
-			ItemString:     func(item utils.EmailAddress) string { return "@" + argDomain + " -> " + item.String() },
 			FailureMessage: "failed to patch catchall target",
 			SuccessMessage: "Successfully patched catchall target",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
@@ -14,13 +14,17 @@ var PatchMailboxesCmd = &cobra.Command{
 	Aliases: []string{"mailbox"},
 	Short:   "Updates existing mailboxes",
 	Long:    "Updates specified properties for existing mailboxes.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagPassword, _ := cmd.Flags().GetBool("password")
 		flagPasswordStdin, _ := cmd.Flags().GetBool("password-stdin")
 		flagPasswordMethod, _ := cmd.Flags().GetString("password-method")
 		flagPasswordHashOptions, _ := cmd.Flags().GetString("password-hash-options")
 		flagPasswordNo, _ := cmd.Flags().GetBool("no-password")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
 		if (flagPassword || flagPasswordStdin) && flagPasswordNo {
 			return fmt.Errorf("cannot use --no-password with --password or --password-stdin")
@@ -30,68 +34,98 @@ var PatchMailboxesCmd = &cobra.Command{
 			return fmt.Errorf("cannot use both --password and --password-stdin")
 		}
 
-		if len(args) > 1 && (flagPassword || flagPasswordStdin) {
-			return fmt.Errorf("cannot set password while updating multiple mailboxes")
+		if (flagPassword || flagPasswordStdin || flagPasswordNo) && cmd.Flags().Changed("password-hash") {
+			return fmt.Errorf("cannot use --password-hash with --password, --password-stdin or --no-password")
 		}
 
-		argEmails := ParseEmailArgs(args)
-		if len(argEmails) != len(args) {
-			return fmt.Errorf("invalid email arguments")
+		rows, err := ReadItemRows(cmd, args, []string{"email"},
+			"password-hash", "quota", "transport", "login", "receiving", "sending")
+		if err != nil {
+			return err
 		}
 
-		options := db.MailboxesPatchOptions{}
+		if len(rows) > 1 && (flagPassword || flagPasswordStdin) {
+			return fmt.Errorf("cannot set password while updating multiple mailboxes")
+		}
+
+		var passwordHash string
 		if flagPassword || flagPasswordStdin {
-			passwordHash, err := ReadPasswordHashed(flagPasswordMethod, flagPasswordHashOptions, flagPasswordStdin)
+			passwordHash, err = ReadPasswordHashed(flagPasswordMethod, flagPasswordHashOptions, flagPasswordStdin)
 			if err != nil {
 				utils.PrintErrorWithMessage("failed to read password", err)
-				return nil
+				return utils.ExitError{Code: 1}
 			}
-			options.PasswordHash = &sql.NullString{Valid: true, String: passwordHash}
 		}
-		if flagPasswordNo {
-			options.PasswordHash = &sql.NullString{Valid: false}
+
+		type mailboxItem struct {
+			email   utils.EmailAddress
+			options db.MailboxesPatchOptions
 		}
-		if cmd.Flags().Changed("quota") {
-			q, _ := cmd.Flags().GetInt32("quota")
-			if q <= 0 {
-				options.Quota = &sql.NullInt32{Valid: false}
-			} else {
-				options.Quota = &sql.NullInt32{Valid: true, Int32: q}
+
+		items := make([]mailboxItem, 0, len(rows))
+		for _, row := range rows {
+			email, err := row.Email(0)
+			if err != nil {
+				return err
 			}
-		}
-		if cmd.Flags().Changed("transport") {
-			transportName, _ := cmd.Flags().GetString("transport")
-			if transportName == "-" {
-				options.TransportName = &sql.NullString{Valid: false}
-			} else {
-				options.TransportName = &sql.NullString{Valid: true, String: transportName}
+
+			options := db.MailboxesPatchOptions{}
+			if passwordHash != "" {
+				options.PasswordHash = &sql.NullString{Valid: true, String: passwordHash}
+			} else if row.Changed("password-hash") {
+				flagPasswordHash, _ := row.GetString("password-hash")
+				options.PasswordHash = &sql.NullString{Valid: true, String: flagPasswordHash}
 			}
-		}
-		if cmd.Flags().Changed("login") {
-			v, _ := cmd.Flags().GetBool("login")
-			options.Login = &v
-		}
-		if cmd.Flags().Changed("receiving") {
-			v, _ := cmd.Flags().GetBool("receiving")
-			options.Receiving = &v
-		}
-		if cmd.Flags().Changed("sending") {
-			v, _ := cmd.Flags().GetBool("sending")
-			options.Sending = &v
+			if flagPasswordNo {
+				options.PasswordHash = &sql.NullString{Valid: false}
+			}
+			if row.Changed("quota") {
+				q, _ := row.GetInt32("quota")
+				if q <= 0 {
+					options.Quota = &sql.NullInt32{Valid: false}
+				} else {
+					options.Quota = &sql.NullInt32{Valid: true, Int32: q}
+				}
+			}
+			if row.Changed("transport") {
+				transportName, _ := row.GetString("transport")
+				if transportName == "-" {
+					options.TransportName = &sql.NullString{Valid: false}
+				} else {
+					options.TransportName = &sql.NullString{Valid: true, String: transportName}
+				}
+			}
+			if row.Changed("login") {
+				v, _ := row.GetBool("login")
+				options.Login = &v
+			}
+			if row.Changed("receiving") {
+				v, _ := row.GetBool("receiving")
+				options.Receiving = &v
+			}
+			if row.Changed("sending") {
+				v, _ := row.GetBool("sending")
+				options.Sending = &v
+			}
+
+			items = append(items, mailboxItem{email: email, options: options})
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddress]{
-			Items: argEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddress) error {
-				return db.Mailboxes(tx).Patch(item, options)
+		runner := db.TxForEachRunner[mailboxItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item mailboxItem) error {
+				return db.Mailboxes(tx).Patch(item.email, item.options)
 			},
-			ItemString:     func(item utils.EmailAddress) string { return item.String() },
+			ItemString:     func(item mailboxItem) string { return item.email.String() },
 			FailureMessage: "failed to patch mailbox",
 			SuccessMessage: "Successfully patched mailbox",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
@@ -101,6 +135,7 @@ func init() {
 	PatchMailboxesCmd.Flags().String("password-hash-options", "", "Password hash options (bcrypt: <cost>; argon2id: m=<number>,t=<number>,p=<number>)")
 	PatchMailboxesCmd.Flags().Bool("password-stdin", false, "Read new password from stdin")
 	PatchMailboxesCmd.Flags().Bool("no-password", false, "Remove password")
+	PatchMailboxesCmd.Flags().String("password-hash", "", "Set an already hashed password (bcrypt or argon2id)")
 	PatchMailboxesCmd.Flags().Int32P("quota", "q", 0, "New quota in bytes")
 	PatchMailboxesCmd.Flags().String("transport", "", "New transport name")
 	PatchMailboxesCmd.Flags().BoolP("login", "l", true, "Enable or disable login")
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/gerolf-vent/mailctl/internal/utils"
@@ -14,36 +13,58 @@ var PatchRecipientsRelayedCmd = &cobra.Command{
 	Aliases: []string{"recipient-relayed", "relayed-recipients", "relayed-recipient", "relayed"},
 	Short:   "Updates existing relayed recipients",
 	Long:    "Updates specified properties for existing relayed recipients. Emails must be in the format \"name@example.com\".",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		flagEnabled, _ := cmd.Flags().GetBool("enabled")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
-		if !cmd.Flags().Changed("enabled") {
-			return fmt.Errorf("no changes specified")
+		rows, err := ReadItemRows(cmd, args, []string{"email"}, "enabled")
+		if err != nil {
+			return err
 		}
 
-		argEmails := ParseEmailArgs(args)
-		if len(argEmails) != len(args) {
-			return fmt.Errorf("invalid email arguments")
+		type recipientItem struct {
+			email   utils.EmailAddress
+			options db.RecipientsRelayedPatchOptions
 		}
 
-		options := db.RecipientsRelayedPatchOptions{}
-		if cmd.Flags().Changed("enabled") {
-			options.Enabled = &flagEnabled
+		items := make([]recipientItem, 0, len(rows))
+		for _, row := range rows {
+			if !row.Changed("enabled") {
+				return row.Errorf("no changes specified")
+			}
+
+			email, err := row.Email(0)
+			if err != nil {
+				return err
+			}
+
+			options := db.RecipientsRelayedPatchOptions{}
+			if row.Changed("enabled") {
+				flagEnabled, _ := row.GetBool("enabled")
+				options.Enabled = &flagEnabled
+			}
+
+			items = append(items, recipientItem{email: email, options: options})
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddress]{
-			Items: argEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddress) error {
-				return db.RecipientsRelayed(tx).Patch(item, options)
+		runner := db.TxForEachRunner[recipientItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item recipientItem) error {
+				return db.RecipientsRelayed(tx).Patch(item.email, item.options)
 			},
-			ItemString:     func(item utils.EmailAddress) string { return item.String() },
+			ItemString:     func(item recipientItem) string { return item.email.String() },
 			FailureMessage: "failed to patch relayed recipient",
 			SuccessMessage: "Successfully patched relayed recipient",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
@@ -13,14 +13,17 @@ var PatchRemotesCmd = &cobra.Command{
 	Use:   "remotes [flags] <hostname> [<hostname>...]",
 	Short: "Updates existing remotes",
 	Long:  "Updates specified properties for existing remotes.",
-	Args:  cobra.MinimumNArgs(1),
+	Args:  ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		flagEnabled, _ := cmd.Flags().GetBool("enabled")
 		flagPassword, _ := cmd.Flags().GetBool("password")
 		flagPasswordStdin, _ := cmd.Flags().GetBool("password-stdin")
 		flagPasswordMethod, _ := cmd.Flags().GetString("password-method")
 		flagPasswordHashOptions, _ := cmd.Flags().GetString("password-hash-options")
 		flagPasswordNo, _ := cmd.Flags().GetBool("no-password")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
 		if (flagPassword || flagPasswordStdin) && flagPasswordNo {
 			return fmt.Errorf("cannot use --no-password with --password or --password-stdin")
@@ -30,49 +33,82 @@ var PatchRemotesCmd = &cobra.Command{
 			return fmt.Errorf("cannot use both --password and --password-stdin")
 		}
 
-		if len(args) > 1 && (flagPassword || flagPasswordStdin) {
-			return fmt.Errorf("cannot set password while updating multiple remotes")
+		if (flagPassword || flagPasswordStdin || flagPasswordNo) && cmd.Flags().Changed("password-hash") {
+			return fmt.Errorf("cannot use --password-hash with --password, --password-stdin or --no-password")
 		}
 
-		if !flagPassword && !flagPasswordStdin && !flagPasswordNo && !cmd.Flags().Changed("enabled") {
-			return fmt.Errorf("no changes specified. Use --password, --no-password, or --enabled flags")
+		rows, err := ReadItemRows(cmd, args, []string{"name"}, "password-hash", "enabled")
+		if err != nil {
+			return err
 		}
 
-		options := db.RemotesPatchOptions{}
-
-		if cmd.Flags().Changed("enabled") {
-			options.Enabled = &flagEnabled
+		if len(rows) > 1 && (flagPassword || flagPasswordStdin) {
+			return fmt.Errorf("cannot set password while updating multiple remotes")
 		}
 
+		var passwordHash string
 		if flagPassword || flagPasswordStdin {
-			passwordHash, err := ReadPasswordHashed(flagPasswordMethod, flagPasswordHashOptions, flagPasswordStdin)
+			passwordHash, err = ReadPasswordHashed(flagPasswordMethod, flagPasswordHashOptions, flagPasswordStdin)
 			if err != nil {
 				utils.PrintErrorWithMessage("failed to read password", err)
-				return nil
-			}
-			options.PasswordHash = &sql.NullString{
-				String: passwordHash,
-				Valid:  true,
+				return utils.ExitError{Code: 1}
 			}
 		}
-		if flagPasswordNo {
-			options.PasswordHash = &sql.NullString{
-				Valid: false,
+
+		type remoteItem struct {
+			name    string
+			options db.RemotesPatchOptions
+		}
+
+		items := make([]remoteItem, 0, len(rows))
+		for _, row := range rows {
+			if passwordHash == "" && !flagPasswordNo && !row.Changed("password-hash") && !row.Changed("enabled") {
+				return row.Errorf("no changes specified. Use --password, --password-hash, --no-password, or --enabled flags")
 			}
+
+			options := db.RemotesPatchOptions{}
+
+			if row.Changed("enabled") {
+				flagEnabled, _ := row.GetBool("enabled")
+				options.Enabled = &flagEnabled
+			}
+
+			if passwordHash != "" {
+				options.PasswordHash = &sql.NullString{
+					String: passwordHash,
+					Valid:  true,
+				}
+			} else if row.Changed("password-hash") {
+				flagPasswordHash, _ := row.GetString("password-hash")
+				options.PasswordHash = &sql.NullString{
+					String: flagPasswordHash,
+					Valid:  true,
+				}
+			}
+			if flagPasswordNo {
+				options.PasswordHash = &sql.NullString{
+					Valid: false,
+				}
+			}
+
+			items = append(items, remoteItem{name: row.Args[0], options: options})
 		}
 
-		runner := db.TxForEachRunner[string]{
-			Items: args,
-			Exec: func(tx *sql.Tx, item string) error {
-				return db.Remotes(tx).Patch(item, options)
+		runner := db.TxForEachRunner[remoteItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item remoteItem) error {
+				return db.Remotes(tx).Patch(item.name, item.options)
 			},
-			ItemString:     func(item string) string { return item },
+			ItemString:     func(item remoteItem) string { return item.name },
 			FailureMessage: "failed to patch remote",
 			SuccessMessage: "Successfully patched remote",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
@@ -83,4 +119,5 @@ func init() {
 	PatchRemotesCmd.Flags().String("password-hash-options", "", "Password hash options (bcrypt: <cost>; argon2id: m=<number>,t=<number>,p=<number>)")
 	PatchRemotesCmd.Flags().Bool("password-stdin", false, "Read new password from stdin")
 	PatchRemotesCmd.Flags().Bool("no-password", false, "Remove password")
+	PatchRemotesCmd.Flags().String("password-hash", "", "Set an already hashed password (bcrypt or argon2id)")
 }
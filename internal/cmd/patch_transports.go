@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/spf13/cobra"
@@ -13,48 +12,70 @@ var PatchTransportsCmd = &cobra.Command{
 	Aliases: []string{"transport"},
 	Short:   "Updates existing transports",
 	Long:    "Updates specified properties of an existing transport.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		flagMethod, _ := cmd.Flags().GetString("method")
-		flagHost, _ := cmd.Flags().GetString("host")
-		flagPort, _ := cmd.Flags().GetUint16("port")
-		flagMxLookup, _ := cmd.Flags().GetBool("mx-lookup")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
 
-		// Check if at least one flag was changed
-		if !cmd.Flags().Changed("method") && !cmd.Flags().Changed("host") && !cmd.Flags().Changed("port") && !cmd.Flags().Changed("mx-lookup") {
-			return fmt.Errorf("no changes specified")
+		rows, err := ReadItemRows(cmd, args, []string{"name"}, "method", "host", "port", "mx-lookup")
+		if err != nil {
+			return err
 		}
 
-		options := db.TransportsPatchOptions{}
-		if cmd.Flags().Changed("method") {
-			options.Method = &flagMethod
+		type transportItem struct {
+			name    string
+			options db.TransportsPatchOptions
 		}
-		if cmd.Flags().Changed("host") {
-			options.Host = &flagHost
-		}
-		if cmd.Flags().Changed("port") {
-			if flagPort > 0 {
-				options.Port = &sql.NullInt32{Int32: int32(flagPort), Valid: true}
-			} else {
-				options.Port = &sql.NullInt32{Valid: false}
+
+		items := make([]transportItem, 0, len(rows))
+		for _, row := range rows {
+			// Check if at least one flag was changed
+			if !row.Changed("method") && !row.Changed("host") && !row.Changed("port") && !row.Changed("mx-lookup") {
+				return row.Errorf("no changes specified")
 			}
-		}
-		if cmd.Flags().Changed("mx-lookup") {
-			options.MxLookup = &flagMxLookup
+
+			options := db.TransportsPatchOptions{}
+			if row.Changed("method") {
+				flagMethod, _ := row.GetString("method")
+				options.Method = &flagMethod
+			}
+			if row.Changed("host") {
+				flagHost, _ := row.GetString("host")
+				options.Host = &flagHost
+			}
+			if row.Changed("port") {
+				flagPort, _ := row.GetUint16("port")
+				if flagPort > 0 {
+					options.Port = &sql.NullInt32{Int32: int32(flagPort), Valid: true}
+				} else {
+					options.Port = &sql.NullInt32{Valid: false}
+				}
+			}
+			if row.Changed("mx-lookup") {
+				flagMxLookup, _ := row.GetBool("mx-lookup")
+				options.MxLookup = &flagMxLookup
+			}
+
+			items = append(items, transportItem{name: row.Args[0], options: options})
 		}
 
-		runner := db.TxForEachRunner[string]{
-			Items: args,
-			Exec: func(tx *sql.Tx, item string) error {
-				return db.Transports(tx).Patch(item, options)
+		runner := db.TxForEachRunner[transportItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item transportItem) error {
+				return db.Transports(tx).Patch(item.name, item.options)
 			},
-			ItemString:     func(item string) string { return item },
+			ItemString:     func(item transportItem) string { return item.name },
 			FailureMessage: "failed to patch transport",
 			SuccessMessage: "Successfully patched transport",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
 
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var PatchVacationCmd = &cobra.Command{
+	Use:     "vacation [flags] <email> [<email>...]",
+	Aliases: []string{"vacations"},
+	Short:   "Updates vacation autoresponders of mailboxes",
+	Long:    "Updates specified properties for existing vacation autoresponders of mailboxes.",
+	Args:    ItemArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+
+		rows, err := ReadItemRows(cmd, args, []string{"email"},
+			"subject", "body", "body-file", "start", "end", "reply-interval", "exclude", "enabled")
+		if err != nil {
+			return err
+		}
+
+		if flagBodyFile, _ := cmd.Flags().GetString("body-file"); flagBodyFile == "-" && len(rows) > 1 {
+			return fmt.Errorf("cannot read body from stdin while updating multiple vacation autoresponders")
+		}
+
+		type vacationItem struct {
+			email   utils.EmailAddress
+			options db.MailboxesVacationPatchOptions
+		}
+
+		items := make([]vacationItem, 0, len(rows))
+		for _, row := range rows {
+			email, err := row.Email(0)
+			if err != nil {
+				return err
+			}
+
+			options := db.MailboxesVacationPatchOptions{}
+			changed := false
+
+			if row.Changed("subject") {
+				subject, _ := row.GetString("subject")
+				if subject == "" {
+					return row.Errorf("subject must not be empty")
+				}
+				options.Subject = &subject
+				changed = true
+			}
+			body, ok, err := readVacationBody(row)
+			if err != nil {
+				return err
+			}
+			if ok {
+				options.Body = &body
+				changed = true
+			}
+			if row.Changed("start") {
+				flagStart, _ := row.GetString("start")
+				startDate, err := parseVacationDate(flagStart)
+				if err != nil {
+					return row.Errorf("%w", err)
+				}
+				options.StartDate = &startDate
+				changed = true
+			}
+			if row.Changed("end") {
+				flagEnd, _ := row.GetString("end")
+				endDate, err := parseVacationDate(flagEnd)
+				if err != nil {
+					return row.Errorf("%w", err)
+				}
+				options.EndDate = &endDate
+				changed = true
+			}
+			if row.Changed("reply-interval") {
+				flagReplyInterval, _ := row.GetString("reply-interval")
+				replyInterval, err := parseVacationReplyInterval(flagReplyInterval)
+				if err != nil {
+					return row.Errorf("%w", err)
+				}
+				options.ReplyInterval = &replyInterval
+				changed = true
+			}
+			if row.Changed("exclude") {
+				flagExclude, _ := row.GetStringSlice("exclude")
+				excludedSenders, err := parseVacationExcludedSenders(flagExclude)
+				if err != nil {
+					return row.Errorf("%w", err)
+				}
+				options.ExcludedSenders = &excludedSenders
+				changed = true
+			}
+			if row.Changed("enabled") {
+				v, _ := row.GetBool("enabled")
+				options.Enabled = &v
+				changed = true
+			}
+
+			if !changed {
+				return row.Errorf("no changes specified. Use --subject, --body, --body-file, --start, --end, --reply-interval, --exclude or --enabled flags")
+			}
+
+			items = append(items, vacationItem{email: email, options: options})
+		}
+
+		runner := db.TxForEachRunner[vacationItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item vacationItem) error {
+				return db.MailboxesVacation(tx).Patch(item.email, item.options)
+			},
+			ItemString:     func(item vacationItem) string { return item.email.String() },
+			FailureMessage: "failed to patch vacation autoresponder",
+			SuccessMessage: "Successfully patched vacation autoresponder",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	PatchVacationCmd.Flags().String("subject", "", "New subject of the replies")
+	PatchVacationCmd.Flags().String("body", "", "New body of the replies")
+	PatchVacationCmd.Flags().String("body-file", "", "Read the new body of the replies from a file ('-' for stdin)")
+	PatchVacationCmd.Flags().String("start", "", "New first day of the vacation (YYYY-MM-DD, '-' to remove)")
+	PatchVacationCmd.Flags().String("end", "", "New last day of the vacation (YYYY-MM-DD, '-' to remove)")
+	PatchVacationCmd.Flags().String("reply-interval", "", "New minimum interval between two replies to the same sender (e.g. \"1d\", \"12h\")")
+	PatchVacationCmd.Flags().StringSlice("exclude", nil, "New senders, which are never answered (mail addresses or \"@domain\", empty to remove all)")
+	PatchVacationCmd.Flags().BoolP("enabled", "e", true, "Enable or disable the vacation autoresponder")
+}
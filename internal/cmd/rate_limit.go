@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var RateLimitCmd = &cobra.Command{
+	Use:   "rate-limit [flags]",
+	Short: "Show sending rate limits",
+	Long:  "Show the sending rate limits of mailboxes and remotes, which are enforced by 'mailctl serve policy'.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		rateLimits, err := db.RateLimits(dbConn).List()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to list rate limits", err)
+			return utils.ExitError{Code: 1}
+		}
+
+		if flagJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			if err := encoder.Encode(rateLimits); err != nil {
+				utils.PrintErrorWithMessage("failed to encode JSON", err)
+			}
+			return nil
+		}
+
+		t := table.New().
+			Border(lipgloss.RoundedBorder()).
+			BorderStyle(utils.BlackStyle).
+			Headers("Type", "Name", "Max Messages", "Period", "Last Updated").
+			StyleFunc(func(row, col int) lipgloss.Style {
+				if row == table.HeaderRow {
+					return utils.TableHeaderStyle
+				}
+				return utils.TableRowStyle
+			})
+
+		for _, rateLimit := range rateLimits {
+			t.Row(
+				rateLimit.ObjectType,
+				rateLimit.Name,
+				strconv.Itoa(int(rateLimit.MaxMessages)),
+				utils.FormatDuration(rateLimit.Period),
+				utils.MaybeTimeStyle.Render(rateLimit.UpdatedAt),
+			)
+		}
+
+		fmt.Println(t.Render())
+		return nil
+	},
+}
+
+var RateLimitSetCmd = &cobra.Command{
+	Use:   "set <type> <name> <max-messages> <period>",
+	Short: "Set a sending rate limit",
+	Long:  "Set the maximum number of messages a mailbox (by email) or remote (by name) can send within a period. An existing rate limit with the same period is updated.",
+	Args:  cobra.ExactArgs(4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		objectType, err := parseRateLimitObjectTypeArg(args[0])
+		if err != nil {
+			return err
+		}
+
+		maxMessages, err := strconv.ParseInt(args[2], 10, 32)
+		if err != nil || maxMessages <= 0 {
+			return fmt.Errorf("invalid max messages %q, must be a positive number", args[2])
+		}
+
+		period, err := parseRateLimitPeriodArg(args[3])
+		if err != nil {
+			return err
+		}
+
+		var exec func(tx *sql.Tx, name string) error
+		switch objectType {
+		case "mailbox":
+			email, err := utils.ParseEmailAddress(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid email address %q: %w", args[1], err)
+			}
+			exec = func(tx *sql.Tx, name string) error {
+				return db.RateLimits(tx).SetMailbox(email, period, int32(maxMessages))
+			}
+		case "remote":
+			exec = func(tx *sql.Tx, name string) error {
+				return db.RateLimits(tx).SetRemote(name, period, int32(maxMessages))
+			}
+		}
+
+		runner := db.TxForEachRunner[string]{
+			Items: []string{args[1]},
+			Exec:  exec,
+			ItemString: func(name string) string {
+				return name + " (" + strconv.FormatInt(maxMessages, 10) + " per " + utils.FormatDuration(period) + ")"
+			},
+			FailureMessage: "failed to set rate limit",
+			SuccessMessage: "Successfully set rate limit",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+		}
+
+		return runner.Run()
+	},
+}
+
+var RateLimitUnsetCmd = &cobra.Command{
+	Use:   "unset <type> <name> [<period>]",
+	Short: "Unset sending rate limits",
+	Long:  "Unset the sending rate limit of a mailbox (by email) or remote (by name) with the period. Without a period all of its rate limits are unset.",
+	Args:  cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		objectType, err := parseRateLimitObjectTypeArg(args[0])
+		if err != nil {
+			return err
+		}
+
+		var period *time.Duration
+		if len(args) == 3 {
+			p, err := parseRateLimitPeriodArg(args[2])
+			if err != nil {
+				return err
+			}
+			period = &p
+		}
+
+		var exec func(tx *sql.Tx, name string) error
+		switch objectType {
+		case "mailbox":
+			email, err := utils.ParseEmailAddress(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid email address %q: %w", args[1], err)
+			}
+			exec = func(tx *sql.Tx, name string) error {
+				return db.RateLimits(tx).DeleteMailbox(email, period)
+			}
+		case "remote":
+			exec = func(tx *sql.Tx, name string) error {
+				return db.RateLimits(tx).DeleteRemote(name, period)
+			}
+		}
+
+		runner := db.TxForEachRunner[string]{
+			Items: []string{args[1]},
+			Exec:  exec,
+			ItemString: func(name string) string {
+				if period != nil {
+					return name + " (" + utils.FormatDuration(*period) + ")"
+				}
+				return name
+			},
+			FailureMessage: "failed to unset rate limit",
+			SuccessMessage: "Successfully unset rate limit",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	RateLimitCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+
+	RateLimitSetCmd.Flags().Bool("dry-run", false, "Show the affected rows without committing")
+	RateLimitSetCmd.Flags().BoolP("json", "j", false, "Output the result in JSON format")
+	RateLimitUnsetCmd.Flags().Bool("dry-run", false, "Show the affected rows without committing")
+	RateLimitUnsetCmd.Flags().BoolP("json", "j", false, "Output the result in JSON format")
+
+	// Add subcommands
+	RateLimitCmd.AddCommand(RateLimitSetCmd)
+	RateLimitCmd.AddCommand(RateLimitUnsetCmd)
+}
+
+func parseRateLimitObjectTypeArg(arg string) (string, error) {
+	if !slices.Contains(db.RateLimitObjectTypes, arg) {
+		return "", fmt.Errorf("invalid type %q, must be one of: %s", arg, strings.Join(db.RateLimitObjectTypes, ", "))
+	}
+	return arg, nil
+}
+
+func parseRateLimitPeriodArg(arg string) (time.Duration, error) {
+	period, err := utils.ParseDuration(arg)
+	if err != nil {
+		return 0, fmt.Errorf("invalid period: %w", err)
+	}
+	if period < time.Second {
+		return 0, fmt.Errorf("period must be at least one second")
+	}
+	return period, nil
+}
@@ -8,6 +8,10 @@ var RenameCmd = &cobra.Command{
 }
 
 func init() {
+	// Add common flags
+	RenameCmd.PersistentFlags().Bool("dry-run", false, "Show the affected rows (including cascaded changes) without committing")
+	RenameCmd.PersistentFlags().BoolP("json", "j", false, "Output the result of each item in JSON format")
+
 	// Add subcommands
 	RenameCmd.AddCommand(RenameDomainsCmd)
 	RenameCmd.AddCommand(RenameMailboxesCmd)
@@ -15,6 +15,9 @@ var RenameAliasesCmd = &cobra.Command{
 	Long:    "Renames an alias by changing its email address. This also supports changing the domain without loosing any of the targets or other relations.",
 	Args:    cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
 		argEmails := ParseEmailArgs(args)
 		if len(argEmails) != len(args) {
 			return fmt.Errorf("invalid email arguments")
@@ -29,9 +32,10 @@ var RenameAliasesCmd = &cobra.Command{
 			ItemString:     fmt.Sprintf("%s -> %s", argOldEmail.String(), argNewEmail.String()),
 			FailureMessage: "failed to rename alias",
 			SuccessMessage: "Successfully renamed alias",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
@@ -15,6 +15,9 @@ var RenameDomainsCmd = &cobra.Command{
 	Long:    "Renames a domain by changing its FQDN. All contained mailboxes, aliases, recipients, and other relations will be renamed too.",
 	Args:    cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
 		argDomains := ParseDomainFQDNArgs(args)
 		if len(argDomains) != len(args) {
 			return fmt.Errorf("invalid domain arguments")
@@ -30,9 +33,10 @@ var RenameDomainsCmd = &cobra.Command{
 			ItemString:     fmt.Sprintf("%s -> %s", oldDomain, newDomain),
 			FailureMessage: "failed to rename domain",
 			SuccessMessage: "Successfully renamed domain",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
@@ -15,6 +15,9 @@ var RenameMailboxesCmd = &cobra.Command{
 	Long:    "Renames a mailbox. This also supports changing the domain without loosing any of the aliases, or other relations.",
 	Args:    cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
 		argEmails := ParseEmailArgs(args)
 		if len(argEmails) != len(args) {
 			return fmt.Errorf("invalid email arguments")
@@ -30,9 +33,10 @@ var RenameMailboxesCmd = &cobra.Command{
 			ItemString:     fmt.Sprintf("%s -> %s", oldEmail, newEmail),
 			FailureMessage: "failed to rename mailbox",
 			SuccessMessage: "Successfully renamed mailbox",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
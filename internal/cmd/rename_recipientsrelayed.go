@@ -15,6 +15,9 @@ var RenameRecipientsRelayedCmd = &cobra.Command{
 	Long:    "Renames a relayed recipient. This also supports changing the domain without loosing any of the relations.",
 	Args:    cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
 		argEmails := ParseEmailArgs(args)
 		if len(argEmails) != len(args) {
 			return fmt.Errorf("invalid email arguments")
@@ -29,9 +32,10 @@ var RenameRecipientsRelayedCmd = &cobra.Command{
 			ItemString:     fmt.Sprintf("%s -> %s", oldEmail.String(), newEmail.String()),
 			FailureMessage: "failed to rename relayed recipient",
 			SuccessMessage: "Successfully renamed relayed recipient",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
@@ -14,6 +14,9 @@ var RenameRemotesCmd = &cobra.Command{
 	Long:  "Renames a remote by changing its name.",
 	Args:  cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
 		oldName := args[0]
 		newName := args[1]
 
@@ -24,9 +27,10 @@ var RenameRemotesCmd = &cobra.Command{
 			ItemString:     fmt.Sprintf("%s -> %s", oldName, newName),
 			FailureMessage: "failed to rename remote",
 			SuccessMessage: "Successfully renamed remote",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
@@ -15,6 +15,9 @@ var RenameTransportsCmd = &cobra.Command{
 	Long:    "Renames a transport by changing its name.",
 	Args:    cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
 		oldName := args[0]
 		newName := args[1]
 
@@ -25,9 +28,10 @@ var RenameTransportsCmd = &cobra.Command{
 			ItemString:     fmt.Sprintf("%s -> %s", oldName, newName),
 			FailureMessage: "failed to rename transport",
 			SuccessMessage: "Successfully renamed transport",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
@@ -0,0 +1,14 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var RenderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render static configuration files from the database",
+}
+
+func init() {
+	// Add subcommands
+	RenderCmd.AddCommand(RenderPostfixMapsCmd)
+	RenderCmd.AddCommand(RenderSieveCmd)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/render"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var RenderPostfixMapsCmd = &cobra.Command{
+	Use:   "postfix-maps --out <dir> [flags]",
+	Short: "Render static Postfix lookup tables and Dovecot passwd-files",
+	Long: "Render the Postfix lookup tables and Dovecot passwd-files of all objects to a directory, e.g. for a standby server, which works without the database.\n" +
+		"Each value is the result of the postfix.* or dovecot.* function for its key, so the files answer lookups like the SQL configuration does.\n" +
+		"The files are replaced atomically, run 'postmap' on the Postfix lookup tables afterwards.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagOut, _ := cmd.Flags().GetString("out")
+		flagMaxDepth, _ := cmd.Flags().GetUint32("max-depth")
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		// Render all maps from the same snapshot
+		tx, err := dbConn.BeginTx(context.Background(), &sql.TxOptions{
+			Isolation: sql.LevelRepeatableRead,
+			ReadOnly:  true,
+		})
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to begin transaction", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		maps, warnings, err := render.Maps(tx, flagMaxDepth)
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to render maps", err)
+			return utils.ExitError{Code: 1}
+		}
+		for _, warning := range warnings {
+			utils.PrintWarning(warning)
+		}
+
+		if err := os.MkdirAll(flagOut, 0755); err != nil {
+			utils.PrintErrorWithMessage("failed to create output directory", err)
+			return utils.ExitError{Code: 1}
+		}
+
+		for _, m := range maps {
+			// Password hashes must only be readable by the owner
+			var perm os.FileMode = 0644
+			if m.Secret {
+				perm = 0600
+			}
+			if err := writeFileAtomic(filepath.Join(flagOut, m.Name), m.Bytes(), perm); err != nil {
+				utils.PrintErrorWithMessage("failed to write "+m.Name, err)
+				return utils.ExitError{Code: 1}
+			}
+		}
+
+		utils.PrintSuccess(fmt.Sprintf("Successfully rendered %d maps: %s", len(maps), flagOut))
+		return nil
+	},
+}
+
+// writeFileAtomic writes the data to a temporary file, which replaces the
+// file, so readers never see a partially written file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(file.Name())
+	}()
+
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Chmod(perm); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func init() {
+	RenderPostfixMapsCmd.Flags().String("out", "", "Output directory (created, if missing)")
+	RenderPostfixMapsCmd.Flags().Uint32("max-depth", 10000, "Maximum recursion depth of alias lookups (should match the SQL configuration)")
+	_ = RenderPostfixMapsCmd.MarkFlagRequired("out")
+}
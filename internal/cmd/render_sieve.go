@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/render"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var RenderSieveCmd = &cobra.Command{
+	Use:   "sieve --out <dir> [flags]",
+	Short: "Render Sieve scripts of vacation autoresponders",
+	Long: "Render a Sieve script '<email>.sieve' for each mailbox with an enabled vacation autoresponder to a directory, which Dovecot Pigeonhole runs e.g. with sieve_after.\n" +
+		"Each script is rendered from the result of the dovecot.vacation_mailboxes function and checks the start and end date at delivery time.\n" +
+		"The files are replaced atomically and scripts of mailboxes without a vacation autoresponder are removed, so the directory should only be used for these scripts.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagOut, _ := cmd.Flags().GetString("out")
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		// Render all scripts from the same snapshot
+		tx, err := dbConn.BeginTx(context.Background(), &sql.TxOptions{
+			Isolation: sql.LevelRepeatableRead,
+			ReadOnly:  true,
+		})
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to begin transaction", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		scripts, err := render.SieveScripts(tx)
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to render Sieve scripts", err)
+			return utils.ExitError{Code: 1}
+		}
+
+		if err := os.MkdirAll(flagOut, 0755); err != nil {
+			utils.PrintErrorWithMessage("failed to create output directory", err)
+			return utils.ExitError{Code: 1}
+		}
+
+		rendered := make(map[string]bool, len(scripts))
+		for _, script := range scripts {
+			name := script.Email.String() + ".sieve"
+			if err := writeFileAtomic(filepath.Join(flagOut, name), script.Source, 0644); err != nil {
+				utils.PrintErrorWithMessage("failed to write "+name, err)
+				return utils.ExitError{Code: 1}
+			}
+			rendered[name] = true
+		}
+
+		// Remove the scripts of vacation autoresponders, which have been
+		// disabled or deleted
+		entries, err := os.ReadDir(flagOut)
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to read output directory", err)
+			return utils.ExitError{Code: 1}
+		}
+		removed := 0
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasSuffix(name, ".sieve") || !strings.Contains(name, "@") || rendered[name] {
+				continue
+			}
+			if err := os.Remove(filepath.Join(flagOut, name)); err != nil {
+				utils.PrintErrorWithMessage("failed to remove "+name, err)
+				return utils.ExitError{Code: 1}
+			}
+			// Remove the compiled script as well
+			_ = os.Remove(filepath.Join(flagOut, strings.TrimSuffix(name, ".sieve")+".svbin"))
+			removed++
+		}
+
+		utils.PrintSuccess(fmt.Sprintf("Successfully rendered %d Sieve scripts and removed %d: %s", len(scripts), removed, flagOut))
+		return nil
+	},
+}
+
+func init() {
+	RenderSieveCmd.Flags().String("out", "", "Output directory (created, if missing)")
+	_ = RenderSieveCmd.MarkFlagRequired("out")
+}
@@ -8,6 +8,14 @@ var RestoreCmd = &cobra.Command{
 }
 
 func init() {
+	// Add common flags
+	RestoreCmd.PersistentFlags().Bool("dry-run", false, "Show the affected rows (including cascaded changes) without committing")
+	RestoreCmd.PersistentFlags().BoolP("json", "j", false, "Output the result of each item in JSON format")
+	RestoreCmd.PersistentFlags().Bool("atomic", false, "Process all items in a single transaction, which is rolled back on the first failure")
+	RestoreCmd.PersistentFlags().Int("parallel", 1, "Process up to this many items concurrently, each in its own transaction")
+	RestoreCmd.MarkFlagsMutuallyExclusive("atomic", "parallel")
+	RestoreCmd.PersistentFlags().String("from-file", "", "Read the items from a CSV, JSONL or newline-separated file ('-' for stdin)")
+
 	// Add subcommands
 	RestoreCmd.AddCommand(RestoreDomainsCmd)
 	RestoreCmd.AddCommand(RestoreMailboxesCmd)
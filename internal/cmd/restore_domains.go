@@ -2,22 +2,28 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/spf13/cobra"
 )
 
 var RestoreDomainsCmd = &cobra.Command{
-	Use:     "domains <fqdn> [<fqdn>...]",
+	Use:     "domains [flags] <fqdn> [<fqdn>...]",
 	Aliases: []string{"domain"},
 	Short:   "Restores soft-deleted domains",
 	Long:    "Restores soft-deleted domains.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		argDomains := ParseDomainFQDNArgs(args)
-		if len(argDomains) != len(args) {
-			return fmt.Errorf("invalid domain arguments")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+		flagYes, _ := cmd.Flags().GetBool("yes")
+		flagConfirmThreshold, _ := cmd.Flags().GetInt("confirm-threshold")
+
+		argDomains, err := ReadItemDomainFQDNs(cmd, args, "fqdn")
+		if err != nil {
+			return err
 		}
 
 		runner := db.TxForEachRunner[string]{
@@ -28,9 +34,22 @@ var RestoreDomainsCmd = &cobra.Command{
 			ItemString:     func(item string) string { return item },
 			FailureMessage: "failed to restore domain",
 			SuccessMessage: "Successfully restored domain",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
+			Impact: &db.ImpactOptions{
+				Action:    "Restoring domain",
+				Threshold: flagConfirmThreshold,
+				Yes:       flagYes,
+			},
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
+
+func init() {
+	RestoreDomainsCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation, if many dependent objects are restored as well")
+	RestoreDomainsCmd.Flags().Int("confirm-threshold", 10, "Ask for confirmation, if more dependent objects are restored as well")
+}
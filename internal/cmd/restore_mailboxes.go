@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/gerolf-vent/mailctl/internal/utils"
@@ -10,15 +9,22 @@ import (
 )
 
 var RestoreMailboxesCmd = &cobra.Command{
-	Use:     "mailboxes <email> [<email>...]",
+	Use:     "mailboxes [flags] <email> [<email>...]",
 	Aliases: []string{"mailbox"},
 	Short:   "Restores soft-deleted mailboxes",
 	Long:    "Restores soft-deleted mailboxes.",
-	Args:    cobra.MinimumNArgs(1),
+	Args:    ItemArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		argEmails := ParseEmailArgs(args)
-		if len(argEmails) != len(args) {
-			return fmt.Errorf("invalid email arguments")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+		flagYes, _ := cmd.Flags().GetBool("yes")
+		flagConfirmThreshold, _ := cmd.Flags().GetInt("confirm-threshold")
+
+		argEmails, err := ReadItemEmails(cmd, args, "email")
+		if err != nil {
+			return err
 		}
 
 		runner := db.TxForEachRunner[utils.EmailAddress]{
@@ -29,9 +35,22 @@ var RestoreMailboxesCmd = &cobra.Command{
 			ItemString:     func(item utils.EmailAddress) string { return item.String() },
 			FailureMessage: "failed to restore mailbox",
 			SuccessMessage: "Successfully restored mailbox",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
+			Impact: &db.ImpactOptions{
+				Action:    "Restoring mailbox",
+				Threshold: flagConfirmThreshold,
+				Yes:       flagYes,
+			},
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
+
+func init() {
+	RestoreMailboxesCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation, if many dependent objects are restored as well")
+	RestoreMailboxesCmd.Flags().Int("confirm-threshold", 10, "Ask for confirmation, if more dependent objects are restored as well")
+}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/gerolf-vent/mailctl/internal/utils"
@@ -13,25 +12,47 @@ var RestoreRemoteSendGrantsCmd = &cobra.Command{
 	Use:   "send-grant <remote-name> <email> [<email>...]",
 	Short: "Restores soft-deleted send grants for a remote",
 	Long:  "Restores soft-deleted send grants for a remote.",
-	Args:  cobra.MinimumNArgs(2),
+	Args:  ItemArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		argRemoteName := args[0]
-		argEmails := ParseEmailOrWildcardArgs(args[1:])
-		if len(argEmails) != len(args[1:]) {
-			return fmt.Errorf("invalid email or domain argument")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagAtomic, _ := cmd.Flags().GetBool("atomic")
+		flagParallel, _ := cmd.Flags().GetInt("parallel")
+
+		rows, err := ReadItemRows(cmd, args, []string{"remote", "email"})
+		if err != nil {
+			return err
+		}
+
+		type sendGrantItem struct {
+			remoteName string
+			email      utils.EmailAddressOrWildcard
+		}
+
+		items := make([]sendGrantItem, 0, len(rows))
+		for _, row := range rows {
+			email, err := row.EmailOrWildcard(1)
+			if err != nil {
+				return err
+			}
+
+			items = append(items, sendGrantItem{remoteName: row.Args[0], email: email})
 		}
 
-		runner := db.TxForEachRunner[utils.EmailAddressOrWildcard]{
-			Items: argEmails,
-			Exec: func(tx *sql.Tx, item utils.EmailAddressOrWildcard) error {
-				return db.RemotesSendGrants(tx).Restore(argRemoteName, item)
+		runner := db.TxForEachRunner[sendGrantItem]{
+			Items: items,
+			Exec: func(tx *sql.Tx, item sendGrantItem) error {
+				return db.RemotesSendGrants(tx).Restore(item.remoteName, item.email)
 			},
-			ItemString:     func(item utils.EmailAddressOrWildcard) string { return argRemoteName + " -> " + item.String() },
+			ItemString:     func(item sendGrantItem) string { return item.remoteName + " -> " + item.email.String() },
 			FailureMessage: "failed to restore send grant",
 			SuccessMessage: "Successfully restored send grant",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+			Atomic:         flagAtomic,
+			Parallel:       flagParallel,
 		}
 
-		runner.Run()
-		return nil
+		return runner.Run()
 	},
 }
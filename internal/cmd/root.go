@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"errors"
 	"os"
 
+	"github.com/gerolf-vent/mailctl/internal/db"
 	"github.com/gerolf-vent/mailctl/internal/utils"
 	"github.com/spf13/cobra"
 )
@@ -13,9 +15,32 @@ var rootCmd = &cobra.Command{
 	Long:          `mailctl is a command-line interface for managing the mail database system including domains, mailboxes, aliases, transports, and more.`,
 	SilenceErrors: true,
 	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		flagAs, _ := cmd.Flags().GetString("as")
+		flagReason, _ := cmd.Flags().GetString("reason")
+
+		// Determine the operator recorded in the audit log
+		operator := flagAs
+		if operator == "" {
+			operator = os.Getenv("MAILCTL_OPERATOR")
+		}
+		if operator == "" {
+			operator = db.DefaultOperator()
+		}
+
+		db.SetSession(db.Session{
+			Operator: operator,
+			Reason:   flagReason,
+		})
+		return nil
+	},
 }
 
 func init() {
+	// Add global flags
+	rootCmd.PersistentFlags().String("as", "", "Operator name recorded in the audit log (default: $MAILCTL_OPERATOR or <os-user>@<hostname>)")
+	rootCmd.PersistentFlags().String("reason", "", "Reason for the change recorded in the audit log")
+
 	// Add main commands
 	rootCmd.AddCommand(ListCmd)
 	rootCmd.AddCommand(DescribeCmd)
@@ -27,10 +52,30 @@ func init() {
 	rootCmd.AddCommand(EnableCmd)
 	rootCmd.AddCommand(RestoreCmd)
 	rootCmd.AddCommand(SchemaCmd)
+	rootCmd.AddCommand(AuditCmd)
+	rootCmd.AddCommand(LoginsCmd)
+	rootCmd.AddCommand(GCCmd)
+	rootCmd.AddCommand(RateLimitCmd)
+	rootCmd.AddCommand(SieveCmd)
+	rootCmd.AddCommand(ApplyCmd)
+	rootCmd.AddCommand(DiffCmd)
+	rootCmd.AddCommand(ExportCmd)
+	rootCmd.AddCommand(ImportCmd)
+	rootCmd.AddCommand(MigrateCmd)
+	rootCmd.AddCommand(RenderCmd)
+	rootCmd.AddCommand(ServeCmd)
+	rootCmd.AddCommand(CheckpasswordCmd)
 }
 
 func Execute() {
 	cmd, err := rootCmd.ExecuteC()
+
+	// The command has already reported the reason
+	var exitErr utils.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.Code)
+	}
+
 	if err != nil {
 		utils.PrintError(err)
 		if cmd == nil {
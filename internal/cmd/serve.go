@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var ServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run servers for mail system integrations",
+}
+
+func init() {
+	// Add subcommands
+	ServeCmd.AddCommand(ServeSocketmapCmd)
+	ServeCmd.AddCommand(ServePolicyCmd)
+}
+
+// parseListenAddress parses an address in Postfix notation (unix:<path> or
+// inet:<host>:<port>) into the network and address for net.Listen.
+func parseListenAddress(value string) (network string, address string, err error) {
+	network, address, ok := strings.Cut(value, ":")
+	if !ok || (network != "unix" && network != "inet") || address == "" {
+		return "", "", fmt.Errorf("invalid --listen value %q, must be unix:<path> or inet:<host>:<port>", value)
+	}
+	if network == "inet" {
+		network = "tcp"
+	}
+	return network, address, nil
+}
+
+// listen listens on the address. Unix sockets of a previous run are replaced
+// and get the file mode (octal).
+func listen(network, address, socketMode string) (net.Listener, error) {
+	mode, err := strconv.ParseUint(socketMode, 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid socket mode %q, must be an octal file mode", socketMode)
+	}
+
+	if network == "unix" {
+		if info, err := os.Lstat(address); err == nil && info.Mode()&os.ModeSocket != 0 {
+			_ = os.Remove(address)
+		}
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if network == "unix" {
+		if err := os.Chmod(address, os.FileMode(mode)); err != nil {
+			_ = listener.Close()
+			return nil, err
+		}
+	}
+	return listener, nil
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/policy"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var ServePolicyCmd = &cobra.Command{
+	Use:   "policy --listen <address> [flags]",
+	Short: "Serve the Postfix SMTP access policy",
+	Long: "Answers the requests of Postfix's check_policy_service with the SMTP access policy delegation protocol.\n" +
+		"Senders are rejected, if the SASL login doesn't own them according to postfix.smtpd_sender_login_maps (like reject_sender_login_mismatch).\n" +
+		"Messages are deferred, if the mailbox or remote of the SASL login exceeds one of its rate limits (see 'mailctl rate-limit').\n" +
+		"The address is either unix:<path> or inet:<host>:<port>.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagListen, _ := cmd.Flags().GetString("listen")
+		flagSocketMode, _ := cmd.Flags().GetString("socket-mode")
+		flagMaxConnections, _ := cmd.Flags().GetInt("max-connections")
+		flagMaxDepth, _ := cmd.Flags().GetUint32("max-depth")
+		flagRecipientDelimiter, _ := cmd.Flags().GetString("recipient-delimiter")
+
+		network, address, err := parseListenAddress(flagListen)
+		if err != nil {
+			return err
+		}
+		if flagMaxConnections < 1 {
+			return fmt.Errorf("--max-connections must be at least 1")
+		}
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+		dbConn.SetMaxOpenConns(flagMaxConnections)
+		dbConn.SetMaxIdleConns(flagMaxConnections)
+
+		listener, err := listen(network, address, flagSocketMode)
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to listen on "+flagListen, err)
+			return utils.ExitError{Code: 1}
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		server := &policy.Server{
+			Policy: &policy.Policy{
+				SenderLogins: func(email utils.EmailAddress) ([]string, error) {
+					return db.PostfixSMTPDSenderLoginMaps(dbConn, email, flagMaxDepth)
+				},
+				RateLimits: func(login string) ([]policy.RateLimit, error) {
+					rateLimits, err := db.RateLimits(dbConn).ListByLogin(login)
+					if err != nil {
+						return nil, err
+					}
+					limits := make([]policy.RateLimit, 0, len(rateLimits))
+					for _, rl := range rateLimits {
+						limits = append(limits, policy.RateLimit{Period: rl.Period, MaxMessages: int(rl.MaxMessages)})
+					}
+					return limits, nil
+				},
+				RecipientDelimiter: flagRecipientDelimiter,
+				Limiter:            policy.NewLimiter(),
+			},
+			OnError: func(req policy.Request, err error) {
+				utils.PrintErrorWithMessage("failed to check policy", err)
+			},
+		}
+
+		utils.PrintSuccess("Serving policy on " + flagListen)
+		if err := server.Serve(ctx, listener); err != nil {
+			utils.PrintErrorWithMessage("failed to serve policy", err)
+			return utils.ExitError{Code: 1}
+		}
+		return nil
+	},
+}
+
+func init() {
+	ServePolicyCmd.Flags().String("listen", "", "Address to listen on (unix:<path> or inet:<host>:<port>)")
+	ServePolicyCmd.Flags().String("socket-mode", "0660", "File mode of the unix socket")
+	ServePolicyCmd.Flags().Int("max-connections", 10, "Maximum number of database connections")
+	ServePolicyCmd.Flags().Uint32("max-depth", 10000, "Maximum recursion depth of alias lookups")
+	ServePolicyCmd.Flags().String("recipient-delimiter", "", "Delimiters of address extensions, which are removed from senders without owner (like recipient_delimiter)")
+	_ = ServePolicyCmd.MarkFlagRequired("listen")
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/socketmap"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var ServeSocketmapCmd = &cobra.Command{
+	Use:   "socketmap --listen <address> [flags]",
+	Short: "Serve the Postfix maps with the socketmap protocol",
+	Long: "Serves a map for each postfix.* function (e.g. transport_maps or virtual_alias_maps) with Postfix's socketmap protocol, so Postfix doesn't need database credentials.\n" +
+		"The address is either unix:<path> or inet:<host>:<port>. Lookups use a pool of database connections and can be cached for --cache-ttl.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagListen, _ := cmd.Flags().GetString("listen")
+		flagSocketMode, _ := cmd.Flags().GetString("socket-mode")
+		flagMaxConnections, _ := cmd.Flags().GetInt("max-connections")
+		flagCacheTTL, _ := cmd.Flags().GetDuration("cache-ttl")
+		flagMaxDepth, _ := cmd.Flags().GetUint32("max-depth")
+
+		network, address, err := parseListenAddress(flagListen)
+		if err != nil {
+			return err
+		}
+		if flagMaxConnections < 1 {
+			return fmt.Errorf("--max-connections must be at least 1")
+		}
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+		dbConn.SetMaxOpenConns(flagMaxConnections)
+		dbConn.SetMaxIdleConns(flagMaxConnections)
+
+		listener, err := listen(network, address, flagSocketMode)
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to listen on "+flagListen, err)
+			return utils.ExitError{Code: 1}
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		server := &socketmap.Server{
+			Maps: socketmap.PostfixMaps(dbConn, flagMaxDepth),
+			OnError: func(name, key string, err error) {
+				utils.PrintErrorWithMessage(fmt.Sprintf("failed to look up %s in %s", key, name), err)
+			},
+		}
+		if flagCacheTTL > 0 {
+			server.Cache = socketmap.NewCache(flagCacheTTL)
+
+			// Remove expired entries, so the cache doesn't grow forever
+			go func() {
+				ticker := time.NewTicker(flagCacheTTL)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						server.Cache.Prune()
+					}
+				}
+			}()
+		}
+
+		utils.PrintSuccess("Serving socketmap on " + flagListen)
+		if err := server.Serve(ctx, listener); err != nil {
+			utils.PrintErrorWithMessage("failed to serve socketmap", err)
+			return utils.ExitError{Code: 1}
+		}
+		return nil
+	},
+}
+
+func init() {
+	ServeSocketmapCmd.Flags().String("listen", "", "Address to listen on (unix:<path> or inet:<host>:<port>)")
+	ServeSocketmapCmd.Flags().String("socket-mode", "0660", "File mode of the unix socket")
+	ServeSocketmapCmd.Flags().Int("max-connections", 10, "Maximum number of database connections")
+	ServeSocketmapCmd.Flags().Duration("cache-ttl", 0, "Time to cache the results of lookups (e.g. 30s, default: no caching)")
+	ServeSocketmapCmd.Flags().Uint32("max-depth", 10000, "Maximum recursion depth of alias lookups")
+	_ = ServeSocketmapCmd.MarkFlagRequired("listen")
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+var SieveCmd = &cobra.Command{
+	Use:   "sieve",
+	Short: "Manage Sieve scripts of mailboxes",
+	Long:  "Manage the named Sieve scripts of mailboxes, of which at most one is active. Dovecot Pigeonhole reads them from the database with its dict storage.",
+}
+
+func init() {
+	// Add subcommands
+	SieveCmd.AddCommand(SieveListCmd)
+	SieveCmd.AddCommand(SieveGetCmd)
+	SieveCmd.AddCommand(SievePutCmd)
+	SieveCmd.AddCommand(SieveActivateCmd)
+	SieveCmd.AddCommand(SieveDeactivateCmd)
+	SieveCmd.AddCommand(SieveDeleteCmd)
+}
+
+// parseSieveScriptNameArg checks a script name, which must not contain "/"
+// or control characters and must not start with "@" (reserved for "@active").
+func parseSieveScriptNameArg(arg string) (string, error) {
+	switch {
+	case arg == "":
+		return "", fmt.Errorf("script name must not be empty")
+	case len(arg) > 256:
+		return "", fmt.Errorf("script name %q exceeds 256 characters", arg)
+	case strings.HasPrefix(arg, "@"):
+		return "", fmt.Errorf("invalid script name %q, must not start with '@'", arg)
+	case strings.ContainsRune(arg, '/') || strings.ContainsFunc(arg, unicode.IsControl):
+		return "", fmt.Errorf("invalid script name %q, must not contain '/' or control characters", arg)
+	}
+	return arg, nil
+}
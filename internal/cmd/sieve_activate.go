@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var SieveActivateCmd = &cobra.Command{
+	Use:   "activate <email> <name>",
+	Short: "Activate a Sieve script",
+	Long:  "Activate a Sieve script of a mailbox. The previously active script is deactivated.",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		email, err := utils.ParseEmailAddress(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid email address %q: %w", args[0], err)
+		}
+
+		name, err := parseSieveScriptNameArg(args[1])
+		if err != nil {
+			return err
+		}
+
+		runner := db.TxForEachRunner[string]{
+			Items: []string{name},
+			Exec: func(tx *sql.Tx, name string) error {
+				return db.MailboxesSieveScripts(tx).Activate(email, name)
+			},
+			ItemString:     func(name string) string { return email.String() + " (" + name + ")" },
+			FailureMessage: "failed to activate Sieve script",
+			SuccessMessage: "Successfully activated Sieve script",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+		}
+
+		return runner.Run()
+	},
+}
+
+var SieveDeactivateCmd = &cobra.Command{
+	Use:   "deactivate <email> [<email>...]",
+	Short: "Deactivate the active Sieve script",
+	Long:  "Deactivate the active Sieve script of mailboxes, so no script is run for them.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		emails := make([]utils.EmailAddress, 0, len(args))
+		for _, arg := range args {
+			email, err := utils.ParseEmailAddress(arg)
+			if err != nil {
+				return fmt.Errorf("invalid email address %q: %w", arg, err)
+			}
+			emails = append(emails, email)
+		}
+
+		runner := db.TxForEachRunner[utils.EmailAddress]{
+			Items: emails,
+			Exec: func(tx *sql.Tx, email utils.EmailAddress) error {
+				return db.MailboxesSieveScripts(tx).Deactivate(email)
+			},
+			ItemString:     func(email utils.EmailAddress) string { return email.String() },
+			FailureMessage: "failed to deactivate Sieve script",
+			SuccessMessage: "Successfully deactivated Sieve script",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	SieveActivateCmd.Flags().Bool("dry-run", false, "Show the affected rows without committing")
+	SieveActivateCmd.Flags().BoolP("json", "j", false, "Output the result in JSON format")
+	SieveDeactivateCmd.Flags().Bool("dry-run", false, "Show the affected rows without committing")
+	SieveDeactivateCmd.Flags().BoolP("json", "j", false, "Output the result of each item in JSON format")
+}
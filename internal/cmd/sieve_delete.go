@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var SieveDeleteCmd = &cobra.Command{
+	Use:   "delete <email> <name> [<name>...]",
+	Short: "Delete Sieve scripts",
+	Long:  "Delete Sieve scripts of a mailbox permanently. They can be restored from the audit log.",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		email, err := utils.ParseEmailAddress(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid email address %q: %w", args[0], err)
+		}
+
+		names := make([]string, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			name, err := parseSieveScriptNameArg(arg)
+			if err != nil {
+				return err
+			}
+			names = append(names, name)
+		}
+
+		runner := db.TxForEachRunner[string]{
+			Items: names,
+			Exec: func(tx *sql.Tx, name string) error {
+				return db.MailboxesSieveScripts(tx).Delete(email, name)
+			},
+			ItemString:     func(name string) string { return email.String() + " (" + name + ")" },
+			FailureMessage: "failed to delete Sieve script",
+			SuccessMessage: "Successfully deleted Sieve script",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	SieveDeleteCmd.Flags().Bool("dry-run", false, "Show the affected rows without committing")
+	SieveDeleteCmd.Flags().BoolP("json", "j", false, "Output the result of each item in JSON format")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var SieveGetCmd = &cobra.Command{
+	Use:   "get <email> [<name>]",
+	Short: "Print a Sieve script",
+	Long:  "Print a Sieve script of a mailbox or, without name, its active script.",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		email, err := utils.ParseEmailAddress(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid email address %q: %w", args[0], err)
+		}
+
+		options := db.MailboxesSieveScriptsListOptions{
+			ByEmail:       &email,
+			OnlyActive:    len(args) < 2,
+			IncludeScript: true,
+		}
+		if len(args) == 2 {
+			name, err := parseSieveScriptNameArg(args[1])
+			if err != nil {
+				return err
+			}
+			options.ByScriptName = &name
+		}
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		scripts, err := db.MailboxesSieveScripts(dbConn).List(options)
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to get Sieve script", err)
+			return utils.ExitError{Code: 1}
+		}
+		if len(scripts) == 0 {
+			if options.ByScriptName != nil {
+				utils.PrintErrorWithMessage(fmt.Sprintf("Sieve script %q of %s not found", *options.ByScriptName, email.String()), nil)
+			} else {
+				utils.PrintErrorWithMessage(email.String()+" has no active Sieve script", nil)
+			}
+			return utils.ExitError{Code: 1}
+		}
+
+		fmt.Print(scripts[0].Script)
+		return nil
+	},
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var SieveListCmd = &cobra.Command{
+	Use:   "list [flags] [<email>...]",
+	Short: "List Sieve scripts",
+	Long:  "List the Sieve scripts of all mailboxes or of the given mailboxes.",
+	Args:  cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagJSON, _ := cmd.Flags().GetBool("json")
+		flagVerbose, _ := cmd.Flags().GetBool("verbose")
+
+		emails := make([]utils.EmailAddress, 0, len(args))
+		for _, arg := range args {
+			email, err := utils.ParseEmailAddress(arg)
+			if err != nil {
+				return fmt.Errorf("invalid email address %q: %w", arg, err)
+			}
+			emails = append(emails, email)
+		}
+
+		dbConn, err := db.Connect()
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to connect to database", err)
+			return utils.ExitError{Code: 1}
+		}
+		defer func() {
+			if err := dbConn.Close(); err != nil {
+				utils.PrintErrorWithMessage("failed to close database connection", err)
+			}
+		}()
+
+		var scripts []db.MailboxSieveScript
+		if len(emails) == 0 {
+			scripts, err = db.MailboxesSieveScripts(dbConn).List(db.MailboxesSieveScriptsListOptions{})
+		} else {
+			for _, email := range emails {
+				var s []db.MailboxSieveScript
+				s, err = db.MailboxesSieveScripts(dbConn).List(db.MailboxesSieveScriptsListOptions{ByEmail: &email})
+				if err != nil {
+					break
+				}
+				scripts = append(scripts, s...)
+			}
+		}
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to list Sieve scripts", err)
+			return utils.ExitError{Code: 1}
+		}
+
+		if flagJSON {
+			out, err := json.Marshal(scripts)
+			if err != nil {
+				utils.PrintErrorWithMessage("Failed to marshal Sieve scripts to JSON", err)
+				return utils.ExitError{Code: 1}
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(scripts) == 0 {
+			fmt.Println("No Sieve scripts found")
+			return nil
+		}
+
+		headers := []string{"Domain", "Name", "Script", "Active", "Size"}
+		if flagVerbose {
+			headers = append(headers, "Created", "Last Updated")
+		}
+
+		t := table.New().
+			Border(lipgloss.RoundedBorder()).
+			BorderStyle(utils.BlackStyle).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				cellStyle := utils.TableRowStyle
+				if row == table.HeaderRow {
+					cellStyle = utils.TableHeaderStyle
+				}
+				switch col {
+				case 3: // Active
+					return cellStyle.Align(lipgloss.Center)
+				case 4: // Size
+					return cellStyle.Align(lipgloss.Right)
+				default:
+					return cellStyle.Align(lipgloss.Left)
+				}
+			}).
+			Headers(headers...)
+
+		for _, s := range scripts {
+			row := []string{
+				s.DomainFQDN,
+				s.Name,
+				s.ScriptName,
+				utils.MaybeEnabledTableStyle.Render(s.Active),
+				strconv.Itoa(s.Size),
+			}
+
+			if flagVerbose {
+				row = append(row,
+					utils.MaybeTimeStyle.Render(s.CreatedAt),
+					utils.MaybeTimeStyle.Render(s.UpdatedAt),
+				)
+			}
+
+			t.Row(row...)
+		}
+
+		fmt.Println(t.Render())
+		return nil
+	},
+}
+
+func init() {
+	SieveListCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	SieveListCmd.Flags().BoolP("verbose", "v", false, "Show created/updated timestamps")
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/sieve"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var SievePutCmd = &cobra.Command{
+	Use:   "put <email> <name> [<file>]",
+	Short: "Store a Sieve script",
+	Long:  "Store a Sieve script of a mailbox read from a file or stdin (without file or '-'). An existing script of the same name is replaced and stays active, if it is. The syntax is validated before the script is stored.",
+	Args:  cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagActivate, _ := cmd.Flags().GetBool("activate")
+		flagDryRun, _ := cmd.Flags().GetBool("dry-run")
+		flagJSON, _ := cmd.Flags().GetBool("json")
+
+		email, err := utils.ParseEmailAddress(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid email address %q: %w", args[0], err)
+		}
+
+		name, err := parseSieveScriptNameArg(args[1])
+		if err != nil {
+			return err
+		}
+
+		var data []byte
+		if len(args) < 3 || args[2] == "-" {
+			data, err = io.ReadAll(io.LimitReader(os.Stdin, sieve.MaxScriptSize+1))
+		} else {
+			data, err = os.ReadFile(args[2])
+		}
+		if err != nil {
+			utils.PrintErrorWithMessage("failed to read script", err)
+			return utils.ExitError{Code: 1}
+		}
+		script := string(data)
+
+		if err := sieve.Validate(script); err != nil {
+			return fmt.Errorf("invalid Sieve script: %w", err)
+		}
+
+		runner := db.TxForEachRunner[string]{
+			Items: []string{name},
+			Exec: func(tx *sql.Tx, name string) error {
+				if err := db.MailboxesSieveScripts(tx).Put(email, name, script); err != nil {
+					return err
+				}
+				if flagActivate {
+					return db.MailboxesSieveScripts(tx).Activate(email, name)
+				}
+				return nil
+			},
+			ItemString:     func(name string) string { return email.String() + " (" + name + ")" },
+			FailureMessage: "failed to store Sieve script",
+			SuccessMessage: "Successfully stored Sieve script",
+			DryRun:         flagDryRun,
+			JSON:           flagJSON,
+		}
+
+		return runner.Run()
+	},
+}
+
+func init() {
+	SievePutCmd.Flags().BoolP("activate", "a", false, "Activate the script")
+	SievePutCmd.Flags().Bool("dry-run", false, "Validate the script and show the affected rows without committing")
+	SievePutCmd.Flags().BoolP("json", "j", false, "Output the result in JSON format")
+}
@@ -244,7 +244,7 @@ func (r *aliasesRepository) Delete(email utils.EmailAddress, options DeleteOptio
 		// Soft delete
 		uq := sq.
 			Update("aliases").
-			Set("deleted_at", sq.Expr("NOW()")).
+			Set("deleted_at", options.deletedAtValue()).
 			Where(sq.Eq{
 				"name": email.LocalPart,
 			}).
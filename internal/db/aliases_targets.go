@@ -297,7 +297,7 @@ func (r *aliasesTargetsRepository) Delete(aliasEmail utils.EmailAddress, targetE
 		// Soft delete
 		uq := sq.
 			Update(targetTable).
-			Set("deleted_at", sq.Expr("NOW()")).
+			Set("deleted_at", options.deletedAtValue()).
 			Where(sq.Eq{
 				"ID": targetId,
 			})
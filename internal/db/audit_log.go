@@ -0,0 +1,307 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+const (
+	AuditLogTable string = "audit.log"
+)
+
+var (
+	// Tables which are tracked by the audit log
+	AuditLogTables = []string{
+		"transports",
+		"remotes",
+		"remotes_send_grants",
+		"domains_managed",
+		"domains_relayed",
+		"domains_alias",
+		"domains_canonical",
+		"domains_catchall_targets",
+		"mailboxes",
+		"aliases",
+		"aliases_targets_recursive",
+		"aliases_targets_foreign",
+		"recipients_relayed",
+		"mailboxes_vacation",
+		"mailboxes_sieve_scripts",
+		"mailboxes_rate_limits",
+		"remotes_rate_limits",
+		"login_lockouts",
+	}
+
+	// Operations which are recorded in the audit log
+	AuditLogOperations = []string{"INSERT", "UPDATE", "DELETE"}
+
+	auditLogDomainTables    = []string{"domains_managed", "domains_relayed", "domains_alias", "domains_canonical"}
+	auditLogRecipientTables = []string{"mailboxes", "aliases", "recipients_relayed"}
+)
+
+type AuditLogEntry struct {
+	ID        int64               `json:"id"`
+	TableName string              `json:"table"`
+	RecordID  int                 `json:"recordId"`
+	Operation string              `json:"operation"`
+	Object    *string             `json:"object"`
+	DataOld   json.RawMessage     `json:"dataOld,omitempty"`
+	DataNew   json.RawMessage     `json:"dataNew,omitempty"`
+	Changes   []utils.FieldChange `json:"changes"`
+	ChangedBy string              `json:"changedBy"`
+	ChangedAt time.Time           `json:"changedAt"`
+	Reason    *string             `json:"reason"`
+}
+
+// AuditLogObject references a mail system object by its natural key. Entries
+// are matched against all names the object ever had, so renames are followed.
+type AuditLogObject struct {
+	Email      *utils.EmailAddress // Mailboxes, aliases and relayed recipients
+	DomainFQDN string              // Domains of any type
+	Name       string              // Transports and remotes
+}
+
+func (o AuditLogObject) String() string {
+	switch {
+	case o.Email != nil:
+		return o.Email.String()
+	case o.DomainFQDN != "":
+		return o.DomainFQDN
+	default:
+		return o.Name
+	}
+}
+
+type AuditLogListOptions struct {
+	ByID               int64
+	FilterTables       []string
+	FilterOperations   []string
+	FilterChangedBy    []string
+	FilterObjects      []AuditLogObject
+	Since              *time.Time
+	Until              *time.Time
+	Limit              uint64 // Only return the most recent entries
+	CurrentTransaction bool   // Only return entries recorded by the current transaction
+}
+
+type AuditLogRepository interface {
+	List(options AuditLogListOptions) ([]AuditLogEntry, error)
+	Revert(ids []int64) error
+	ListPartitions() ([]AuditLogPartition, error)
+	ExportPartition(partition AuditLogPartition, w io.Writer) (int, error)
+	DropPartition(name string) error
+}
+
+type auditLogRepository struct {
+	r sq.BaseRunner
+}
+
+func AuditLog(r sq.BaseRunner) AuditLogRepository {
+	return &auditLogRepository{
+		r: r,
+	}
+}
+
+func (r *auditLogRepository) List(options AuditLogListOptions) ([]AuditLogEntry, error) {
+	q := sq.
+		Select(
+			"l.ID",
+			"l.table_name",
+			"l.record_id",
+			"l.operation",
+			"audit.object_label(l.table_name, COALESCE(l.data_new, l.data_old))",
+			"audit.redact(l.data_old)",
+			"audit.redact(l.data_new)",
+			"COALESCE(l.changed_by, '')",
+			"l.changed_at",
+			"l.reason",
+		).
+		From(AuditLogTable + " l")
+
+	if options.ByID != 0 {
+		q = q.Where(sq.Eq{"l.ID": options.ByID})
+	}
+
+	if len(options.FilterTables) > 0 {
+		q = q.Where(sq.Eq{"l.table_name": options.FilterTables})
+	}
+
+	if len(options.FilterOperations) > 0 {
+		q = q.Where(sq.Eq{"l.operation": options.FilterOperations})
+	}
+
+	if len(options.FilterChangedBy) > 0 {
+		q = q.Where(sq.Eq{"l.changed_by": options.FilterChangedBy})
+	}
+
+	if len(options.FilterObjects) > 0 {
+		or := sq.Or{}
+		for _, object := range options.FilterObjects {
+			or = append(or, auditLogObjectPredicate(object))
+		}
+		q = q.Where(or)
+	}
+
+	if options.Since != nil {
+		q = q.Where(sq.GtOrEq{"l.changed_at": *options.Since})
+	}
+
+	if options.Until != nil {
+		q = q.Where(sq.Lt{"l.changed_at": *options.Until})
+	}
+
+	if options.CurrentTransaction {
+		// The top-level transaction id is also recorded for entries, which are
+		// written in savepoints. Without an id, nothing has been written yet.
+		q = q.Where("l.transaction_id = pg_current_xact_id_if_assigned()")
+	}
+
+	if options.Limit > 0 {
+		// Fetch the most recent entries and restore the chronological order below
+		q = q.OrderBy("l.ID DESC").Limit(options.Limit)
+	} else {
+		q = q.OrderBy("l.ID")
+	}
+
+	rows, err := q.
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r.r).
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []AuditLogEntry
+	for rows.Next() {
+		var e AuditLogEntry
+		var dataOld, dataNew []byte
+		if err := rows.Scan(
+			&e.ID,
+			&e.TableName,
+			&e.RecordID,
+			&e.Operation,
+			&e.Object,
+			&dataOld,
+			&dataNew,
+			&e.ChangedBy,
+			&e.ChangedAt,
+			&e.Reason,
+		); err != nil {
+			return nil, err
+		}
+		if dataOld != nil {
+			e.DataOld = json.RawMessage(dataOld)
+		}
+		if dataNew != nil {
+			e.DataNew = json.RawMessage(dataNew)
+		}
+
+		e.Changes, err = utils.DiffJSONObjects(dataOld, dataNew)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff audit log entry %d: %w", e.ID, err)
+		}
+
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if options.Limit > 0 {
+		slices.Reverse(out)
+	}
+
+	return out, nil
+}
+
+// auditLogObjectPredicate matches all audit log entries of an object and the
+// records it owns (alias targets, catchall targets, send grants, vacation
+// autoresponders and Sieve scripts).
+func auditLogObjectPredicate(object AuditLogObject) sq.Sqlizer {
+	var match sq.Sqlizer
+	switch {
+	case object.Email != nil:
+		domainIDs := sq.Expr(
+			"SELECT dl.record_id FROM "+AuditLogTable+" dl WHERE dl.table_name IN ("+auditLogTableList(auditLogDomainTables)+") AND (dl.data_new->>'fqdn' = ? OR dl.data_old->>'fqdn' = ?)",
+			object.Email.DomainFQDN, object.Email.DomainFQDN,
+		)
+		match = sq.And{
+			sq.Expr("ol.table_name IN (" + auditLogTableList(auditLogRecipientTables) + ")"),
+			sq.Or{
+				sq.And{
+					sq.Expr("ol.data_new->>'name' = ?", object.Email.LocalPart),
+					sq.Expr("(ol.data_new->>'domain_id')::INT IN (?)", domainIDs),
+				},
+				sq.And{
+					sq.Expr("ol.data_old->>'name' = ?", object.Email.LocalPart),
+					sq.Expr("(ol.data_old->>'domain_id')::INT IN (?)", domainIDs),
+				},
+			},
+		}
+	case object.DomainFQDN != "" && object.Name != "":
+		match = sq.Or{
+			sq.And{
+				sq.Expr("ol.table_name IN (" + auditLogTableList(auditLogDomainTables) + ")"),
+				sq.Expr("(ol.data_new->>'fqdn' = ? OR ol.data_old->>'fqdn' = ?)", object.DomainFQDN, object.DomainFQDN),
+			},
+			sq.And{
+				sq.Expr("ol.table_name IN ('transports', 'remotes')"),
+				sq.Expr("(ol.data_new->>'name' = ? OR ol.data_old->>'name' = ?)", object.Name, object.Name),
+			},
+		}
+	case object.DomainFQDN != "":
+		match = sq.And{
+			sq.Expr("ol.table_name IN (" + auditLogTableList(auditLogDomainTables) + ")"),
+			sq.Expr("(ol.data_new->>'fqdn' = ? OR ol.data_old->>'fqdn' = ?)", object.DomainFQDN, object.DomainFQDN),
+		}
+	default:
+		match = sq.And{
+			sq.Expr("ol.table_name IN ('transports', 'remotes')"),
+			sq.Expr("(ol.data_new->>'name' = ? OR ol.data_old->>'name' = ?)", object.Name, object.Name),
+		}
+	}
+
+	objects := func(tables []string) sq.SelectBuilder {
+		return sq.
+			Select("ol.record_id").
+			From(AuditLogTable + " ol").
+			Where(match).
+			Where(sq.Eq{"ol.table_name": tables})
+	}
+
+	return sq.Or{
+		sq.Expr("(l.table_name, l.record_id) IN (?)", sq.Select("ol.table_name", "ol.record_id").From(AuditLogTable+" ol").Where(match)),
+		sq.And{
+			sq.Expr("l.table_name IN ('aliases_targets_recursive', 'aliases_targets_foreign')"),
+			sq.Expr("(COALESCE(l.data_new, l.data_old)->>'alias_id')::INT IN (?)", objects([]string{"aliases"})),
+		},
+		sq.And{
+			sq.Expr("l.table_name = 'domains_catchall_targets'"),
+			sq.Expr("(COALESCE(l.data_new, l.data_old)->>'domain_id')::INT IN (?)", objects(auditLogDomainTables)),
+		},
+		sq.And{
+			sq.Expr("l.table_name IN ('remotes_send_grants', 'remotes_rate_limits')"),
+			sq.Expr("(COALESCE(l.data_new, l.data_old)->>'remote_id')::INT IN (?)", objects([]string{"remotes"})),
+		},
+		sq.And{
+			sq.Expr("l.table_name IN ('mailboxes_vacation', 'mailboxes_sieve_scripts', 'mailboxes_rate_limits')"),
+			sq.Expr("(COALESCE(l.data_new, l.data_old)->>'mailbox_id')::INT IN (?)", objects([]string{"mailboxes"})),
+		},
+	}
+}
+
+func auditLogTableList(tables []string) string {
+	quoted := make([]string, 0, len(tables))
+	for _, table := range tables {
+		quoted = append(quoted, "'"+table+"'")
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// AuditLogPartition is a monthly partition of the audit log.
+type AuditLogPartition struct {
+	Name       string    `json:"name"`
+	RangeStart time.Time `json:"rangeStart"`
+	RangeEnd   time.Time `json:"rangeEnd"`
+}
+
+// ListPartitions returns the partitions of the audit log ordered by time.
+func (r *auditLogRepository) ListPartitions() ([]AuditLogPartition, error) {
+	rows, err := sq.
+		Select("name", "range_start", "range_end").
+		From("audit.log_partitions()").
+		OrderBy("range_start").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r.r).
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []AuditLogPartition
+	for rows.Next() {
+		var p AuditLogPartition
+		if err := rows.Scan(&p.Name, &p.RangeStart, &p.RangeEnd); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ExportPartition writes all entries of a partition as JSON lines (one
+// unredacted entry per line) and returns the number of written entries.
+func (r *auditLogRepository) ExportPartition(partition AuditLogPartition, w io.Writer) (int, error) {
+	rows, err := sq.
+		Select("row_to_json(l)").
+		From(AuditLogTable + " l").
+		Where(sq.GtOrEq{"l.changed_at": partition.RangeStart}).
+		Where(sq.Lt{"l.changed_at": partition.RangeEnd}).
+		OrderBy("l.ID").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r.r).
+		Query()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	bw := bufio.NewWriter(w)
+	count := 0
+	for rows.Next() {
+		var line json.RawMessage
+		if err := rows.Scan(&line); err != nil {
+			return count, err
+		}
+		if _, err := bw.Write(line); err != nil {
+			return count, err
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	return count, bw.Flush()
+}
+
+// DropPartition permanently removes a partition and all its entries.
+func (r *auditLogRepository) DropPartition(name string) error {
+	_, err := sq.
+		Select().
+		Column(sq.Expr("audit.drop_log_partition(?)", name)).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r.r).
+		Exec()
+	return err
+}
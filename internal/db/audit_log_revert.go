@@ -0,0 +1,349 @@
+package db
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrAuditLogEntryNotFound = errors.New("audit log entry not found")
+)
+
+// AuditLogConflictError reports why an audit log entry can't be reverted
+// without overwriting a later change.
+type AuditLogConflictError struct {
+	EntryID int64
+	Reason  string
+}
+
+func (e *AuditLogConflictError) Error() string {
+	return fmt.Sprintf("conflict reverting audit log entry %d: %s", e.EntryID, e.Reason)
+}
+
+type auditLogRevertEntry struct {
+	ID             int64
+	TableName      string
+	RecordID       int
+	Operation      string
+	ChangedColumns []string
+}
+
+type auditLogRecord struct {
+	TableName string
+	RecordID  int
+}
+
+// Revert restores the state recorded before the given audit log entries.
+// Entries are reverted from the most recent to the oldest. The runner has to
+// be a transaction, because every entry is reverted within a savepoint.
+//
+// An entry is refused with an AuditLogConflictError if its record has been
+// changed by a later entry not being reverted as well, or if the current
+// state of the record doesn't match the state recorded by the entry.
+func (r *auditLogRepository) Revert(ids []int64) error {
+	var startID int64
+	err := sq.
+		Select("COALESCE(MAX(ID), 0)").
+		From(AuditLogTable).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r.r).
+		QueryRow().
+		Scan(&startID)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]auditLogRevertEntry, 0, len(ids))
+	records := make(map[auditLogRecord]struct{}, len(ids))
+	for _, id := range ids {
+		entry, err := r.getRevertEntry(id)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		records[auditLogRecord{entry.TableName, entry.RecordID}] = struct{}{}
+	}
+
+	// Revert the most recent changes first
+	slices.SortFunc(entries, func(a, b auditLogRevertEntry) int {
+		return cmp.Compare(b.ID, a.ID)
+	})
+
+	// Refuse if a later change would be overwritten
+	for _, entry := range entries {
+		var laterIDs []int64
+		rows, err := sq.
+			Select("ID").
+			From(AuditLogTable).
+			Where(sq.Eq{
+				"table_name": entry.TableName,
+				"record_id":  entry.RecordID,
+			}).
+			Where(sq.Gt{"ID": entry.ID}).
+			Where(sq.LtOrEq{"ID": startID}).
+			Where(sq.NotEq{"ID": ids}).
+			OrderBy("ID").
+			PlaceholderFormat(sq.Dollar).
+			RunWith(r.r).
+			Query()
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			laterIDs = append(laterIDs, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(laterIDs) > 0 {
+			return &AuditLogConflictError{
+				EntryID: entry.ID,
+				Reason:  fmt.Sprintf("record has been changed later by audit log entries %s", joinIDs(laterIDs)),
+			}
+		}
+	}
+
+	// Revert entries one by one. Changes cascading to other records (like
+	// soft deletes) might require a different order, so entries failing
+	// are retried as long as others succeed.
+	pending := entries
+	for len(pending) > 0 {
+		var failed []auditLogRevertEntry
+		var firstErr error
+		for _, entry := range pending {
+			if _, err := r.r.Exec("SAVEPOINT audit_log_revert"); err != nil {
+				return err
+			}
+
+			if err := r.revertEntry(entry, records); err != nil {
+				if _, rbErr := r.r.Exec("ROLLBACK TO SAVEPOINT audit_log_revert"); rbErr != nil {
+					return errors.Join(err, rbErr)
+				}
+				failed = append(failed, entry)
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+
+			if _, err := r.r.Exec("RELEASE SAVEPOINT audit_log_revert"); err != nil {
+				return err
+			}
+		}
+
+		if len(failed) == len(pending) {
+			return firstErr
+		}
+		pending = failed
+	}
+
+	return nil
+}
+
+func (r *auditLogRepository) getRevertEntry(id int64) (auditLogRevertEntry, error) {
+	entry := auditLogRevertEntry{ID: id}
+	err := sq.
+		Select(
+			"l.table_name",
+			"l.record_id",
+			"l.operation",
+			// Columns changed by an update (updated_at is maintained by a trigger)
+			"COALESCE((SELECT array_agg(n.key ORDER BY n.key) FROM jsonb_each(l.data_new) n WHERE l.data_old->n.key IS DISTINCT FROM n.value AND n.key <> 'updated_at'), '{}')",
+		).
+		From(AuditLogTable+" l").
+		Where(sq.Eq{"l.ID": id}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r.r).
+		QueryRow().
+		Scan(
+			&entry.TableName,
+			&entry.RecordID,
+			&entry.Operation,
+			pq.Array(&entry.ChangedColumns),
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entry, fmt.Errorf("%w: %d", ErrAuditLogEntryNotFound, id)
+		}
+		return entry, err
+	}
+
+	if !slices.Contains(AuditLogTables, entry.TableName) {
+		return entry, fmt.Errorf("audit log entry %d references unsupported table %q", id, entry.TableName)
+	}
+
+	return entry, nil
+}
+
+func (r *auditLogRepository) revertEntry(entry auditLogRevertEntry, records map[auditLogRecord]struct{}) error {
+	table := pq.QuoteIdentifier(entry.TableName)
+
+	var exists bool
+	err := sq.
+		Select().
+		Column(sq.Expr(fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE ID = ?)", table), entry.RecordID)).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r.r).
+		QueryRow().
+		Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	switch entry.Operation {
+	case "INSERT":
+		// The record didn't exist before, so remove it permanently
+		if !exists {
+			return nil // Already removed
+		}
+
+		var beforeID int64
+		err := sq.
+			Select("COALESCE(MAX(ID), 0)").
+			From(AuditLogTable).
+			PlaceholderFormat(sq.Dollar).
+			RunWith(r.r).
+			QueryRow().
+			Scan(&beforeID)
+		if err != nil {
+			return err
+		}
+
+		_, err = r.r.Exec(fmt.Sprintf("DELETE FROM %s WHERE ID = $1", table), entry.RecordID)
+		if err != nil {
+			return err
+		}
+
+		// Refuse if other records, which are not reverted as well, were deleted by cascade
+		rows, err := sq.
+			Select("table_name", "record_id").
+			From(AuditLogTable).
+			Where(sq.Gt{"ID": beforeID}).
+			Where(sq.Eq{"operation": "DELETE"}).
+			PlaceholderFormat(sq.Dollar).
+			RunWith(r.r).
+			Query()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var record auditLogRecord
+			if err := rows.Scan(&record.TableName, &record.RecordID); err != nil {
+				return err
+			}
+			if _, ok := records[record]; !ok {
+				return &AuditLogConflictError{
+					EntryID: entry.ID,
+					Reason:  fmt.Sprintf("record is referenced by %s record %d, which has been created later", record.TableName, record.RecordID),
+				}
+			}
+		}
+		return rows.Err()
+
+	case "DELETE":
+		// The record was deleted permanently, so recreate it from its last state
+		if exists {
+			return &AuditLogConflictError{
+				EntryID: entry.ID,
+				Reason:  "record has been recreated later",
+			}
+		}
+
+		_, err := r.r.Exec(
+			fmt.Sprintf("INSERT INTO %[1]s SELECT * FROM jsonb_populate_record(NULL::%[1]s, (SELECT data_old FROM %[2]s WHERE ID = $1))", table, AuditLogTable),
+			entry.ID,
+		)
+		return err
+
+	case "UPDATE":
+		if len(entry.ChangedColumns) == 0 {
+			return nil // Nothing to revert
+		}
+		if !exists {
+			return &AuditLogConflictError{
+				EntryID: entry.ID,
+				Reason:  "record has been deleted permanently later",
+			}
+		}
+
+		columns := make([]string, 0, len(entry.ChangedColumns))
+		matchesOld := make([]string, 0, len(entry.ChangedColumns))
+		matchesNew := make([]string, 0, len(entry.ChangedColumns))
+		oldColumns := make([]string, 0, len(entry.ChangedColumns))
+		for _, column := range entry.ChangedColumns {
+			c := pq.QuoteIdentifier(column)
+			columns = append(columns, c)
+			matchesOld = append(matchesOld, fmt.Sprintf("t.%[1]s IS NOT DISTINCT FROM o.%[1]s", c))
+			matchesNew = append(matchesNew, fmt.Sprintf("t.%[1]s IS NOT DISTINCT FROM n.%[1]s", c))
+			oldColumns = append(oldColumns, "o."+c)
+		}
+
+		// Compare the current state with the states before and after the change
+		var isOld, isNew bool
+		err := sq.
+			Select(
+				strings.Join(matchesOld, " AND "),
+				strings.Join(matchesNew, " AND "),
+			).
+			From(table+" t").
+			JoinClause(sq.Expr(fmt.Sprintf("CROSS JOIN jsonb_populate_record(NULL::%s, (SELECT data_old FROM %s WHERE ID = ?)) o", table, AuditLogTable), entry.ID)).
+			JoinClause(sq.Expr(fmt.Sprintf("CROSS JOIN jsonb_populate_record(NULL::%s, (SELECT data_new FROM %s WHERE ID = ?)) n", table, AuditLogTable), entry.ID)).
+			Where("t.ID = ?", entry.RecordID).
+			PlaceholderFormat(sq.Dollar).
+			RunWith(r.r).
+			QueryRow().
+			Scan(&isOld, &isNew)
+		if err != nil {
+			return err
+		}
+
+		if isOld {
+			return nil // Already reverted (e.g. by a cascading restore)
+		}
+		if !isNew {
+			return &AuditLogConflictError{
+				EntryID: entry.ID,
+				Reason:  fmt.Sprintf("current state of %s differs from the state after the change", strings.Join(entry.ChangedColumns, ", ")),
+			}
+		}
+
+		_, err = r.r.Exec(
+			fmt.Sprintf(
+				"UPDATE %[1]s SET (%[3]s) = (SELECT %[4]s FROM jsonb_populate_record(NULL::%[1]s, (SELECT data_old FROM %[2]s WHERE ID = $1)) o) WHERE ID = $2",
+				table,
+				AuditLogTable,
+				strings.Join(columns, ", "),
+				strings.Join(oldColumns, ", "),
+			),
+			entry.ID,
+			entry.RecordID,
+		)
+		return err
+
+	default:
+		return fmt.Errorf("audit log entry %d has unsupported operation %q", entry.ID, entry.Operation)
+	}
+}
+
+func joinIDs(ids []int64) string {
+	strs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strs = append(strs, fmt.Sprintf("#%d", id))
+	}
+	return strings.Join(strs, ", ")
+}
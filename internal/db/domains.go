@@ -296,7 +296,7 @@ func (r *domainsRepository) Delete(fqdn string, options DeleteOptions) error {
 	} else {
 		uq := sq.
 			Update(tableName).
-			Set("deleted_at", sq.Expr("NOW()")).
+			Set("deleted_at", options.deletedAtValue()).
 			Where(sq.Eq{
 				"ID": domainId,
 			})
@@ -236,7 +236,7 @@ func (r *domainsCatchallTargetsRepository) Delete(srcDomainFQDN string, targetEm
 	} else {
 		uq := sq.
 			Update("domains_catchall_targets").
-			Set("deleted_at", sq.Expr("NOW()")).
+			Set("deleted_at", options.deletedAtValue()).
 			Where(domainIdExpr).
 			Where(targetIdExpr)
 
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+// transactionEntries lists the rows affected by the transaction so far. The
+// rows are read from the audit log entries recorded by the transaction, so
+// changes made by triggers (e.g. cascaded soft deletes) are included.
+func transactionEntries(tx *sql.Tx) ([]AuditLogEntry, error) {
+	entries, err := AuditLog(tx).List(AuditLogListOptions{CurrentTransaction: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list affected rows: %w", err)
+	}
+	return entries, nil
+}
+
+func printDryRun(itemString string, entries []AuditLogEntry) {
+	fmt.Println(utils.YellowStyle.Bold(true).Render("Dry run, rolled back: ") + itemString)
+	if len(entries) == 0 {
+		fmt.Println(utils.BlackStyle.Render("  <no rows affected>"))
+	}
+	for _, entry := range entries {
+		printAffectedRow(entry)
+	}
+}
+
+func printAffectedRow(entry AuditLogEntry) {
+	object := "#" + strconv.Itoa(entry.RecordID)
+	if entry.Object != nil {
+		object = *entry.Object
+	}
+	row := entry.TableName + " " + object
+
+	switch entry.Operation {
+	case "INSERT":
+		fmt.Println(utils.GreenStyle.Render("  + " + row + " (created)"))
+		return
+	case "DELETE":
+		fmt.Println(utils.RedStyle.Render("  - " + row + " (deleted permanently)"))
+		return
+	}
+
+	var fields []utils.FieldChange
+	for _, change := range entry.Changes {
+		switch {
+		case change.Field == "updated_at":
+			continue
+		case change.Field == "deleted_at" && change.Old == nil:
+			fmt.Println(utils.RedStyle.Render("  - " + row + " (soft-deleted)"))
+			return
+		case change.Field == "deleted_at":
+			fmt.Println(utils.BlueStyle.Render("  ↺ " + row + " (restored)"))
+			return
+		}
+		fields = append(fields, change)
+	}
+
+	fmt.Println(utils.YellowStyle.Render("  ~ " + row))
+	for _, change := range fields {
+		fmt.Printf("      %s: %s → %s\n", change.Field, renderAffectedValue(change.Old), renderAffectedValue(change.New))
+	}
+}
+
+func renderAffectedValue(value any) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%v", value)
+}
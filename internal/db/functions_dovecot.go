@@ -0,0 +1,120 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/lib/pq"
+)
+
+type DovecotPassDBResult struct {
+	Password sql.NullString
+	NoLogin  sql.NullBool
+	Reason   sql.NullString
+}
+
+func DovecotUserDBMailboxes(r sq.BaseRunner, email utils.EmailAddress) (string, error) {
+	var res string
+	err := sq.
+		Select("quota_storage_size").
+		Suffix("FROM dovecot.userdb_mailboxes(?, ?)", email.DomainFQDN, email.LocalPart).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r).
+		QueryRow().
+		Scan(&res)
+	if err != nil {
+		return "", err
+	}
+	return res, nil
+}
+
+func DovecotPassDBMailboxes(r sq.BaseRunner, email utils.EmailAddress) (DovecotPassDBResult, error) {
+	var res DovecotPassDBResult
+	err := sq.
+		Select("password", "nologin", "reason").
+		Suffix("FROM dovecot.passdb_mailboxes(?, ?)", email.DomainFQDN, email.LocalPart).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r).
+		QueryRow().
+		Scan(&res.Password, &res.NoLogin, &res.Reason)
+	if err != nil {
+		return DovecotPassDBResult{}, err
+	}
+	return res, nil
+}
+
+func DovecotPassDBRemotes(r sq.BaseRunner, name string) (DovecotPassDBResult, error) {
+	var res DovecotPassDBResult
+	err := sq.
+		Select("password", "nologin", "reason").
+		Suffix("FROM dovecot.passdb_remotes(?)", name).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r).
+		QueryRow().
+		Scan(&res.Password, &res.NoLogin, &res.Reason)
+	if err != nil {
+		return DovecotPassDBResult{}, err
+	}
+	return res, nil
+}
+
+type DovecotVacationResult struct {
+	Subject         string
+	Body            string
+	StartDate       sql.NullTime
+	EndDate         sql.NullTime
+	ReplyInterval   time.Duration
+	ExcludedSenders []string
+}
+
+func DovecotVacationMailboxes(r sq.BaseRunner, email utils.EmailAddress) (DovecotVacationResult, error) {
+	var res DovecotVacationResult
+	var seconds int64
+	err := sq.
+		Select("subject", "body", "start_date", "end_date", "reply_interval_seconds", "excluded_senders").
+		Suffix("FROM dovecot.vacation_mailboxes(?, ?)", email.DomainFQDN, email.LocalPart).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r).
+		QueryRow().
+		Scan(&res.Subject, &res.Body, &res.StartDate, &res.EndDate, &seconds, pq.Array(&res.ExcludedSenders))
+	if err != nil {
+		return DovecotVacationResult{}, err
+	}
+	res.ReplyInterval = time.Duration(seconds) * time.Second
+	return res, nil
+}
+
+type DovecotSieveScriptResult struct {
+	Name   string
+	DataID string
+	Active bool
+	Script string
+}
+
+func DovecotSieveScripts(r sq.BaseRunner, email utils.EmailAddress) ([]DovecotSieveScriptResult, error) {
+	rows, err := sq.
+		Select("name", "data_id", "active", "script").
+		Suffix("FROM dovecot.sieve_scripts(?, ?)", email.DomainFQDN, email.LocalPart).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r).
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []DovecotSieveScriptResult
+	for rows.Next() {
+		var res DovecotSieveScriptResult
+		if err := rows.Scan(&res.Name, &res.DataID, &res.Active, &res.Script); err != nil {
+			return nil, err
+		}
+		out = append(out, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -161,6 +161,28 @@ func PostfixSMTPDSenderLoginMapsRemotes(r sq.BaseRunner, email utils.EmailAddres
 	return results, nil
 }
 
+func PostfixSMTPDSenderLoginMaps(r sq.BaseRunner, email utils.EmailAddress, limit uint32) ([]string, error) {
+	var results []string
+	rows, err := sq.
+		Select("result").
+		Suffix("FROM postfix.smtpd_sender_login_maps(?, ?, ?)", email.DomainFQDN, email.LocalPart, limit).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r).
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var res string
+		if err := rows.Scan(&res); err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
 func PostfixCanonicalMaps(r sq.BaseRunner, email utils.EmailAddress) (string, error) {
 	var res string
 	err := sq.
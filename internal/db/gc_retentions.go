@@ -0,0 +1,99 @@
+package db
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+var (
+	// Object types which can be garbage collected, in the order they have to
+	// be deleted (objects before the objects they depend on)
+	GCObjectTypes = []string{
+		"alias-targets",
+		"catchall-targets",
+		"send-grants",
+		"recipients-relayed",
+		"aliases",
+		"mailboxes",
+		"domains",
+		"remotes",
+		"transports",
+	}
+)
+
+type GCRetention struct {
+	ObjectType string        `json:"objectType"`
+	Retention  time.Duration `json:"retention"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
+}
+
+type GCRetentionsRepository interface {
+	List() ([]GCRetention, error)
+	Set(objectType string, retention time.Duration) error
+	Delete(objectType string) error
+}
+
+type gcRetentionsRepository struct {
+	r sq.BaseRunner
+}
+
+func GCRetentions(r sq.BaseRunner) GCRetentionsRepository {
+	return &gcRetentionsRepository{
+		r: r,
+	}
+}
+
+func (r *gcRetentionsRepository) List() ([]GCRetention, error) {
+	rows, err := sq.
+		Select(
+			"object_type",
+			"EXTRACT(EPOCH FROM retention)::BIGINT",
+			"created_at",
+			"updated_at",
+		).
+		From("gc_retentions").
+		OrderBy("object_type").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r.r).
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []GCRetention
+	for rows.Next() {
+		var rt GCRetention
+		var seconds int64
+		if err := rows.Scan(&rt.ObjectType, &seconds, &rt.CreatedAt, &rt.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rt.Retention = time.Duration(seconds) * time.Second
+		out = append(out, rt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *gcRetentionsRepository) Set(objectType string, retention time.Duration) error {
+	q := sq.
+		Insert("gc_retentions").
+		Columns("object_type", "retention").
+		Values(objectType, sq.Expr("make_interval(secs => ?)", int64(retention/time.Second))).
+		Suffix("ON CONFLICT (object_type) DO UPDATE SET retention = EXCLUDED.retention")
+
+	return Exec(r.r, q, 1)
+}
+
+func (r *gcRetentionsRepository) Delete(objectType string) error {
+	q := sq.
+		Delete("gc_retentions").
+		Where(sq.Eq{"object_type": objectType})
+
+	return Exec(r.r, q, 1)
+}
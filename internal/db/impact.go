@@ -0,0 +1,72 @@
+package db
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+)
+
+var (
+	ErrNotConfirmed = errors.New("not confirmed")
+)
+
+// ImpactOptions configures the preview of dependent rows, which are changed
+// by triggers together with an object (e.g. cascaded soft deletes or restores).
+type ImpactOptions struct {
+	Action    string // Description of the change, e.g. "Deleting domain"
+	Threshold int    // Ask for confirmation, if more dependent rows are affected
+	Yes       bool   // Don't ask for confirmation
+}
+
+// confirmImpact prints the dependent rows among the rows changed for an item.
+// If there are more than the threshold, an interactive confirmation is
+// required, unless Yes is set. The rows are read from the audit log entries
+// recorded by the transaction, so they match exactly what will be committed.
+// With quiet, nothing is printed and no confirmation is asked for.
+func confirmImpact(entries []AuditLogEntry, itemString string, options ImpactOptions, quiet bool) error {
+	// Entries labeled like the object itself aren't dependents
+	var dependents []AuditLogEntry
+	for _, entry := range entries {
+		if entry.Object != nil && *entry.Object == itemString {
+			continue
+		}
+		dependents = append(dependents, entry)
+	}
+
+	if len(dependents) == 0 {
+		return nil
+	}
+
+	if !quiet {
+		fmt.Printf("%s %s also affects %d dependent objects:\n", options.Action, itemString, len(dependents))
+		for _, entry := range dependents {
+			printAffectedRow(entry)
+		}
+	}
+
+	if options.Yes || len(dependents) <= options.Threshold {
+		return nil
+	}
+
+	if quiet || !term.IsTerminal(os.Stdin.Fd()) {
+		return fmt.Errorf("%w: %d dependent objects affected, use --yes to proceed", ErrNotConfirmed, len(dependents))
+	}
+
+	fmt.Print("Proceed? [y/N] ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return ErrNotConfirmed
+	}
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+var (
+	// Object types which can be locked after failed login attempts
+	LoginLockoutObjectTypes = []string{"mailbox", "remote"}
+)
+
+type LoginLockout struct {
+	ObjectType  string        `json:"objectType"`
+	MaxFailures int32         `json:"maxFailures"`
+	Period      time.Duration `json:"period"`
+	CreatedAt   time.Time     `json:"createdAt"`
+	UpdatedAt   time.Time     `json:"updatedAt"`
+}
+
+type LoginLockoutsRepository interface {
+	List() ([]LoginLockout, error)
+	Set(objectType string, maxFailures int32, period time.Duration) error
+	Delete(objectType string) error
+}
+
+type loginLockoutsRepository struct {
+	r sq.BaseRunner
+}
+
+func LoginLockouts(r sq.BaseRunner) LoginLockoutsRepository {
+	return &loginLockoutsRepository{
+		r: r,
+	}
+}
+
+func (r *loginLockoutsRepository) List() ([]LoginLockout, error) {
+	rows, err := sq.
+		Select(
+			"object_type",
+			"max_failures",
+			"EXTRACT(EPOCH FROM period)::BIGINT",
+			"created_at",
+			"updated_at",
+		).
+		From("login_lockouts").
+		OrderBy("object_type").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r.r).
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []LoginLockout
+	for rows.Next() {
+		var ll LoginLockout
+		var seconds int64
+		if err := rows.Scan(&ll.ObjectType, &ll.MaxFailures, &seconds, &ll.CreatedAt, &ll.UpdatedAt); err != nil {
+			return nil, err
+		}
+		ll.Period = time.Duration(seconds) * time.Second
+		out = append(out, ll)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *loginLockoutsRepository) Set(objectType string, maxFailures int32, period time.Duration) error {
+	q := sq.
+		Insert("login_lockouts").
+		Columns("object_type", "max_failures", "period").
+		Values(objectType, maxFailures, sq.Expr("make_interval(secs => ?)", int64(period/time.Second))).
+		Suffix("ON CONFLICT (object_type) DO UPDATE SET max_failures = EXCLUDED.max_failures, period = EXCLUDED.period")
+
+	return Exec(r.r, q, 1)
+}
+
+func (r *loginLockoutsRepository) Delete(objectType string) error {
+	q := sq.
+		Delete("login_lockouts").
+		Where(sq.Eq{"object_type": objectType})
+
+	return Exec(r.r, q, 1)
+}
@@ -7,8 +7,7 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/gerolf-vent/mailctl/internal/utils"
-	"github.com/gerolf-vent/mailctl/internal/utils/crypto/argon2"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/gerolf-vent/mailctl/internal/utils/crypto/passwordhash"
 )
 
 type Mailbox struct {
@@ -19,6 +18,7 @@ type Mailbox struct {
 	ReceivingEnabled bool       `json:"receivingEnabled"`
 	SendingEnabled   bool       `json:"sendingEnabled"`
 	PasswordSet      bool       `json:"passwordHashSet"`
+	PasswordHash     *string    `json:"passwordHash,omitempty"` // Only with IncludePasswordHash
 	StorageQuota     *int32     `json:"storageQuota,omitempty"`
 	Transport        *string    `json:"transport,omitempty"`
 	TransportName    *string    `json:"transportName,omitempty"`
@@ -46,10 +46,11 @@ type MailboxesPatchOptions struct {
 }
 
 type MailboxesListOptions struct {
-	FilterDomains  []string
-	ByEmail        *utils.EmailAddress
-	IncludeDeleted bool
-	IncludeAll     bool
+	FilterDomains       []string
+	ByEmail             *utils.EmailAddress
+	IncludeDeleted      bool
+	IncludeAll          bool
+	IncludePasswordHash bool
 }
 
 type MailboxesRepository interface {
@@ -73,6 +74,11 @@ func Mailboxes(r sq.BaseRunner) MailboxesRepository {
 }
 
 func (r *mailboxesRepository) List(options MailboxesListOptions) ([]Mailbox, error) {
+	passwordHashColumn := "NULL"
+	if options.IncludePasswordHash {
+		passwordHashColumn = "m.password_hash"
+	}
+
 	q := sq.
 		Select(
 			"d.fqdn",
@@ -82,6 +88,7 @@ func (r *mailboxesRepository) List(options MailboxesListOptions) ([]Mailbox, err
 			"m.receiving_enabled",
 			"m.sending_enabled",
 			"m.password_hash IS NOT NULL AS auth_data_hash_set",
+			passwordHashColumn+" AS password_hash",
 			"m.storage_quota",
 			"CASE WHEN t.ID IS NOT NULL THEN postfix.transport_string(t.method, t.host, t.port, t.mx_lookup) ELSE NULL END AS transport",
 			"t.name AS transport_name",
@@ -129,6 +136,7 @@ func (r *mailboxesRepository) List(options MailboxesListOptions) ([]Mailbox, err
 	var out []Mailbox
 	for rows.Next() {
 		var m Mailbox
+		var passwordHash sql.NullString
 		var storageQuota sql.NullInt32
 		var transport sql.NullString
 		var transportName sql.NullString
@@ -141,6 +149,7 @@ func (r *mailboxesRepository) List(options MailboxesListOptions) ([]Mailbox, err
 			&m.ReceivingEnabled,
 			&m.SendingEnabled,
 			&m.PasswordSet,
+			&passwordHash,
 			&storageQuota,
 			&transport,
 			&transportName,
@@ -151,6 +160,9 @@ func (r *mailboxesRepository) List(options MailboxesListOptions) ([]Mailbox, err
 			return nil, err
 		}
 
+		if passwordHash.Valid {
+			m.PasswordHash = &passwordHash.String
+		}
 		if storageQuota.Valid {
 			m.StorageQuota = &storageQuota.Int32
 		}
@@ -180,11 +192,13 @@ func (r *mailboxesRepository) Authenticate(email utils.EmailAddress, givenPasswo
 			Select("ID").
 			From("domains_managed").
 			Where(sq.Eq{
-				"fqdn": email.DomainFQDN,
+				"fqdn":       email.DomainFQDN,
+				"deleted_at": nil,
 			}),
 		).
 		Where(sq.Eq{
-			"name": email.LocalPart,
+			"name":       email.LocalPart,
+			"deleted_at": nil,
 		}).
 		PlaceholderFormat(sq.Dollar).
 		RunWith(r.r).
@@ -201,21 +215,7 @@ func (r *mailboxesRepository) Authenticate(email utils.EmailAddress, givenPasswo
 		return false, nil
 	}
 
-	if passwordHash.String[:10] == "$argon2id$" {
-		err = argon2.CompareHashAndPassword([]byte(passwordHash.String), []byte(givenPassword))
-		if errors.Is(err, argon2.ErrMismatchedHashAndPassword) {
-			return false, nil
-		}
-		return err == nil, err
-	} else if passwordHash.String[:2] == "$2" {
-		err = bcrypt.CompareHashAndPassword([]byte(passwordHash.String), []byte(givenPassword))
-		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-			return false, nil
-		}
-		return err == nil, err
-	}
-
-	return false, errors.New("unsupported password hash type")
+	return passwordhash.Compare(passwordHash.String, givenPassword)
 }
 
 func (r *mailboxesRepository) Create(address utils.EmailAddress, options MailboxesCreateOptions) (err error) {
@@ -359,7 +359,7 @@ func (r *mailboxesRepository) Delete(email utils.EmailAddress, options DeleteOpt
 		// Soft delete
 		uq := sq.
 			Update("mailboxes").
-			Set("deleted_at", sq.Expr("NOW()")).
+			Set("deleted_at", options.deletedAtValue()).
 			Where(sq.Eq{"name": email.LocalPart}).
 			Where(sq.Expr("domain_id = (?)", sq.Select("ID").From("domains_managed").Where(sq.Eq{"fqdn": email.DomainFQDN}).Limit(1)))
 
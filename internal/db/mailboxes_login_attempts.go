@@ -14,22 +14,37 @@ const (
 )
 
 type MailboxLoginAttempt struct {
-	DomainFQDN    string    `json:"domainFQDN"`
-	Name          string    `json:"name"`
-	Succeeded     bool      `json:"succeeded"`
-	FailureReason string    `json:"failureReason"`
-	AttemptedAt   time.Time `json:"attemptedAt"`
+	DomainFQDN    string     `json:"domainFQDN"`
+	Name          string     `json:"name"`
+	Succeeded     bool       `json:"succeeded"`
+	FailureReason string     `json:"failureReason"`
+	RemoteIP      *string    `json:"remoteIP,omitempty"`
+	Protocol      string     `json:"protocol"`
+	Service       string     `json:"service"`
+	UnlockedAt    *time.Time `json:"unlockedAt,omitempty"`
+	AttemptedAt   time.Time  `json:"attemptedAt"`
 }
 
 type MailboxesLoginAttemptsListOptions struct {
 	FilterDomains []string
 	FilterEmails  []*utils.EmailAddress
+	OnlyFailed    bool
+	Since         *time.Time
+	Limit         uint64 // Only the most recent attempts
+}
+
+// LoginAttemptsRecordOptions describes where a login attempt came from.
+type LoginAttemptsRecordOptions struct {
+	RemoteIP string // Optional
+	Protocol string // e.g. imap, pop3 or smtp
+	Service  string // Which recorded the attempt, e.g. dovecot
 }
 
 type MailboxesLoginAttemptsRepository interface {
 	List(options MailboxesLoginAttemptsListOptions) ([]MailboxLoginAttempt, error)
 	CheckRateLimit(email utils.EmailAddress, count uint32, interval time.Duration) (ok bool, err error)
-	Record(email utils.EmailAddress, succeeded bool, failureReason string) (err error)
+	Record(email utils.EmailAddress, succeeded bool, failureReason string, options LoginAttemptsRecordOptions) (err error)
+	Unlock(email utils.EmailAddress) (err error)
 }
 
 type mailboxesLoginAttemptsRepository struct {
@@ -43,15 +58,49 @@ func MailboxesLoginAttempts(r sq.BaseRunner) MailboxesLoginAttemptsRepository {
 }
 
 func (r *mailboxesLoginAttemptsRepository) List(options MailboxesLoginAttemptsListOptions) ([]MailboxLoginAttempt, error) {
-	rows, err := sq.
+	q := sq.
 		Select(
 			"domain_fqdn",
 			"name",
 			"succeeded",
 			"failure_reason",
+			"HOST(remote_ip)",
+			"protocol",
+			"service",
+			"unlocked_at",
 			"attempted_at",
 		).
-		From(MailboxesLoginAttemptsTable).
+		From(MailboxesLoginAttemptsTable)
+
+	if len(options.FilterDomains) > 0 {
+		q = q.Where(sq.Eq{"domain_fqdn": options.FilterDomains})
+	}
+
+	if len(options.FilterEmails) > 0 {
+		or := sq.Or{}
+		for _, email := range options.FilterEmails {
+			or = append(or, sq.Eq{
+				"domain_fqdn": email.DomainFQDN,
+				"name":        email.LocalPart,
+			})
+		}
+		q = q.Where(or)
+	}
+
+	if options.OnlyFailed {
+		q = q.Where(sq.Eq{"succeeded": false})
+	}
+
+	if options.Since != nil {
+		q = q.Where(sq.GtOrEq{"attempted_at": *options.Since})
+	}
+
+	q = q.OrderBy("attempted_at DESC")
+	if options.Limit > 0 {
+		q = q.Limit(options.Limit)
+	}
+
+	rows, err := q.
 		PlaceholderFormat(sq.Dollar).
 		RunWith(r.r).
 		Query()
@@ -63,33 +112,48 @@ func (r *mailboxesLoginAttemptsRepository) List(options MailboxesLoginAttemptsLi
 	var out []MailboxLoginAttempt
 	for rows.Next() {
 		var mla MailboxLoginAttempt
+		var remoteIP sql.NullString
+		var unlockedAt sql.NullTime
 		if err := rows.Scan(
 			&mla.DomainFQDN,
 			&mla.Name,
 			&mla.Succeeded,
 			&mla.FailureReason,
+			&remoteIP,
+			&mla.Protocol,
+			&mla.Service,
+			&unlockedAt,
 			&mla.AttemptedAt,
 		); err != nil {
 			return nil, err
 		}
+		if remoteIP.Valid {
+			mla.RemoteIP = &remoteIP.String
+		}
+		if unlockedAt.Valid {
+			mla.UnlockedAt = &unlockedAt.Time
+		}
 
 		out = append(out, mla)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	return out, nil
 }
 
+// CheckRateLimit checks whether a mailbox has less than count failed login
+// attempts within interval. Like the login lockout, it only counts failed
+// attempts since the last successful one, which haven't been unlocked. The
+// attempts are counted by dovecot.login_failures_mailboxes, so the dovecot
+// user can check them as well.
 func (r *mailboxesLoginAttemptsRepository) CheckRateLimit(email utils.EmailAddress, count uint32, interval time.Duration) (ok bool, err error) {
 	var attempts uint32
 
 	err = sq.
-		Select("COUNT(*)").
-		From(MailboxesLoginAttemptsTable).
-		Where(sq.Eq{
-			"domain_fqdn": email.DomainFQDN,
-			"name":        email.LocalPart,
-		}).
-		Where("attempted_at > ?", time.Now().Add(-1*interval)).
+		Select("failures").
+		Suffix("FROM dovecot.login_failures_mailboxes(?, ?, make_interval(secs => ?)) AS failures", email.DomainFQDN, email.LocalPart, int64(interval/time.Second)).
 		PlaceholderFormat(sq.Dollar).
 		RunWith(r.r).
 		QueryRow().
@@ -104,11 +168,47 @@ func (r *mailboxesLoginAttemptsRepository) CheckRateLimit(email utils.EmailAddre
 	return attempts < count, nil
 }
 
-func (r *mailboxesLoginAttemptsRepository) Record(email utils.EmailAddress, succeeded bool, failureReason string) (err error) {
+func (r *mailboxesLoginAttemptsRepository) Record(email utils.EmailAddress, succeeded bool, failureReason string, options LoginAttemptsRecordOptions) (err error) {
 	q := sq.
 		Insert(MailboxesLoginAttemptsTable).
-		Columns("domain_fqdn", "name", "succeeded", "failure_reason").
-		Values(email.DomainFQDN, email.LocalPart, succeeded, failureReason)
+		Columns("domain_fqdn", "name", "succeeded", "failure_reason", "remote_ip", "protocol", "service").
+		Values(email.DomainFQDN, email.LocalPart, succeeded, failureReason, loginAttemptRemoteIP(options.RemoteIP), options.Protocol, options.Service)
 
 	return Exec(r.r, q, 1)
 }
+
+// Unlock marks the failed login attempts of a mailbox as unlocked, so they
+// don't count towards the login lockout and the rate limit anymore. The time of the unlock is
+// set on the mailbox as well, so it is recorded in the audit log.
+func (r *mailboxesLoginAttemptsRepository) Unlock(email utils.EmailAddress) (err error) {
+	unlock := sq.
+		Update("mailboxes").
+		Set("logins_unlocked_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Expr("ID = (?)", mailboxIDQuery(email)))
+
+	if err := Exec(r.r, unlock, 1); err != nil {
+		return err
+	}
+
+	q := sq.
+		Update(MailboxesLoginAttemptsTable).
+		Set("unlocked_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{
+			"domain_fqdn": email.DomainFQDN,
+			"name":        email.LocalPart,
+			"succeeded":   false,
+			"unlocked_at": nil,
+		})
+
+	_, err = q.PlaceholderFormat(sq.Dollar).RunWith(r.r).Exec()
+	return
+}
+
+// loginAttemptRemoteIP returns the remote IP of a login attempt, which is
+// NULL if unknown.
+func loginAttemptRemoteIP(remoteIP string) any {
+	if remoteIP == "" {
+		return nil
+	}
+	return remoteIP
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+// MailboxSieveScript is a named Sieve script of a mailbox, of which at most
+// one is active.
+type MailboxSieveScript struct {
+	DomainFQDN string    `json:"domainFQDN"`
+	Name       string    `json:"name"` // Name of the mailbox
+	ScriptName string    `json:"scriptName"`
+	Active     bool      `json:"active"`
+	Size       int       `json:"size"` // In bytes
+	Script     string    `json:"script,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type MailboxesSieveScriptsListOptions struct {
+	ByEmail       *utils.EmailAddress
+	ByScriptName  *string
+	OnlyActive    bool
+	IncludeScript bool
+}
+
+type MailboxesSieveScriptsRepository interface {
+	List(options MailboxesSieveScriptsListOptions) ([]MailboxSieveScript, error)
+	Put(email utils.EmailAddress, name string, script string) error
+	Activate(email utils.EmailAddress, name string) error
+	Deactivate(email utils.EmailAddress) error
+	Delete(email utils.EmailAddress, name string) error
+}
+
+type mailboxesSieveScriptsRepository struct {
+	r sq.BaseRunner
+}
+
+func MailboxesSieveScripts(r sq.BaseRunner) MailboxesSieveScriptsRepository {
+	return &mailboxesSieveScriptsRepository{
+		r: r,
+	}
+}
+
+func (r *mailboxesSieveScriptsRepository) List(options MailboxesSieveScriptsListOptions) ([]MailboxSieveScript, error) {
+	script := "''"
+	if options.IncludeScript {
+		script = "s.script"
+	}
+
+	q := sq.
+		Select(
+			"dm.fqdn",
+			"m.name",
+			"s.name",
+			"s.active",
+			"octet_length(s.script)",
+			script,
+			"s.created_at",
+			"s.updated_at",
+		).
+		From("mailboxes_sieve_scripts s").
+		Join("mailboxes m ON m.ID = s.mailbox_id").
+		Join("domains_managed dm ON dm.ID = m.domain_id").
+		Where(sq.Eq{
+			"m.deleted_at":  nil,
+			"dm.deleted_at": nil,
+		}).
+		OrderBy("dm.fqdn", "m.name", "s.name")
+
+	if options.ByEmail != nil {
+		q = q.Where(sq.Eq{
+			"dm.fqdn": options.ByEmail.DomainFQDN,
+			"m.name":  options.ByEmail.LocalPart,
+		})
+	}
+
+	if options.ByScriptName != nil {
+		q = q.Where(sq.Eq{"s.name": *options.ByScriptName})
+	}
+
+	if options.OnlyActive {
+		q = q.Where(sq.Eq{"s.active": true})
+	}
+
+	rows, err := q.PlaceholderFormat(sq.Dollar).RunWith(r.r).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []MailboxSieveScript
+	for rows.Next() {
+		var s MailboxSieveScript
+		if err := rows.Scan(
+			&s.DomainFQDN,
+			&s.Name,
+			&s.ScriptName,
+			&s.Active,
+			&s.Size,
+			&s.Script,
+			&s.CreatedAt,
+			&s.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// Put creates the script or replaces the script of the same name, which
+// stays active, if it is.
+func (r *mailboxesSieveScriptsRepository) Put(email utils.EmailAddress, name string, script string) error {
+	q := sq.
+		Insert("mailboxes_sieve_scripts").
+		Columns("mailbox_id", "name", "script").
+		Values(
+			sq.Expr("(?)", mailboxIDQuery(email)),
+			name,
+			script,
+		).
+		Suffix("ON CONFLICT (mailbox_id, name) DO UPDATE SET script = EXCLUDED.script")
+
+	return Exec(r.r, q, 1)
+}
+
+// Activate activates the script and deactivates the previously active one.
+func (r *mailboxesSieveScriptsRepository) Activate(email utils.EmailAddress, name string) error {
+	deactivate := sq.
+		Update("mailboxes_sieve_scripts").
+		Set("active", false).
+		Where(sq.Expr("mailbox_id = (?)", mailboxIDQuery(email))).
+		Where(sq.Eq{"active": true}).
+		Where(sq.NotEq{"name": name})
+
+	if _, err := deactivate.PlaceholderFormat(sq.Dollar).RunWith(r.r).Exec(); err != nil {
+		return err
+	}
+
+	q := sq.
+		Update("mailboxes_sieve_scripts").
+		Set("active", true).
+		Where(sq.Expr("mailbox_id = (?)", mailboxIDQuery(email))).
+		Where(sq.Eq{"name": name})
+
+	return Exec(r.r, q, 1)
+}
+
+// Deactivate deactivates the active script, so no script is run.
+func (r *mailboxesSieveScriptsRepository) Deactivate(email utils.EmailAddress) error {
+	q := sq.
+		Update("mailboxes_sieve_scripts").
+		Set("active", false).
+		Where(sq.Expr("mailbox_id = (?)", mailboxIDQuery(email))).
+		Where(sq.Eq{"active": true})
+
+	return Exec(r.r, q, 1)
+}
+
+func (r *mailboxesSieveScriptsRepository) Delete(email utils.EmailAddress, name string) error {
+	q := sq.
+		Delete("mailboxes_sieve_scripts").
+		Where(sq.Expr("mailbox_id = (?)", mailboxIDQuery(email))).
+		Where(sq.Eq{"name": name})
+
+	return Exec(r.r, q, 1)
+}
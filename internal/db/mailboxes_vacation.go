@@ -0,0 +1,237 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/lib/pq"
+)
+
+// MailboxVacation is the vacation autoresponder of a mailbox. The dates are
+// inclusive and open, if they are nil.
+type MailboxVacation struct {
+	DomainFQDN      string        `json:"domainFQDN"`
+	Name            string        `json:"name"`
+	Enabled         bool          `json:"enabled"`
+	Subject         string        `json:"subject"`
+	Body            string        `json:"body"`
+	StartDate       *time.Time    `json:"startDate,omitempty"`
+	EndDate         *time.Time    `json:"endDate,omitempty"`
+	ReplyInterval   time.Duration `json:"replyInterval"`
+	ExcludedSenders []string      `json:"excludedSenders"`
+	CreatedAt       time.Time     `json:"createdAt"`
+	UpdatedAt       time.Time     `json:"updatedAt"`
+}
+
+type MailboxesVacationCreateOptions struct {
+	Enabled         bool
+	Subject         string
+	Body            string
+	StartDate       sql.NullTime
+	EndDate         sql.NullTime
+	ReplyInterval   *time.Duration // Default: 7 days
+	ExcludedSenders []string
+}
+
+type MailboxesVacationPatchOptions struct {
+	Enabled         *bool
+	Subject         *string
+	Body            *string
+	StartDate       *sql.NullTime
+	EndDate         *sql.NullTime
+	ReplyInterval   *time.Duration
+	ExcludedSenders *[]string
+}
+
+type MailboxesVacationListOptions struct {
+	FilterDomains []string
+	ByEmail       *utils.EmailAddress
+	OnlyEnabled   bool
+}
+
+type MailboxesVacationRepository interface {
+	List(options MailboxesVacationListOptions) ([]MailboxVacation, error)
+	Create(email utils.EmailAddress, options MailboxesVacationCreateOptions) error
+	Patch(email utils.EmailAddress, options MailboxesVacationPatchOptions) error
+	Delete(email utils.EmailAddress) error
+}
+
+type mailboxesVacationRepository struct {
+	r sq.BaseRunner
+}
+
+func MailboxesVacation(r sq.BaseRunner) MailboxesVacationRepository {
+	return &mailboxesVacationRepository{
+		r: r,
+	}
+}
+
+func (r *mailboxesVacationRepository) List(options MailboxesVacationListOptions) ([]MailboxVacation, error) {
+	q := sq.
+		Select(
+			"dm.fqdn",
+			"m.name",
+			"v.enabled",
+			"v.subject",
+			"v.body",
+			"v.start_date",
+			"v.end_date",
+			"EXTRACT(EPOCH FROM v.reply_interval)::BIGINT",
+			"v.excluded_senders",
+			"v.created_at",
+			"v.updated_at",
+		).
+		From("mailboxes_vacation v").
+		Join("mailboxes m ON m.ID = v.mailbox_id").
+		Join("domains_managed dm ON dm.ID = m.domain_id").
+		Where(sq.Eq{
+			"m.deleted_at":  nil,
+			"dm.deleted_at": nil,
+		}).
+		OrderBy("dm.fqdn", "m.name")
+
+	if len(options.FilterDomains) > 0 {
+		q = q.Where(sq.Eq{"dm.fqdn": options.FilterDomains})
+	}
+
+	if options.ByEmail != nil {
+		q = q.Where(sq.Eq{
+			"dm.fqdn": options.ByEmail.DomainFQDN,
+			"m.name":  options.ByEmail.LocalPart,
+		})
+	}
+
+	if options.OnlyEnabled {
+		q = q.Where(sq.Eq{"v.enabled": true})
+	}
+
+	rows, err := q.PlaceholderFormat(sq.Dollar).RunWith(r.r).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []MailboxVacation
+	for rows.Next() {
+		var v MailboxVacation
+		var startDate, endDate sql.NullTime
+		var seconds int64
+		if err := rows.Scan(
+			&v.DomainFQDN,
+			&v.Name,
+			&v.Enabled,
+			&v.Subject,
+			&v.Body,
+			&startDate,
+			&endDate,
+			&seconds,
+			pq.Array(&v.ExcludedSenders),
+			&v.CreatedAt,
+			&v.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if startDate.Valid {
+			t := startDate.Time
+			v.StartDate = &t
+		}
+		if endDate.Valid {
+			t := endDate.Time
+			v.EndDate = &t
+		}
+		v.ReplyInterval = time.Duration(seconds) * time.Second
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *mailboxesVacationRepository) Create(email utils.EmailAddress, options MailboxesVacationCreateOptions) error {
+	columns := []string{"mailbox_id", "enabled", "subject", "body", "start_date", "end_date", "excluded_senders"}
+	values := []any{
+		sq.Expr("(?)", mailboxIDQuery(email)),
+		options.Enabled,
+		options.Subject,
+		options.Body,
+		options.StartDate,
+		options.EndDate,
+		pq.Array(nonNilStrings(options.ExcludedSenders)),
+	}
+	if options.ReplyInterval != nil {
+		columns = append(columns, "reply_interval")
+		values = append(values, sq.Expr("make_interval(secs => ?)", int64(*options.ReplyInterval/time.Second)))
+	}
+
+	q := sq.
+		Insert("mailboxes_vacation").
+		Columns(columns...).
+		Values(values...)
+
+	return Exec(r.r, q, 1)
+}
+
+func (r *mailboxesVacationRepository) Patch(email utils.EmailAddress, options MailboxesVacationPatchOptions) error {
+	q := sq.
+		Update("mailboxes_vacation").
+		Where(sq.Expr("mailbox_id = (?)", mailboxIDQuery(email)))
+
+	if options.Enabled != nil {
+		q = q.Set("enabled", *options.Enabled)
+	}
+	if options.Subject != nil {
+		q = q.Set("subject", *options.Subject)
+	}
+	if options.Body != nil {
+		q = q.Set("body", *options.Body)
+	}
+	if options.StartDate != nil {
+		q = q.Set("start_date", *options.StartDate)
+	}
+	if options.EndDate != nil {
+		q = q.Set("end_date", *options.EndDate)
+	}
+	if options.ReplyInterval != nil {
+		q = q.Set("reply_interval", sq.Expr("make_interval(secs => ?)", int64(*options.ReplyInterval/time.Second)))
+	}
+	if options.ExcludedSenders != nil {
+		q = q.Set("excluded_senders", pq.Array(nonNilStrings(*options.ExcludedSenders)))
+	}
+
+	return Exec(r.r, q, 1)
+}
+
+func (r *mailboxesVacationRepository) Delete(email utils.EmailAddress) error {
+	q := sq.
+		Delete("mailboxes_vacation").
+		Where(sq.Expr("mailbox_id = (?)", mailboxIDQuery(email)))
+
+	return Exec(r.r, q, 1)
+}
+
+// mailboxIDQuery selects the ID of a mailbox, which isn't deleted.
+func mailboxIDQuery(email utils.EmailAddress) sq.SelectBuilder {
+	return sq.
+		Select("m.ID").
+		From("mailboxes m").
+		Join("domains_managed dm ON dm.ID = m.domain_id").
+		Where(sq.Eq{
+			"m.name":        email.LocalPart,
+			"dm.fqdn":       email.DomainFQDN,
+			"m.deleted_at":  nil,
+			"dm.deleted_at": nil,
+		})
+}
+
+// nonNilStrings returns an empty slice for nil, which pq.Array would store
+// as NULL.
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -1,6 +1,21 @@
 package db
 
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
 type DeleteOptions struct {
 	Force     bool
 	Permanent bool
+	DeletedAt *time.Time // Time of a soft delete (default: now)
+}
+
+// deletedAtValue returns the value deleted_at is set to by a soft delete.
+func (o DeleteOptions) deletedAtValue() any {
+	if o.DeletedAt != nil {
+		return *o.DeletedAt
+	}
+	return sq.Expr("NOW()")
 }
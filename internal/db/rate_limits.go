@@ -0,0 +1,209 @@
+package db
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+var (
+	// Object types which can have rate limits
+	RateLimitObjectTypes = []string{"mailbox", "remote"}
+)
+
+type RateLimit struct {
+	ObjectType  string        `json:"objectType"`
+	Name        string        `json:"name"` // Email of the mailbox or name of the remote
+	Period      time.Duration `json:"period"`
+	MaxMessages int32         `json:"maxMessages"`
+	CreatedAt   time.Time     `json:"createdAt"`
+	UpdatedAt   time.Time     `json:"updatedAt"`
+}
+
+type RateLimitsRepository interface {
+	List() ([]RateLimit, error)
+	ListByLogin(login string) ([]RateLimit, error)
+	SetMailbox(email utils.EmailAddress, period time.Duration, maxMessages int32) error
+	SetRemote(name string, period time.Duration, maxMessages int32) error
+	DeleteMailbox(email utils.EmailAddress, period *time.Duration) error
+	DeleteRemote(name string, period *time.Duration) error
+}
+
+type rateLimitsRepository struct {
+	r sq.BaseRunner
+}
+
+func RateLimits(r sq.BaseRunner) RateLimitsRepository {
+	return &rateLimitsRepository{
+		r: r,
+	}
+}
+
+func (r *rateLimitsRepository) List() ([]RateLimit, error) {
+	return r.list(sq.And{}, sq.And{})
+}
+
+// ListByLogin lists the rate limits of the mailbox or remote, which uses the
+// SASL login. Mailboxes log in with their email, remotes with their name.
+func (r *rateLimitsRepository) ListByLogin(login string) ([]RateLimit, error) {
+	mailboxes := sq.Sqlizer(sq.Expr("false"))
+	if email, err := utils.ParseEmailAddress(login); err == nil {
+		mailboxes = sq.Eq{"m.name": email.LocalPart, "dm.fqdn": email.DomainFQDN}
+	}
+	return r.list(mailboxes, sq.Eq{"rm.name": login})
+}
+
+func (r *rateLimitsRepository) list(mailboxes, remotes sq.Sqlizer) ([]RateLimit, error) {
+	q := sq.
+		Select(
+			"'mailbox' AS object_type",
+			"m.name || '@' || dm.fqdn AS name",
+			"EXTRACT(EPOCH FROM rl.period)::BIGINT",
+			"rl.max_messages",
+			"rl.created_at",
+			"rl.updated_at",
+		).
+		From("mailboxes_rate_limits rl").
+		Join("mailboxes m ON m.ID = rl.mailbox_id").
+		Join("domains_managed dm ON dm.ID = m.domain_id").
+		Where(sq.Eq{"m.deleted_at": nil}).
+		Where(mailboxes).
+		Suffix("UNION ALL (?) ORDER BY object_type, name, 3",
+			sq.
+				Select(
+					"'remote' AS object_type",
+					"rm.name",
+					"EXTRACT(EPOCH FROM rl.period)::BIGINT",
+					"rl.max_messages",
+					"rl.created_at",
+					"rl.updated_at",
+				).
+				From("remotes_rate_limits rl").
+				Join("remotes rm ON rm.ID = rl.remote_id").
+				Where(sq.Eq{"rm.deleted_at": nil}).
+				Where(remotes),
+		)
+
+	rows, err := q.PlaceholderFormat(sq.Dollar).RunWith(r.r).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []RateLimit
+	for rows.Next() {
+		var rl RateLimit
+		var seconds int64
+		if err := rows.Scan(&rl.ObjectType, &rl.Name, &seconds, &rl.MaxMessages, &rl.CreatedAt, &rl.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rl.Period = time.Duration(seconds) * time.Second
+		out = append(out, rl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *rateLimitsRepository) SetMailbox(email utils.EmailAddress, period time.Duration, maxMessages int32) error {
+	q := sq.
+		Insert("mailboxes_rate_limits").
+		Columns("mailbox_id", "period", "max_messages").
+		Values(
+			sq.Expr("(?)", sq.
+				Select("m.ID").
+				From("mailboxes m").
+				Join("domains_managed dm ON dm.ID = m.domain_id").
+				Where(sq.Eq{
+					"m.name":       email.LocalPart,
+					"dm.fqdn":      email.DomainFQDN,
+					"m.deleted_at": nil,
+				}),
+			),
+			sq.Expr("make_interval(secs => ?)", int64(period/time.Second)),
+			maxMessages,
+		).
+		Suffix("ON CONFLICT (mailbox_id, period) DO UPDATE SET max_messages = EXCLUDED.max_messages")
+
+	return Exec(r.r, q, 1)
+}
+
+func (r *rateLimitsRepository) SetRemote(name string, period time.Duration, maxMessages int32) error {
+	q := sq.
+		Insert("remotes_rate_limits").
+		Columns("remote_id", "period", "max_messages").
+		Values(
+			sq.Expr("(?)", sq.
+				Select("ID").
+				From("remotes").
+				Where(sq.Eq{
+					"name":       name,
+					"deleted_at": nil,
+				}),
+			),
+			sq.Expr("make_interval(secs => ?)", int64(period/time.Second)),
+			maxMessages,
+		).
+		Suffix("ON CONFLICT (remote_id, period) DO UPDATE SET max_messages = EXCLUDED.max_messages")
+
+	return Exec(r.r, q, 1)
+}
+
+// DeleteMailbox deletes the rate limit of a mailbox with the period or, if
+// period is nil, all of its rate limits.
+func (r *rateLimitsRepository) DeleteMailbox(email utils.EmailAddress, period *time.Duration) error {
+	q := sq.
+		Delete("mailboxes_rate_limits").
+		Where(sq.Expr("mailbox_id = (?)", sq.
+			Select("m.ID").
+			From("mailboxes m").
+			Join("domains_managed dm ON dm.ID = m.domain_id").
+			Where(sq.Eq{
+				"m.name":       email.LocalPart,
+				"dm.fqdn":      email.DomainFQDN,
+				"m.deleted_at": nil,
+			}),
+		))
+
+	return r.delete(q, period)
+}
+
+// DeleteRemote deletes the rate limit of a remote with the period or, if
+// period is nil, all of its rate limits.
+func (r *rateLimitsRepository) DeleteRemote(name string, period *time.Duration) error {
+	q := sq.
+		Delete("remotes_rate_limits").
+		Where(sq.Expr("remote_id = (?)", sq.
+			Select("ID").
+			From("remotes").
+			Where(sq.Eq{
+				"name":       name,
+				"deleted_at": nil,
+			}),
+		))
+
+	return r.delete(q, period)
+}
+
+func (r *rateLimitsRepository) delete(q sq.DeleteBuilder, period *time.Duration) error {
+	if period != nil {
+		q = q.Where(sq.Expr("period = make_interval(secs => ?)", int64(*period/time.Second)))
+		return Exec(r.r, q, 1)
+	}
+
+	result, err := q.PlaceholderFormat(sq.Dollar).RunWith(r.r).Exec()
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrAffectedRowsMismatch
+	}
+	return nil
+}
@@ -217,7 +217,7 @@ func (r *recipientsRelayedRepository) Delete(email utils.EmailAddress, options D
 	} else {
 		// Soft delete
 		uq := sq.Update("recipients_relayed").
-			Set("deleted_at", sq.Expr("NOW()")).
+			Set("deleted_at", options.deletedAtValue()).
 			Where(sq.Expr("domain_id = (?)", sq.
 				Select("ID").
 				From("domains_relayed").
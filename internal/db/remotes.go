@@ -2,19 +2,22 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/gerolf-vent/mailctl/internal/utils/crypto/passwordhash"
 )
 
 type Remote struct {
-	ID          int        `json:"id"`
-	Name        string     `json:"name"`
-	Enabled     bool       `json:"enabled"`
-	PasswordSet bool       `json:"passwordSet"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
-	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
+	ID           int        `json:"id"`
+	Name         string     `json:"name"`
+	Enabled      bool       `json:"enabled"`
+	PasswordSet  bool       `json:"passwordSet"`
+	PasswordHash *string    `json:"passwordHash,omitempty"` // Only with IncludePasswordHash
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+	DeletedAt    *time.Time `json:"deletedAt,omitempty"`
 }
 
 type RemotesCreateOptions struct {
@@ -28,13 +31,15 @@ type RemotesPatchOptions struct {
 }
 
 type RemotesListOptions struct {
-	ByName         string
-	IncludeDeleted bool
-	IncludeAll     bool
+	ByName              string
+	IncludeDeleted      bool
+	IncludeAll          bool
+	IncludePasswordHash bool
 }
 
 type RemotesRepository interface {
 	List(options RemotesListOptions) ([]Remote, error)
+	Authenticate(name string, givenPassword string) (matches bool, err error)
 	Create(name string, options RemotesCreateOptions) error
 	Patch(name string, options RemotesPatchOptions) error
 	Rename(oldName, newName string) error
@@ -53,12 +58,18 @@ func Remotes(r sq.BaseRunner) RemotesRepository {
 }
 
 func (r *remotesRepository) List(options RemotesListOptions) ([]Remote, error) {
+	passwordHashColumn := "NULL"
+	if options.IncludePasswordHash {
+		passwordHashColumn = "password_hash"
+	}
+
 	q := sq.
 		Select(
 			"id",
 			"name",
 			"enabled",
 			"password_hash IS NOT NULL AS password_set",
+			passwordHashColumn+" AS password_hash",
 			"created_at",
 			"updated_at",
 			"deleted_at",
@@ -91,18 +102,23 @@ func (r *remotesRepository) List(options RemotesListOptions) ([]Remote, error) {
 	var out []Remote
 	for rows.Next() {
 		var rr Remote
+		var passwordHash sql.NullString
 		var deletedAt sql.NullTime
 		if err := rows.Scan(
 			&rr.ID,
 			&rr.Name,
 			&rr.Enabled,
 			&rr.PasswordSet,
+			&passwordHash,
 			&rr.CreatedAt,
 			&rr.UpdatedAt,
 			&deletedAt,
 		); err != nil {
 			return nil, err
 		}
+		if passwordHash.Valid {
+			rr.PasswordHash = &passwordHash.String
+		}
 		if deletedAt.Valid {
 			t := deletedAt.Time
 			rr.DeletedAt = &t
@@ -113,6 +129,34 @@ func (r *remotesRepository) List(options RemotesListOptions) ([]Remote, error) {
 	return out, nil
 }
 
+func (r *remotesRepository) Authenticate(name string, givenPassword string) (matches bool, err error) {
+	var passwordHash sql.NullString
+
+	err = sq.
+		Select("password_hash").
+		From("remotes").
+		Where(sq.Eq{
+			"name":       name,
+			"deleted_at": nil,
+		}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r.r).
+		QueryRow().
+		Scan(&passwordHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return
+	}
+
+	if !passwordHash.Valid {
+		return false, nil
+	}
+
+	return passwordhash.Compare(passwordHash.String, givenPassword)
+}
+
 func (r *remotesRepository) Create(name string, options RemotesCreateOptions) error {
 	q := sq.
 		Insert("remotes").
@@ -174,7 +218,7 @@ func (r *remotesRepository) Delete(name string, options DeleteOptions) error {
 	} else {
 		// Soft delete
 		uq := sq.Update("remotes").
-			Set("deleted_at", sq.Expr("NOW()")).
+			Set("deleted_at", options.deletedAtValue()).
 			Where(sq.Expr("ID = (?)", sq.
 				Select("id").
 				From("remotes").
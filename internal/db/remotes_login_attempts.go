@@ -0,0 +1,184 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const (
+	RemotesLoginAttemptsTable string = "audit.remotes_login_attempts"
+)
+
+type RemoteLoginAttempt struct {
+	Name          string     `json:"name"`
+	Succeeded     bool       `json:"succeeded"`
+	FailureReason string     `json:"failureReason"`
+	RemoteIP      *string    `json:"remoteIP,omitempty"`
+	Protocol      string     `json:"protocol"`
+	Service       string     `json:"service"`
+	UnlockedAt    *time.Time `json:"unlockedAt,omitempty"`
+	AttemptedAt   time.Time  `json:"attemptedAt"`
+}
+
+type RemotesLoginAttemptsListOptions struct {
+	FilterNames []string
+	OnlyFailed  bool
+	Since       *time.Time
+	Limit       uint64 // Only the most recent attempts
+}
+
+type RemotesLoginAttemptsRepository interface {
+	List(options RemotesLoginAttemptsListOptions) ([]RemoteLoginAttempt, error)
+	CheckRateLimit(name string, count uint32, interval time.Duration) (ok bool, err error)
+	Record(name string, succeeded bool, failureReason string, options LoginAttemptsRecordOptions) (err error)
+	Unlock(name string) (err error)
+}
+
+type remotesLoginAttemptsRepository struct {
+	r sq.BaseRunner
+}
+
+func RemotesLoginAttempts(r sq.BaseRunner) RemotesLoginAttemptsRepository {
+	return &remotesLoginAttemptsRepository{
+		r: r,
+	}
+}
+
+func (r *remotesLoginAttemptsRepository) List(options RemotesLoginAttemptsListOptions) ([]RemoteLoginAttempt, error) {
+	q := sq.
+		Select(
+			"name",
+			"succeeded",
+			"failure_reason",
+			"HOST(remote_ip)",
+			"protocol",
+			"service",
+			"unlocked_at",
+			"attempted_at",
+		).
+		From(RemotesLoginAttemptsTable)
+
+	if len(options.FilterNames) > 0 {
+		q = q.Where(sq.Eq{"name": options.FilterNames})
+	}
+
+	if options.OnlyFailed {
+		q = q.Where(sq.Eq{"succeeded": false})
+	}
+
+	if options.Since != nil {
+		q = q.Where(sq.GtOrEq{"attempted_at": *options.Since})
+	}
+
+	q = q.OrderBy("attempted_at DESC")
+	if options.Limit > 0 {
+		q = q.Limit(options.Limit)
+	}
+
+	rows, err := q.
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r.r).
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []RemoteLoginAttempt
+	for rows.Next() {
+		var rla RemoteLoginAttempt
+		var remoteIP sql.NullString
+		var unlockedAt sql.NullTime
+		if err := rows.Scan(
+			&rla.Name,
+			&rla.Succeeded,
+			&rla.FailureReason,
+			&remoteIP,
+			&rla.Protocol,
+			&rla.Service,
+			&unlockedAt,
+			&rla.AttemptedAt,
+		); err != nil {
+			return nil, err
+		}
+		if remoteIP.Valid {
+			rla.RemoteIP = &remoteIP.String
+		}
+		if unlockedAt.Valid {
+			rla.UnlockedAt = &unlockedAt.Time
+		}
+
+		out = append(out, rla)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// CheckRateLimit checks whether a remote has less than count failed login
+// attempts within interval. Like the login lockout, it only counts failed
+// attempts since the last successful one, which haven't been unlocked. The
+// attempts are counted by dovecot.login_failures_remotes, so the dovecot user
+// can check them as well.
+func (r *remotesLoginAttemptsRepository) CheckRateLimit(name string, count uint32, interval time.Duration) (ok bool, err error) {
+	var attempts uint32
+
+	err = sq.
+		Select("failures").
+		Suffix("FROM dovecot.login_failures_remotes(?, make_interval(secs => ?)) AS failures", name, int64(interval/time.Second)).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(r.r).
+		QueryRow().
+		Scan(&attempts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	return attempts < count, nil
+}
+
+func (r *remotesLoginAttemptsRepository) Record(name string, succeeded bool, failureReason string, options LoginAttemptsRecordOptions) (err error) {
+	q := sq.
+		Insert(RemotesLoginAttemptsTable).
+		Columns("name", "succeeded", "failure_reason", "remote_ip", "protocol", "service").
+		Values(name, succeeded, failureReason, loginAttemptRemoteIP(options.RemoteIP), options.Protocol, options.Service)
+
+	return Exec(r.r, q, 1)
+}
+
+// Unlock marks the failed login attempts of a remote as unlocked, so they
+// don't count towards the login lockout and the rate limit anymore. The time of the unlock is
+// set on the remote as well, so it is recorded in the audit log.
+func (r *remotesLoginAttemptsRepository) Unlock(name string) (err error) {
+	unlock := sq.
+		Update("remotes").
+		Set("logins_unlocked_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{
+			"name":       name,
+			"deleted_at": nil,
+		})
+
+	if err := Exec(r.r, unlock, 1); err != nil {
+		return err
+	}
+
+	q := sq.
+		Update(RemotesLoginAttemptsTable).
+		Set("unlocked_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{
+			"name":        name,
+			"succeeded":   false,
+			"unlocked_at": nil,
+		})
+
+	_, err = q.PlaceholderFormat(sq.Dollar).RunWith(r.r).Exec()
+	return
+}
@@ -184,7 +184,7 @@ func (r *remotesSendGrantsRepository) Delete(remoteName string, email utils.Emai
 		// Soft delete
 		uq := sq.
 			Update("remotes_send_grants").
-			Set("deleted_at", sq.Expr("NOW()")).
+			Set("deleted_at", options.deletedAtValue()).
 			Where(sq.Expr("remote_id = (?)", sq.
 				Select("ID").
 				From("remotes").
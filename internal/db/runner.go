@@ -2,11 +2,49 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"sync"
 
 	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/lib/pq"
 )
 
+const (
+	RunStatusOK         string = "ok"
+	RunStatusFailed     string = "failed"
+	RunStatusDryRun     string = "dry-run"
+	RunStatusRolledBack string = "rolled-back" // Rolled back due to a failure of another item
+	RunStatusSkipped    string = "skipped"     // Not executed due to a failure of another item
+)
+
+// RunResult is the outcome of a runner for a single item.
+type RunResult struct {
+	Item     string          `json:"item"`
+	Status   string          `json:"status"`
+	Error    string          `json:"error,omitempty"`
+	SQLState string          `json:"sqlstate,omitempty"`
+	Affected []AuditLogEntry `json:"affected,omitempty"` // Rows affected by a dry run
+}
+
+// txRunOptions are the options shared by all runners.
+type txRunOptions struct {
+	FailureMessage string
+	SuccessMessage string
+	DryRun         bool
+	Impact         *ImpactOptions
+	Quiet          bool // Don't print messages, e.g. if the results are printed as JSON
+}
+
+// txItem is an item executed by a runner. String is called after Exec, so it
+// can report what Exec did (e.g. the counts of gc).
+type txItem struct {
+	String func() string
+	Exec   TxExecFunc
+}
+
 type TxExecFunc func(tx *sql.Tx) error
 
 type TxRunner struct {
@@ -14,14 +52,27 @@ type TxRunner struct {
 	ItemString     string
 	FailureMessage string
 	SuccessMessage string
+	DryRun         bool           // Print the affected rows and roll back instead of committing
+	Impact         *ImpactOptions // Preview dependent rows and ask for confirmation before committing
+	JSON           bool           // Print the result as JSON instead of messages
 }
 
-func (r TxRunner) Run() {
+// Run executes the function in a transaction. If it fails, the failure is
+// reported and an ExitError is returned.
+func (r TxRunner) Run() error {
+	options := txRunOptions{
+		FailureMessage: r.FailureMessage,
+		SuccessMessage: r.SuccessMessage,
+		DryRun:         r.DryRun,
+		Impact:         r.Impact,
+		Quiet:          r.JSON,
+	}
+	items := []txItem{{String: func() string { return r.ItemString }, Exec: r.Exec}}
+
 	// Connect to database
 	dbConn, err := Connect()
 	if err != nil {
-		utils.PrintError(fmt.Errorf("failed to connect to database: %w", err))
-		return
+		return finishRun(failedRuns(items, fmt.Errorf("failed to connect to database: %w", err), options), r.JSON)
 	}
 	defer func() {
 		if err := dbConn.Close(); err != nil {
@@ -29,30 +80,7 @@ func (r TxRunner) Run() {
 		}
 	}()
 
-	// Begin a transaction
-	tx, err := dbConn.Begin()
-	if err != nil {
-		utils.PrintError(fmt.Errorf("failed to begin transaction: %w", err))
-		return
-	}
-
-	// Execute the function
-	err = r.Exec(tx)
-	if err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
-			utils.PrintError(fmt.Errorf("failed to rollback transaction: %w", rbErr))
-		}
-		utils.PrintError(fmt.Errorf("%s (%s): %w", r.FailureMessage, r.ItemString, err))
-		return
-	}
-
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		utils.PrintError(fmt.Errorf("failed to commit transaction: %w", err))
-		return
-	}
-
-	utils.PrintSuccess(fmt.Sprintf("%s: %s", r.SuccessMessage, r.ItemString))
+	return finishRun(runTx(dbConn, items, options), r.JSON)
 }
 
 type TxExecForEach[T any] func(tx *sql.Tx, item T) error
@@ -63,14 +91,42 @@ type TxForEachRunner[T any] struct {
 	ItemString     func(item T) string
 	FailureMessage string
 	SuccessMessage string
+	DryRun         bool           // Print the affected rows and roll back instead of committing
+	Impact         *ImpactOptions // Preview dependent rows and ask for confirmation before committing
+	JSON           bool           // Print the results as JSON instead of messages
+	Atomic         bool           // Execute all items in a single transaction, which is rolled back on the first failure
+	Parallel       int            // Number of items executed concurrently, each in its own transaction
 }
 
-func (r TxForEachRunner[T]) Run() {
+// Run executes the function in a separate transaction for each item, or in a
+// single transaction for all items, if Atomic is set. With Parallel, up to
+// that many items are executed concurrently. If any item fails, the failures
+// are reported and an ExitError is returned.
+func (r TxForEachRunner[T]) Run() error {
+	options := txRunOptions{
+		FailureMessage: r.FailureMessage,
+		SuccessMessage: r.SuccessMessage,
+		DryRun:         r.DryRun,
+		Impact:         r.Impact,
+		Quiet:          r.JSON,
+	}
+
+	items := make([]txItem, 0, len(r.Items))
+	for _, item := range r.Items {
+		items = append(items, txItem{
+			String: func() string {
+				return r.ItemString(item)
+			},
+			Exec: func(tx *sql.Tx) error {
+				return r.Exec(tx, item)
+			},
+		})
+	}
+
 	// Connect to database
 	dbConn, err := Connect()
 	if err != nil {
-		utils.PrintError(fmt.Errorf("failed to connect to database: %w", err))
-		return
+		return finishRun(failedRuns(items, fmt.Errorf("failed to connect to database: %w", err), options), r.JSON)
 	}
 	defer func() {
 		if err := dbConn.Close(); err != nil {
@@ -78,30 +134,203 @@ func (r TxForEachRunner[T]) Run() {
 		}
 	}()
 
-	for _, item := range r.Items {
-		// Begin a transaction for each item
-		tx, err := dbConn.Begin()
-		if err != nil {
-			utils.PrintError(fmt.Errorf("failed to begin transaction: %w", err))
-			continue
+	if r.Atomic {
+		return finishRun(runTx(dbConn, items, options), r.JSON)
+	}
+
+	if r.Parallel > 1 {
+		return finishRun(runParallel(dbConn, items, options, r.Parallel), r.JSON)
+	}
+
+	results := make([]RunResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, runTx(dbConn, []txItem{item}, options)...)
+	}
+	return finishRun(results, r.JSON)
+}
+
+// runParallel executes each item in its own transaction on a pool of workers,
+// which share the connections of dbConn. The messages are printed in the order
+// of the items after all of them are done, so the output doesn't depend on the
+// scheduling. Dependent rows can't be confirmed interactively, so exceeding
+// the threshold of Impact fails the item unless it is confirmed in advance.
+func runParallel(dbConn *sql.DB, items []txItem, options txRunOptions, workers int) []RunResult {
+	dbConn.SetMaxOpenConns(workers)
+	dbConn.SetMaxIdleConns(workers)
+
+	workerOptions := options
+	workerOptions.Quiet = true
+
+	results := make([]RunResult, len(items))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(items)) {
+		wg.Go(func() {
+			for i := range indices {
+				results[i] = runTx(dbConn, items[i:i+1], workerOptions)[0]
+			}
+		})
+	}
+	for i := range items {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	if !options.Quiet {
+		for _, result := range results {
+			printResult(result, options)
 		}
+	}
+	return results
+}
+
+// runTx executes the items in a new transaction and commits it. On the first
+// failure and for a dry run the transaction is rolled back instead. Messages
+// are only printed, if the results aren't printed as JSON.
+func runTx(dbConn *sql.DB, items []txItem, options txRunOptions) []RunResult {
+	// Begin a transaction
+	tx, err := Begin(dbConn)
+	if err != nil {
+		return failedRuns(items, fmt.Errorf("failed to begin transaction: %w", err), options)
+	}
 
+	results := make([]RunResult, 0, len(items))
+	seen := 0 // Number of rows affected by the previous items
+	for i, item := range items {
 		// Execute the function
-		err = r.Exec(tx, item)
+		err := item.Exec(tx)
+
+		// Collect the rows affected by this item
+		var entries []AuditLogEntry
+		if err == nil && (options.DryRun || options.Impact != nil) {
+			entries, err = transactionEntries(tx)
+			if err == nil {
+				entries, seen = entries[seen:], len(entries)
+			}
+		}
+		if err == nil && options.Impact != nil && !options.DryRun {
+			err = confirmImpact(entries, item.String(), *options.Impact, options.Quiet)
+		}
+
 		if err != nil {
-			if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone && !options.Quiet {
 				utils.PrintError(fmt.Errorf("failed to rollback transaction: %w", rbErr))
 			}
-			utils.PrintError(fmt.Errorf("%s (%s): %w", r.FailureMessage, r.ItemString(item), err))
-			continue
+			results = append(results, failedRun(item.String(), fmt.Errorf("%s (%s): %w", options.FailureMessage, item.String(), err), options))
+
+			// The other items share the transaction
+			if len(items) > 1 {
+				for j := range results[:i] {
+					results[j].Status = RunStatusRolledBack
+					results[j].Affected = nil
+				}
+				for _, other := range items[i+1:] {
+					results = append(results, RunResult{Item: other.String(), Status: RunStatusSkipped})
+				}
+				if !options.Quiet {
+					utils.PrintWarning(fmt.Sprintf("Rolled back all %d items due to the failure of %s", len(items), item.String()))
+				}
+			}
+			return results
+		}
+
+		if options.DryRun {
+			if !options.Quiet {
+				printDryRun(item.String(), entries)
+			}
+			results = append(results, RunResult{Item: item.String(), Status: RunStatusDryRun, Affected: entries})
+		} else {
+			results = append(results, RunResult{Item: item.String(), Status: RunStatusOK})
+		}
+	}
+
+	if options.DryRun {
+		if err := tx.Rollback(); err != nil {
+			return failedRuns(items, fmt.Errorf("failed to rollback transaction: %w", err), options)
+		}
+		return results
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return failedRuns(items, fmt.Errorf("failed to commit transaction: %w", err), options)
+	}
+
+	if !options.Quiet {
+		for _, result := range results {
+			utils.PrintSuccess(fmt.Sprintf("%s: %s", options.SuccessMessage, result.Item))
+		}
+	}
+	return results
+}
+
+// printResult prints the message of a result, which wasn't printed while
+// executing its item.
+func printResult(result RunResult, options txRunOptions) {
+	switch result.Status {
+	case RunStatusOK:
+		utils.PrintSuccess(fmt.Sprintf("%s: %s", options.SuccessMessage, result.Item))
+	case RunStatusDryRun:
+		printDryRun(result.Item, result.Affected)
+	case RunStatusFailed:
+		utils.PrintError(errors.New(result.Error))
+	}
+}
+
+func failedRun(itemString string, err error, options txRunOptions) RunResult {
+	if !options.Quiet {
+		utils.PrintError(err)
+	}
+	return RunResult{
+		Item:     itemString,
+		Status:   RunStatusFailed,
+		Error:    err.Error(),
+		SQLState: sqlState(err),
+	}
+}
+
+// failedRuns reports an error affecting all items.
+func failedRuns(items []txItem, err error, options txRunOptions) []RunResult {
+	if !options.Quiet {
+		utils.PrintError(err)
+	}
+	results := make([]RunResult, 0, len(items))
+	for _, item := range items {
+		results = append(results, RunResult{
+			Item:     item.String(),
+			Status:   RunStatusFailed,
+			Error:    err.Error(),
+			SQLState: sqlState(err),
+		})
+	}
+	return results
+}
+
+// finishRun prints the results as JSON, if requested, and returns an
+// ExitError, if any item failed.
+func finishRun(results []RunResult, printJSON bool) error {
+	if printJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		if err := encoder.Encode(results); err != nil {
+			utils.PrintErrorWithMessage("Failed to encode JSON", err)
+			return utils.ExitError{Code: 1}
 		}
+	}
 
-		// Commit the transaction
-		if err := tx.Commit(); err != nil {
-			utils.PrintError(fmt.Errorf("failed to commit transaction: %w", err))
-			continue
+	for _, result := range results {
+		if result.Status == RunStatusFailed {
+			return utils.ExitError{Code: 1}
 		}
+	}
+	return nil
+}
 
-		utils.PrintSuccess(fmt.Sprintf("%s: %s", r.SuccessMessage, r.ItemString(item)))
+// sqlState returns the SQLSTATE code of a database error or an empty string.
+func sqlState(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
 	}
+	return ""
 }
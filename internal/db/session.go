@@ -0,0 +1,71 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"os/user"
+)
+
+// Session carries information about the operator, which is recorded in the
+// audit log for every change made in a transaction.
+type Session struct {
+	Operator string
+	Reason   string
+}
+
+var currentSession = Session{
+	Operator: DefaultOperator(),
+}
+
+// SetSession sets the session used for all transactions begun by Begin.
+func SetSession(session Session) {
+	currentSession = session
+}
+
+// CurrentSession returns the session used for all transactions begun by Begin.
+func CurrentSession() Session {
+	return currentSession
+}
+
+// DefaultOperator returns the operator name derived from the OS user and
+// hostname (e.g. "alice@workstation").
+func DefaultOperator() string {
+	userName := "unknown"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		userName = u.Username
+	}
+
+	hostName, err := os.Hostname()
+	if err != nil || hostName == "" {
+		return userName
+	}
+
+	return userName + "@" + hostName
+}
+
+// Begin starts a transaction and applies the current session to it, so the
+// audit log hook can attribute changes to the operator.
+func Begin(dbConn *sql.DB) (*sql.Tx, error) {
+	tx, err := dbConn.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applySession(tx, currentSession); err != nil {
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("failed to apply session: %w", err)
+	}
+
+	return tx, nil
+}
+
+func applySession(tx *sql.Tx, session Session) error {
+	// The settings are local to the transaction (third argument of set_config)
+	_, err := tx.Exec(
+		"SELECT set_config('mailctl.current_user', $1, true), set_config('mailctl.reason', $2, true)",
+		session.Operator,
+		session.Reason,
+	)
+	return err
+}
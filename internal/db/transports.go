@@ -194,7 +194,7 @@ func (r *transportsRepository) Delete(name string, options DeleteOptions) error
 		// Soft delete
 		uq := sq.
 			Update("transports").
-			Set("deleted_at", sq.Expr("NOW()")).
+			Set("deleted_at", options.deletedAtValue()).
 			Where(sq.Eq{
 				"name": name,
 			})
@@ -0,0 +1,377 @@
+package migrate
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gerolf-vent/mailctl/internal/state"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+// PostfixMapEntry is an entry of a Postfix lookup table or aliases file.
+type PostfixMapEntry struct {
+	File  string
+	Line  int
+	Key   string
+	Value string
+}
+
+func (e PostfixMapEntry) issue(reason string) Issue {
+	return Issue{Object: fmt.Sprintf("%s:%d (%s)", e.File, e.Line, e.Key), Reason: reason}
+}
+
+// ParsePostfixMap parses the source of a Postfix lookup table ("key value") or,
+// with aliases set, an aliases file ("name: value"). Lines starting with
+// whitespace continue the previous line, empty lines and comments are skipped.
+func ParsePostfixMap(file string, data []byte, aliases bool) ([]PostfixMapEntry, error) {
+	var entries []PostfixMapEntry
+	for i, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			if len(entries) == 0 {
+				return nil, fmt.Errorf("%s:%d: continuation line without a previous entry", file, i+1)
+			}
+			entries[len(entries)-1].Value = strings.TrimSpace(entries[len(entries)-1].Value + " " + trimmed)
+			continue
+		}
+
+		separator := strings.IndexAny(trimmed, " \t")
+		if aliases {
+			separator = strings.Index(trimmed, ":")
+		}
+		if separator < 0 {
+			return nil, fmt.Errorf("%s:%d: missing value of %q", file, i+1, trimmed)
+		}
+
+		entries = append(entries, PostfixMapEntry{
+			File:  file,
+			Line:  i + 1,
+			Key:   strings.ToLower(strings.TrimSpace(trimmed[:separator])),
+			Value: strings.TrimSpace(trimmed[separator+1:]),
+		})
+	}
+	return entries, nil
+}
+
+// PostfixMaps are the entries of the Postfix lookup tables to migrate.
+type PostfixMaps struct {
+	Virtual        []PostfixMapEntry // virtual_alias_maps
+	VirtualMailbox []PostfixMapEntry // virtual_mailbox_maps
+	RelayRecipient []PostfixMapEntry // relay_recipient_maps
+	Transport      []PostfixMapEntry // transport_maps
+	Aliases        []PostfixMapEntry // alias_maps
+	AliasesDomain  string            // Domain of the local names in Aliases
+
+	// DefaultTransport is the transport of managed and relayed domains without
+	// an entry in Transport (like virtual_transport and relay_transport).
+	DefaultTransport string
+}
+
+// Document maps the entries onto a state document. Everything, which can't be
+// mapped, is skipped and returned as issue.
+//
+// Domains of virtual_mailbox_maps become managed domains, domains of
+// relay_recipient_maps relayed domains and the virtual alias domains of
+// virtual_alias_maps ("example.com anything") alias domains. Their keys become
+// mailboxes, relayed recipients and aliases ("@example.com" catchall targets).
+// The entries of the aliases file become aliases on AliasesDomain. Managed and
+// relayed domains without a transport are reported, unless there is a
+// DefaultTransport.
+func (m *PostfixMaps) Document() (*state.Document, []Issue) {
+	doc := &state.Document{Version: state.DocumentVersion}
+	var issues []Issue
+
+	domainIndex := make(map[string]int)
+	addDomain := func(e PostfixMapEntry, fqdn, domainType string) {
+		if i, ok := domainIndex[fqdn]; ok {
+			if doc.Domains[i].Type != domainType {
+				issues = append(issues, e.issue(fmt.Sprintf("domain is already used as %s domain, can't be a %s domain as well", doc.Domains[i].Type, domainType)))
+			}
+			return
+		}
+		domainIndex[fqdn] = len(doc.Domains)
+		doc.Domains = append(doc.Domains, state.Domain{FQDN: fqdn, Type: domainType})
+	}
+
+	// Virtual alias domains
+	for _, e := range m.Virtual {
+		if !strings.Contains(e.Key, "@") && strings.Contains(e.Key, ".") {
+			addDomain(e, e.Key, "alias")
+		}
+	}
+
+	// Mailboxes
+	mailboxes := make(map[string]bool)
+	for _, e := range m.VirtualMailbox {
+		email, domainFQDN, ok := parseMapKey(e, &issues)
+		if !ok {
+			continue
+		}
+		addDomain(e, domainFQDN, "managed")
+		if email == "" {
+			issues = append(issues, e.issue("catchall mailboxes aren't supported"))
+			continue
+		}
+		if !mailboxes[email] {
+			mailboxes[email] = true
+			doc.Mailboxes = append(doc.Mailboxes, state.Mailbox{Email: email})
+		}
+	}
+
+	// Relayed recipients
+	relayed := make(map[string]bool)
+	for _, e := range m.RelayRecipient {
+		email, domainFQDN, ok := parseMapKey(e, &issues)
+		if !ok {
+			continue
+		}
+		addDomain(e, domainFQDN, "relayed")
+		if email == "" {
+			issues = append(issues, e.issue("relaying all recipients of a domain isn't supported, list them individually"))
+			continue
+		}
+		if !relayed[email] {
+			relayed[email] = true
+			doc.RecipientsRelayed = append(doc.RecipientsRelayed, state.RecipientRelayed{Email: email})
+		}
+	}
+
+	// Aliases and catchall targets
+	aliasIndex := make(map[string]int)
+	addAlias := func(e PostfixMapEntry, email string, targets []string) {
+		if mailboxes[email] {
+			issues = append(issues, e.issue(fmt.Sprintf("forwarding of mailbox to %s not migrated", strings.Join(targets, ", "))))
+			return
+		}
+		i, ok := aliasIndex[email]
+		if !ok {
+			i = len(doc.Aliases)
+			aliasIndex[email] = i
+			doc.Aliases = append(doc.Aliases, state.Alias{Email: email})
+		}
+		for _, target := range targets {
+			if !slices.ContainsFunc(doc.Aliases[i].Targets, func(t state.AliasTarget) bool { return t.Email == target }) {
+				doc.Aliases[i].Targets = append(doc.Aliases[i].Targets, state.AliasTarget{Email: target})
+			}
+		}
+	}
+
+	for _, e := range m.Virtual {
+		if !strings.Contains(e.Key, "@") && strings.Contains(e.Key, ".") {
+			continue // Virtual alias domain
+		}
+
+		email, domainFQDN, ok := parseMapKey(e, &issues)
+		if !ok {
+			continue
+		}
+
+		var targets []string
+		for _, target := range splitMapValue(e.Value) {
+			if strings.EqualFold(target, e.Key) {
+				continue
+			}
+			address, err := utils.ParseEmailAddress(target)
+			if err != nil {
+				issues = append(issues, e.issue(fmt.Sprintf("target %q skipped, invalid email address", target)))
+				continue
+			}
+			targets = append(targets, address.String())
+		}
+		if len(targets) == 0 {
+			continue
+		}
+
+		if email != "" {
+			addAlias(e, email, targets)
+			continue
+		}
+
+		i, ok := domainIndex[domainFQDN]
+		if !ok {
+			issues = append(issues, e.issue("catchall skipped, its domain isn't defined by the maps"))
+			continue
+		}
+		for _, target := range targets {
+			doc.Domains[i].CatchallTargets = append(doc.Domains[i].CatchallTargets, state.CatchallTarget{Target: target})
+		}
+	}
+
+	for _, e := range m.Aliases {
+		email, err := utils.ParseEmailAddress(e.Key + "@" + m.AliasesDomain)
+		if err != nil {
+			issues = append(issues, e.issue("alias skipped, invalid name"))
+			continue
+		}
+
+		var targets []string
+		for _, target := range splitMapValue(e.Value) {
+			switch {
+			case strings.HasPrefix(target, "|"), strings.HasPrefix(target, "/"), strings.HasPrefix(target, ":include:"):
+				issues = append(issues, e.issue(fmt.Sprintf("target %q skipped, commands, files and includes aren't supported", target)))
+				continue
+			case !strings.Contains(target, "@"):
+				target += "@" + m.AliasesDomain
+			}
+			address, err := utils.ParseEmailAddress(target)
+			if err != nil {
+				issues = append(issues, e.issue(fmt.Sprintf("target %q skipped, invalid email address", target)))
+				continue
+			}
+			if address.String() != email.String() {
+				targets = append(targets, address.String())
+			}
+		}
+		if len(targets) > 0 {
+			addAlias(e, email.String(), targets)
+		}
+	}
+
+	// Transports
+	transportIndex := make(map[string]int)
+	for _, e := range m.Transport {
+		i, ok := domainIndex[e.Key]
+		if !ok {
+			issues = append(issues, e.issue("transport skipped, only domains defined by the maps are supported"))
+			continue
+		}
+		if doc.Domains[i].Type != "managed" && doc.Domains[i].Type != "relayed" {
+			issues = append(issues, e.issue(fmt.Sprintf("transport skipped, %s domains have no transport", doc.Domains[i].Type)))
+			continue
+		}
+
+		transport, err := parseTransport(e.Value)
+		if err != nil {
+			issues = append(issues, e.issue(fmt.Sprintf("transport skipped, %v", err)))
+			continue
+		}
+
+		if _, ok := transportIndex[transport.Name]; !ok {
+			transportIndex[transport.Name] = len(doc.Transports)
+			doc.Transports = append(doc.Transports, transport)
+		}
+		doc.Domains[i].Transport = &transport.Name
+	}
+
+	for i := range doc.Domains {
+		d := &doc.Domains[i]
+		if (d.Type != "managed" && d.Type != "relayed") || d.Transport != nil {
+			continue
+		}
+		if m.DefaultTransport == "" {
+			issues = append(issues, Issue{d.FQDN, "domain has no transport, add it to transport_maps or use a default transport"})
+			continue
+		}
+		d.Transport = &m.DefaultTransport
+	}
+
+	return doc, issues
+}
+
+// parseMapKey parses a key of the form "user@example.com" or "@example.com".
+// For the latter, the returned email is empty.
+func parseMapKey(e PostfixMapEntry, issues *[]Issue) (email string, domainFQDN string, ok bool) {
+	if !strings.Contains(e.Key, "@") {
+		*issues = append(*issues, e.issue("keys without domain aren't supported"))
+		return "", "", false
+	}
+
+	if strings.HasPrefix(e.Key, "@") {
+		domainFQDN, err := utils.ParseDomainFQDN(e.Key[1:])
+		if err != nil {
+			*issues = append(*issues, e.issue("invalid domain"))
+			return "", "", false
+		}
+		return "", domainFQDN, true
+	}
+
+	address, err := utils.ParseEmailAddress(e.Key)
+	if err != nil {
+		*issues = append(*issues, e.issue("invalid email address"))
+		return "", "", false
+	}
+	return address.String(), address.DomainFQDN, true
+}
+
+// splitMapValue splits a value into the addresses separated by commas or
+// whitespace. Quoted parts (e.g. "|/usr/bin/vacation user") aren't split and
+// are returned without quotes.
+func splitMapValue(value string) []string {
+	var fields []string
+	var field strings.Builder
+	quoted := false
+	for _, r := range value {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case !quoted && (r == ',' || r == ' ' || r == '\t'):
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// parseTransport parses a transport like "smtp:[relay.example.com]:587" or
+// "lmtp:unix:private/dovecot-lmtp". The transport is named after the method
+// and the next hop.
+func parseTransport(value string) (state.Transport, error) {
+	method, nexthop, _ := strings.Cut(value, ":")
+	if method != "smtp" && method != "lmtp" && method != "relay" {
+		return state.Transport{}, fmt.Errorf("method %q isn't supported", method)
+	}
+	if nexthop == "" {
+		return state.Transport{}, fmt.Errorf("missing next hop")
+	}
+
+	transport := state.Transport{Method: method, MXLookup: true}
+	switch {
+	case strings.HasPrefix(nexthop, "unix:"):
+		// Unix sockets are kept as host without brackets, which renders them
+		// as given
+		transport.Host = nexthop
+	default:
+		nexthop = strings.TrimPrefix(nexthop, "inet:")
+		if strings.HasPrefix(nexthop, "[") {
+			end := strings.Index(nexthop, "]")
+			if end < 0 {
+				return state.Transport{}, fmt.Errorf("invalid next hop %q", nexthop)
+			}
+			transport.Host = nexthop[1:end]
+			transport.MXLookup = false
+			nexthop = nexthop[end+1:]
+		} else {
+			transport.Host, nexthop, _ = strings.Cut(nexthop, ":")
+			if nexthop != "" {
+				nexthop = ":" + nexthop
+			}
+		}
+
+		if nexthop != "" {
+			port, err := strconv.ParseUint(strings.TrimPrefix(nexthop, ":"), 10, 16)
+			if err != nil || port == 0 || !strings.HasPrefix(nexthop, ":") {
+				return state.Transport{}, fmt.Errorf("invalid port in %q", value)
+			}
+			transport.Port = ptr(uint16(port))
+		}
+	}
+
+	transport.Name = method + "-" + strings.NewReplacer(":", "-", "/", "-", "[", "", "]", "").Replace(transport.Host)
+	if transport.Port != nil {
+		transport.Name += "-" + strconv.Itoa(int(*transport.Port))
+	}
+	return transport, nil
+}
@@ -0,0 +1,189 @@
+package migrate
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestParsePostfixMap(t *testing.T) {
+	entries, err := ParsePostfixMap("virtual", []byte("# aliases\nteam@example.com\talice@example.com,\n  bob@example.com\n\n@example.com  alice@example.com\n"), false)
+	if err != nil {
+		t.Fatalf("ParsePostfixMap() unexpected error: %v", err)
+	}
+	want := []PostfixMapEntry{
+		{File: "virtual", Line: 2, Key: "team@example.com", Value: "alice@example.com, bob@example.com"},
+		{File: "virtual", Line: 5, Key: "@example.com", Value: "alice@example.com"},
+	}
+	if !slices.Equal(entries, want) {
+		t.Fatalf("ParsePostfixMap() = %v, want %v", entries, want)
+	}
+
+	entries, err = ParsePostfixMap("aliases", []byte("Postmaster: root\nroot: admin, \"|/usr/bin/notify root\"\n"), true)
+	if err != nil {
+		t.Fatalf("ParsePostfixMap() unexpected error: %v", err)
+	}
+	want = []PostfixMapEntry{
+		{File: "aliases", Line: 1, Key: "postmaster", Value: "root"},
+		{File: "aliases", Line: 2, Key: "root", Value: "admin, \"|/usr/bin/notify root\""},
+	}
+	if !slices.Equal(entries, want) {
+		t.Fatalf("ParsePostfixMap() = %v, want %v", entries, want)
+	}
+
+	for _, in := range []string{"  continued\n", "keyonly\n"} {
+		if _, err := ParsePostfixMap("virtual", []byte(in), false); err == nil {
+			t.Fatalf("ParsePostfixMap(%q) expected error", in)
+		}
+	}
+}
+
+func TestPostfixMapsDocument(t *testing.T) {
+	parse := func(file, in string, aliases bool) []PostfixMapEntry {
+		entries, err := ParsePostfixMap(file, []byte(in), aliases)
+		if err != nil {
+			t.Fatalf("ParsePostfixMap() unexpected error: %v", err)
+		}
+		return entries
+	}
+
+	m := &PostfixMaps{
+		Virtual: parse("virtual", `
+example.org            anything
+info@example.org       alice@example.com
+@example.org           alice@example.com
+alice@example.com      alice@example.com, alice@gmail.example
+team@example.com       alice@example.com bob@example.com
+webmaster              root
+`, false),
+		VirtualMailbox: parse("vmailbox", `
+alice@example.com      example.com/alice/
+bob@example.com        example.com/bob/
+@example.com           example.com/catchall/
+`, false),
+		RelayRecipient: parse("relay_recipients", `
+carol@example.net      OK
+@example.net           OK
+`, false),
+		Transport: parse("transport", `
+example.com            lmtp:unix:private/dovecot-lmtp
+example.net            smtp:[mx.example.net]:2525
+example.org            smtp:[mx.example.org]
+other.example          smtp:relay.example
+`, false),
+		Aliases: parse("aliases", `
+postmaster: root
+root:       admin, "|/usr/bin/notify root"
+`, true),
+		AliasesDomain: "example.com",
+	}
+
+	doc, issues := m.Document()
+	if err := doc.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+	if err := doc.Normalize(); err != nil {
+		t.Fatalf("Normalize() unexpected error: %v", err)
+	}
+
+	want := `{"version":1,` +
+		`"transports":[` +
+		`{"name":"lmtp-unix-private-dovecot-lmtp","method":"lmtp","host":"unix:private/dovecot-lmtp","mxLookup":true},` +
+		`{"name":"smtp-mx.example.net-2525","method":"smtp","host":"mx.example.net","port":2525,"mxLookup":false}],` +
+		`"domains":[` +
+		`{"fqdn":"example.org","type":"alias","enabled":true,"catchallTargets":[{"target":"alice@example.com","forwarding":true,"fallbackOnly":true}]},` +
+		`{"fqdn":"example.com","type":"managed","enabled":true,"transport":"lmtp-unix-private-dovecot-lmtp"},` +
+		`{"fqdn":"example.net","type":"relayed","enabled":true,"transport":"smtp-mx.example.net-2525"}],` +
+		`"mailboxes":[` +
+		`{"email":"alice@example.com","login":true,"receiving":true,"sending":true},` +
+		`{"email":"bob@example.com","login":true,"receiving":true,"sending":true}],` +
+		`"aliases":[` +
+		`{"email":"info@example.org","enabled":true,"targets":[{"email":"alice@example.com","forwarding":true,"sending":false}]},` +
+		`{"email":"team@example.com","enabled":true,"targets":[{"email":"alice@example.com","forwarding":true,"sending":false},{"email":"bob@example.com","forwarding":true,"sending":false}]},` +
+		`{"email":"postmaster@example.com","enabled":true,"targets":[{"email":"root@example.com","forwarding":true,"sending":false}]},` +
+		`{"email":"root@example.com","enabled":true,"targets":[{"email":"admin@example.com","forwarding":true,"sending":false}]}],` +
+		`"recipientsRelayed":[{"email":"carol@example.net","enabled":true}]}`
+	got, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+	if string(got) != want {
+		t.Fatalf("Document() =\n%s\nwant\n%s", got, want)
+	}
+
+	gotIssues := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		gotIssues = append(gotIssues, issue.String())
+	}
+	wantIssues := []string{
+		"vmailbox:4 (@example.com): catchall mailboxes aren't supported",
+		"relay_recipients:3 (@example.net): relaying all recipients of a domain isn't supported, list them individually",
+		"virtual:5 (alice@example.com): forwarding of mailbox to alice@gmail.example not migrated",
+		"virtual:7 (webmaster): keys without domain aren't supported",
+		`aliases:3 (root): target "|/usr/bin/notify root" skipped, commands, files and includes aren't supported`,
+		"transport:4 (example.org): transport skipped, alias domains have no transport",
+		"transport:5 (other.example): transport skipped, only domains defined by the maps are supported",
+	}
+	if !slices.Equal(gotIssues, wantIssues) {
+		t.Fatalf("Document() issues =\n%q\nwant\n%q", gotIssues, wantIssues)
+	}
+}
+
+func TestPostfixMapsDefaultTransport(t *testing.T) {
+	vmailbox, err := ParsePostfixMap("vmailbox", []byte("alice@example.com example.com/alice/\n"), false)
+	if err != nil {
+		t.Fatalf("ParsePostfixMap() unexpected error: %v", err)
+	}
+	relayRecipients, err := ParsePostfixMap("relay_recipients", []byte("carol@example.net OK\n"), false)
+	if err != nil {
+		t.Fatalf("ParsePostfixMap() unexpected error: %v", err)
+	}
+
+	m := &PostfixMaps{VirtualMailbox: vmailbox, RelayRecipient: relayRecipients}
+
+	doc, issues := m.Document()
+	gotIssues := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		gotIssues = append(gotIssues, issue.String())
+	}
+	wantIssues := []string{
+		"example.com: domain has no transport, add it to transport_maps or use a default transport",
+		"example.net: domain has no transport, add it to transport_maps or use a default transport",
+	}
+	if !slices.Equal(gotIssues, wantIssues) {
+		t.Fatalf("Document() issues =\n%q\nwant\n%q", gotIssues, wantIssues)
+	}
+	if err := doc.Validate(); err == nil {
+		t.Fatalf("Validate() expected error for domains without transport")
+	}
+
+	m.DefaultTransport = "dovecot"
+	doc, issues = m.Document()
+	if len(issues) != 0 {
+		t.Fatalf("Document() unexpected issues: %v", issues)
+	}
+	for _, d := range doc.Domains {
+		if d.Transport == nil || *d.Transport != "dovecot" {
+			t.Fatalf("Document() domain %s has transport %v, want %q", d.FQDN, d.Transport, "dovecot")
+		}
+	}
+	if err := doc.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestParseTransport(t *testing.T) {
+	for _, in := range []string{"error:mailbox unavailable", "smtp:", "smtp:[relay.example", "smtp:relay.example:0"} {
+		if _, err := parseTransport(in); err == nil {
+			t.Fatalf("parseTransport(%q) expected error", in)
+		}
+	}
+
+	transport, err := parseTransport("relay:inet:relay.example:587")
+	if err != nil {
+		t.Fatalf("parseTransport() unexpected error: %v", err)
+	}
+	if transport.Name != "relay-relay.example-587" || transport.Host != "relay.example" || !transport.MXLookup || *transport.Port != 587 {
+		t.Fatalf("parseTransport() = %+v", transport)
+	}
+}
@@ -0,0 +1,340 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/gerolf-vent/mailctl/internal/state"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/gerolf-vent/mailctl/internal/utils/crypto/passwordhash"
+)
+
+// Issue is something, which couldn't be mapped from the source.
+type Issue struct {
+	Object string
+	Reason string
+}
+
+func (i Issue) String() string {
+	return i.Object + ": " + i.Reason
+}
+
+// PostfixAdmin holds the rows read from a PostfixAdmin database.
+type PostfixAdmin struct {
+	Domains      []PostfixAdminDomain
+	Mailboxes    []PostfixAdminMailbox
+	Aliases      []PostfixAdminAlias
+	AliasDomains []PostfixAdminAliasDomain
+	Vacations    []string // Addresses with an active vacation message
+	Fetchmail    []string // Mailboxes with a fetchmail configuration
+}
+
+type PostfixAdminDomain struct {
+	Domain    string
+	Transport string
+	BackupMX  bool
+	Active    bool
+}
+
+type PostfixAdminMailbox struct {
+	Username string
+	Password string
+	Quota    int64 // In bytes, 0 for unlimited
+	Active   bool
+}
+
+type PostfixAdminAlias struct {
+	Address string
+	Goto    string // Comma-separated targets
+	Active  bool
+}
+
+type PostfixAdminAliasDomain struct {
+	AliasDomain  string
+	TargetDomain string
+	Active       bool
+}
+
+// ReadPostfixAdmin reads the domain, mailbox, alias and alias_domain tables of
+// a PostfixAdmin database. The vacation and fetchmail tables are only read to
+// report them, if they exist.
+func ReadPostfixAdmin(r *sql.DB) (*PostfixAdmin, error) {
+	pfa := &PostfixAdmin{}
+
+	err := queryRows(r, "SELECT domain, COALESCE(transport, ''), backupmx, active FROM domain WHERE domain <> 'ALL' ORDER BY domain", func(rows *sql.Rows) error {
+		var d PostfixAdminDomain
+		if err := rows.Scan(&d.Domain, &d.Transport, &d.BackupMX, &d.Active); err != nil {
+			return err
+		}
+		pfa.Domains = append(pfa.Domains, d)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read domains: %w", err)
+	}
+
+	err = queryRows(r, "SELECT username, COALESCE(password, ''), COALESCE(quota, 0), active FROM mailbox ORDER BY username", func(rows *sql.Rows) error {
+		var m PostfixAdminMailbox
+		if err := rows.Scan(&m.Username, &m.Password, &m.Quota, &m.Active); err != nil {
+			return err
+		}
+		pfa.Mailboxes = append(pfa.Mailboxes, m)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mailboxes: %w", err)
+	}
+
+	err = queryRows(r, "SELECT address, COALESCE(goto, ''), active FROM alias ORDER BY address", func(rows *sql.Rows) error {
+		var a PostfixAdminAlias
+		if err := rows.Scan(&a.Address, &a.Goto, &a.Active); err != nil {
+			return err
+		}
+		pfa.Aliases = append(pfa.Aliases, a)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read aliases: %w", err)
+	}
+
+	err = queryRows(r, "SELECT alias_domain, target_domain, active FROM alias_domain ORDER BY alias_domain", func(rows *sql.Rows) error {
+		var ad PostfixAdminAliasDomain
+		if err := rows.Scan(&ad.AliasDomain, &ad.TargetDomain, &ad.Active); err != nil {
+			return err
+		}
+		pfa.AliasDomains = append(pfa.AliasDomains, ad)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alias domains: %w", err)
+	}
+
+	// Optional tables, which can't be migrated
+	optional := []struct {
+		table string
+		query string
+		into  *[]string
+	}{
+		{"vacation", "SELECT email FROM vacation WHERE active ORDER BY email", &pfa.Vacations},
+		{"fetchmail", "SELECT mailbox FROM fetchmail ORDER BY mailbox", &pfa.Fetchmail},
+	}
+	for _, o := range optional {
+		var exists bool
+		if err := r.QueryRow("SELECT to_regclass($1) IS NOT NULL", o.table).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to check table %s: %w", o.table, err)
+		}
+		if !exists {
+			continue
+		}
+
+		err := queryRows(r, o.query, func(rows *sql.Rows) error {
+			var s string
+			if err := rows.Scan(&s); err != nil {
+				return err
+			}
+			*o.into = append(*o.into, s)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", o.table, err)
+		}
+	}
+
+	return pfa, nil
+}
+
+func queryRows(r *sql.DB, query string, scan func(rows *sql.Rows) error) error {
+	rows, err := r.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Document maps the PostfixAdmin objects onto a state document. Everything,
+// which can't be mapped, is skipped and returned as issue.
+//
+// Domains become managed domains, alias domains canonical domains, aliases of
+// a domain ("@example.com") catchall targets. The aliases PostfixAdmin creates
+// for each mailbox are skipped, unless they forward to other addresses.
+// Managed domains get the given transport, PostfixAdmin's transports aren't
+// mapped.
+func (pfa *PostfixAdmin) Document(transport string) (*state.Document, []Issue) {
+	doc := &state.Document{Version: state.DocumentVersion}
+	var issues []Issue
+
+	aliasDomains := make(map[string]PostfixAdminAliasDomain, len(pfa.AliasDomains))
+	for _, ad := range pfa.AliasDomains {
+		aliasDomains[ad.AliasDomain] = ad
+	}
+
+	// Domains
+	domainTypes := make(map[string]string, len(pfa.Domains))
+	for _, d := range pfa.Domains {
+		domain := state.Domain{
+			FQDN:    d.Domain,
+			Type:    "managed",
+			Enabled: ptr(d.Active),
+		}
+
+		if d.BackupMX {
+			issues = append(issues, Issue{d.Domain, "backup MX domain skipped, create it as relayed domain with its recipients"})
+			continue
+		}
+
+		if ad, ok := aliasDomains[d.Domain]; ok {
+			domain.Type = "canonical"
+			domain.Enabled = ptr(d.Active && ad.Active)
+			domain.TargetDomain = ptr(ad.TargetDomain)
+		} else {
+			domain.Transport = ptr(transport)
+			if d.Transport != "" && d.Transport != "virtual" {
+				issues = append(issues, Issue{d.Domain, fmt.Sprintf("transport %q not migrated, using transport %q", d.Transport, transport)})
+			}
+		}
+
+		domainTypes[d.Domain] = domain.Type
+		doc.Domains = append(doc.Domains, domain)
+	}
+
+	for _, ad := range pfa.AliasDomains {
+		if _, ok := domainTypes[ad.AliasDomain]; !ok {
+			issues = append(issues, Issue{ad.AliasDomain, "alias domain skipped, it is missing in the domain table"})
+		} else if domainTypes[ad.TargetDomain] != "managed" {
+			issues = append(issues, Issue{ad.AliasDomain, fmt.Sprintf("alias domain skipped, its target domain %s isn't migrated", ad.TargetDomain)})
+			doc.Domains = slices.DeleteFunc(doc.Domains, func(d state.Domain) bool { return d.FQDN == ad.AliasDomain })
+			delete(domainTypes, ad.AliasDomain)
+		}
+	}
+
+	// Mailboxes
+	mailboxes := make(map[string]bool, len(pfa.Mailboxes))
+	for _, m := range pfa.Mailboxes {
+		email, err := utils.ParseEmailAddress(m.Username)
+		if err != nil {
+			issues = append(issues, Issue{m.Username, "mailbox skipped, invalid email address"})
+			continue
+		}
+		if domainTypes[email.DomainFQDN] != "managed" {
+			issues = append(issues, Issue{m.Username, "mailbox skipped, its domain isn't migrated as managed domain"})
+			continue
+		}
+
+		mailbox := state.Mailbox{
+			Email:     email.String(),
+			Login:     ptr(m.Active),
+			Receiving: ptr(m.Active),
+			Sending:   ptr(m.Active),
+		}
+
+		// PostfixAdmin's quotas are in bytes, mailctl's in MB
+		quota := (m.Quota + 1<<20 - 1) >> 20
+		switch {
+		case quota > math.MaxInt32:
+			issues = append(issues, Issue{m.Username, fmt.Sprintf("quota of %d MB exceeds the maximum, not migrated", quota)})
+		case quota > 0:
+			mailbox.Quota = ptr(int32(quota))
+		}
+
+		switch {
+		case m.Password == "":
+		case passwordhash.IsHash(m.Password):
+			mailbox.PasswordHash = ptr(passwordhash.Normalize(m.Password))
+		default:
+			issues = append(issues, Issue{m.Username, "password isn't a known hash, not migrated"})
+		}
+
+		mailboxes[email.String()] = true
+		doc.Mailboxes = append(doc.Mailboxes, mailbox)
+	}
+
+	// Aliases and catchall targets
+	for _, a := range pfa.Aliases {
+		var targets []string
+		for _, target := range strings.Split(a.Goto, ",") {
+			target = strings.TrimSpace(target)
+			if target == "" || strings.EqualFold(target, a.Address) {
+				continue
+			}
+
+			// Vacation replies are handled by addresses like
+			// "user#example.com@autoreply.example.com"
+			if strings.Contains(target, "#") {
+				continue
+			}
+
+			if _, err := utils.ParseEmailAddress(target); err != nil {
+				issues = append(issues, Issue{a.Address, fmt.Sprintf("target %q skipped, invalid email address", target)})
+				continue
+			}
+			targets = append(targets, target)
+		}
+
+		if strings.HasPrefix(a.Address, "@") {
+			domainFQDN := a.Address[1:]
+			if domainTypes[domainFQDN] != "managed" {
+				issues = append(issues, Issue{a.Address, "catchall skipped, its domain isn't migrated as managed domain"})
+				continue
+			}
+
+			i := slices.IndexFunc(doc.Domains, func(d state.Domain) bool { return d.FQDN == domainFQDN })
+			for _, target := range targets {
+				doc.Domains[i].CatchallTargets = append(doc.Domains[i].CatchallTargets, state.CatchallTarget{
+					Target:     target,
+					Forwarding: ptr(a.Active),
+				})
+			}
+			continue
+		}
+
+		if len(targets) == 0 {
+			continue
+		}
+
+		email, err := utils.ParseEmailAddress(a.Address)
+		if err != nil {
+			issues = append(issues, Issue{a.Address, "alias skipped, invalid email address"})
+			continue
+		}
+		if mailboxes[email.String()] {
+			issues = append(issues, Issue{a.Address, fmt.Sprintf("forwarding of mailbox to %s not migrated", strings.Join(targets, ", "))})
+			continue
+		}
+		if domainTypes[email.DomainFQDN] != "managed" {
+			issues = append(issues, Issue{a.Address, "alias skipped, its domain isn't migrated as managed domain"})
+			continue
+		}
+
+		alias := state.Alias{
+			Email:   email.String(),
+			Enabled: ptr(a.Active),
+		}
+		for _, target := range targets {
+			alias.Targets = append(alias.Targets, state.AliasTarget{Email: target})
+		}
+		doc.Aliases = append(doc.Aliases, alias)
+	}
+
+	for _, v := range pfa.Vacations {
+		issues = append(issues, Issue{v, "vacation message not migrated"})
+	}
+	for _, f := range pfa.Fetchmail {
+		issues = append(issues, Issue{f, "fetchmail configuration not migrated"})
+	}
+
+	return doc, issues
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
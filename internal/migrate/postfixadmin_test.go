@@ -0,0 +1,82 @@
+package migrate
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestPostfixAdminDocument(t *testing.T) {
+	pfa := &PostfixAdmin{
+		Domains: []PostfixAdminDomain{
+			{Domain: "example.com", Transport: "virtual", Active: true},
+			{Domain: "example.net", Active: true},
+			{Domain: "example.org", Transport: "smtp:[backup]", BackupMX: true, Active: true},
+			{Domain: "old.example", Transport: "lmtp:unix:private/dovecot-lmtp", Active: false},
+		},
+		Mailboxes: []PostfixAdminMailbox{
+			{Username: "alice@example.com", Password: "$6$salt$hash", Quota: 1 << 30, Active: true},
+			{Username: "bob@example.com", Password: "{ARGON2ID}$argon2id$v=19$m=65536,t=1,p=4$salt$hash", Active: false},
+			{Username: "carol@example.com", Password: "secret", Quota: 1 << 52, Active: true},
+			{Username: "dave@example.org", Password: "$2y$10$hash", Active: true},
+		},
+		Aliases: []PostfixAdminAlias{
+			{Address: "alice@example.com", Goto: "alice@example.com,alice#example.com@autoreply.example.com", Active: true},
+			{Address: "bob@example.com", Goto: "bob@example.com,bob@gmail.example", Active: true},
+			{Address: "team@example.com", Goto: "alice@example.com, bob@example.com,|/usr/bin/true", Active: false},
+			{Address: "@example.com", Goto: "alice@example.com", Active: true},
+			{Address: "@example.org", Goto: "dave@example.org", Active: true},
+		},
+		AliasDomains: []PostfixAdminAliasDomain{
+			{AliasDomain: "example.net", TargetDomain: "example.com", Active: true},
+		},
+		Vacations: []string{"alice@example.com"},
+	}
+
+	doc, issues := pfa.Document("dovecot")
+
+	want := `{"version":1,` +
+		`"domains":[` +
+		`{"fqdn":"example.com","type":"managed","enabled":true,"transport":"dovecot","catchallTargets":[{"target":"alice@example.com","forwarding":true,"fallbackOnly":true}]},` +
+		`{"fqdn":"example.net","type":"canonical","enabled":true,"targetDomain":"example.com"},` +
+		`{"fqdn":"old.example","type":"managed","enabled":false,"transport":"dovecot"}],` +
+		`"mailboxes":[` +
+		`{"email":"alice@example.com","login":true,"receiving":true,"sending":true,"quota":1024,"passwordHash":"{SHA512-CRYPT}$6$salt$hash"},` +
+		`{"email":"bob@example.com","login":false,"receiving":false,"sending":false,"passwordHash":"{ARGON2ID}$argon2id$v=19$m=65536,t=1,p=4$salt$hash"},` +
+		`{"email":"carol@example.com","login":true,"receiving":true,"sending":true}],` +
+		`"aliases":[` +
+		`{"email":"team@example.com","enabled":false,"targets":[{"email":"alice@example.com","forwarding":true,"sending":false},{"email":"bob@example.com","forwarding":true,"sending":false}]}]}`
+	if err := doc.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+	if err := doc.Normalize(); err != nil {
+		t.Fatalf("Normalize() unexpected error: %v", err)
+	}
+
+	got, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+	if string(got) != want {
+		t.Fatalf("Document() =\n%s\nwant\n%s", got, want)
+	}
+
+	gotIssues := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		gotIssues = append(gotIssues, issue.String())
+	}
+	wantIssues := []string{
+		"example.org: backup MX domain skipped, create it as relayed domain with its recipients",
+		`old.example: transport "lmtp:unix:private/dovecot-lmtp" not migrated, using transport "dovecot"`,
+		"carol@example.com: quota of 4294967296 MB exceeds the maximum, not migrated",
+		"carol@example.com: password isn't a known hash, not migrated",
+		"dave@example.org: mailbox skipped, its domain isn't migrated as managed domain",
+		"bob@example.com: forwarding of mailbox to bob@gmail.example not migrated",
+		`team@example.com: target "|/usr/bin/true" skipped, invalid email address`,
+		"@example.org: catchall skipped, its domain isn't migrated as managed domain",
+		"alice@example.com: vacation message not migrated",
+	}
+	if !slices.Equal(gotIssues, wantIssues) {
+		t.Fatalf("Document() issues =\n%q\nwant\n%q", gotIssues, wantIssues)
+	}
+}
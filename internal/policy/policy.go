@@ -0,0 +1,240 @@
+package policy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+// MaxRequestSize is the maximum size of a request, which is far larger than
+// the requests Postfix sends.
+const MaxRequestSize = 64 * 1024
+
+// Request is a policy delegation request, which consists of attributes like
+// "protocol_state", "sasl_username" and "sender".
+type Request map[string]string
+
+// ReadRequest reads the "name=value" lines of a request up to the empty line,
+// which terminates it.
+func ReadRequest(r *bufio.Reader) (Request, error) {
+	req := make(Request)
+	size := 0
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) && (line != "" || len(req) > 0) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+
+		size += len(line)
+		if size > MaxRequestSize {
+			return nil, fmt.Errorf("request exceeds the maximum size of %d bytes", MaxRequestSize)
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return req, nil
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid attribute %q", line)
+		}
+		req[name] = value
+	}
+}
+
+// WriteAction writes the reply to a request.
+func WriteAction(w io.Writer, action string) error {
+	_, err := fmt.Fprintf(w, "action=%s\n\n", action)
+	return err
+}
+
+// Server answers the requests of Postfix's check_policy_service.
+type Server struct {
+	Policy *Policy
+
+	// OnError is called for failed checks, which are answered with
+	// DEFER_IF_PERMIT
+	OnError func(req Request, err error)
+}
+
+// Serve accepts connections on the listener until the context is done.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	return utils.ServeConns(ctx, l, func(conn net.Conn) {
+		_ = s.ServeConn(conn)
+	})
+}
+
+// ServeConn answers the requests of a single connection until it is closed.
+func (s *Server) ServeConn(conn io.ReadWriter) error {
+	r := bufio.NewReader(conn)
+	for {
+		req, err := ReadRequest(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		action, err := s.Policy.Check(req)
+		if err != nil {
+			if s.OnError != nil {
+				s.OnError(req, err)
+			}
+			action = "DEFER_IF_PERMIT Service temporarily unavailable"
+		}
+
+		if err := WriteAction(conn, action); err != nil {
+			return err
+		}
+	}
+}
+
+// RateLimit allows MaxMessages messages within Period.
+type RateLimit struct {
+	Period      time.Duration
+	MaxMessages int
+}
+
+// Policy decides about the requests.
+type Policy struct {
+	// SenderLogins looks up the SASL logins, which own a sender address, like
+	// smtpd_sender_login_maps
+	SenderLogins func(email utils.EmailAddress) ([]string, error)
+
+	// RateLimits looks up the rate limits of a SASL login (optional)
+	RateLimits func(login string) ([]RateLimit, error)
+
+	// RecipientDelimiter separates address extensions, which are removed from
+	// senders without an owner, like Postfix does (optional)
+	RecipientDelimiter string
+
+	Limiter *Limiter
+	Now     func() time.Time
+}
+
+// Check returns the action for a request:
+//   - REJECT, if the sender has an owner, but the client isn't logged in as
+//     one of its owners, or the client is logged in, but the sender has no
+//     owner (like reject_sender_login_mismatch)
+//   - DEFER, if the login exceeds a rate limit. Messages are counted in the
+//     DATA state, in other states the rate limits are only checked.
+//   - DUNNO otherwise
+func (p *Policy) Check(req Request) (string, error) {
+	login := req["sasl_username"]
+
+	if sender := req["sender"]; sender != "" {
+		owners, err := p.senderOwners(sender)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up owners of %s: %w", sender, err)
+		}
+
+		switch {
+		case len(owners) > 0 && login == "":
+			return fmt.Sprintf("REJECT 5.7.1 <%s>: Sender address rejected: not logged in", sender), nil
+		case login != "" && !slices.ContainsFunc(owners, func(owner string) bool { return strings.EqualFold(owner, login) }):
+			return fmt.Sprintf("REJECT 5.7.1 <%s>: Sender address rejected: not owned by user %s", sender, login), nil
+		}
+	}
+
+	if login != "" && p.RateLimits != nil && p.Limiter != nil {
+		limits, err := p.RateLimits(login)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up rate limits of %s: %w", login, err)
+		}
+
+		now := time.Now()
+		if p.Now != nil {
+			now = p.Now()
+		}
+		if limit, ok := p.Limiter.Allow(login, limits, now, req["protocol_state"] == "DATA"); !ok {
+			return fmt.Sprintf("DEFER 4.7.1 Sending rate limit of %d messages per %s exceeded", limit.MaxMessages, utils.FormatDuration(limit.Period)), nil
+		}
+	}
+
+	return "DUNNO", nil
+}
+
+// senderOwners looks up the owners of a sender address and, if it has none,
+// of the address without extension.
+func (p *Policy) senderOwners(sender string) ([]string, error) {
+	i := strings.LastIndex(sender, "@")
+	if i < 1 || i == len(sender)-1 {
+		return nil, nil
+	}
+	email := utils.EmailAddress{LocalPart: sender[:i], DomainFQDN: sender[i+1:]}
+
+	owners, err := p.SenderLogins(email)
+	if err != nil || len(owners) > 0 || p.RecipientDelimiter == "" {
+		return owners, err
+	}
+
+	if j := strings.IndexAny(email.LocalPart, p.RecipientDelimiter); j > 0 {
+		email.LocalPart = email.LocalPart[:j]
+		return p.SenderLogins(email)
+	}
+	return nil, nil
+}
+
+// Limiter counts the messages of each login in memory.
+type Limiter struct {
+	mu   sync.Mutex
+	sent map[string][]time.Time // Oldest first
+}
+
+func NewLimiter() *Limiter {
+	return &Limiter{sent: make(map[string][]time.Time)}
+}
+
+// Allow reports whether the login is within its rate limits at the time
+// and, if count is set, counts a message then. Otherwise it returns the
+// exceeded rate limit.
+func (l *Limiter) Allow(login string, limits []RateLimit, now time.Time, count bool) (RateLimit, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := strings.ToLower(login)
+	if len(limits) == 0 {
+		delete(l.sent, key)
+		return RateLimit{}, true
+	}
+
+	// Forget the messages, which are out of all periods
+	var maxPeriod time.Duration
+	for _, limit := range limits {
+		maxPeriod = max(maxPeriod, limit.Period)
+	}
+	sent := l.sent[key]
+	i, _ := slices.BinarySearchFunc(sent, now.Add(-maxPeriod), func(t, target time.Time) int { return t.Compare(target) })
+	sent = sent[i:]
+
+	for _, limit := range limits {
+		j, _ := slices.BinarySearchFunc(sent, now.Add(-limit.Period), func(t, target time.Time) int { return t.Compare(target) })
+		if len(sent)-j >= limit.MaxMessages {
+			l.sent[key] = sent
+			return limit, false
+		}
+	}
+
+	if count {
+		sent = append(sent, now)
+	}
+	if len(sent) == 0 {
+		delete(l.sent, key)
+	} else {
+		l.sent[key] = sent
+	}
+	return RateLimit{}, true
+}
@@ -0,0 +1,161 @@
+package policy
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+func TestReadRequest(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("request=smtpd_access_policy\nprotocol_state=RCPT\nsender=alice@example.com\nsasl_username=\n\n"))
+	req, err := ReadRequest(r)
+	if err != nil {
+		t.Fatalf("ReadRequest() unexpected error: %v", err)
+	}
+	if len(req) != 4 || req["protocol_state"] != "RCPT" || req["sender"] != "alice@example.com" || req["sasl_username"] != "" {
+		t.Fatalf("ReadRequest() = %v", req)
+	}
+
+	for _, in := range []string{"sender=alice@example.com\n", "invalid\n\n", strings.Repeat("a=b\n", MaxRequestSize)} {
+		if _, err := ReadRequest(bufio.NewReader(strings.NewReader(in))); err == nil {
+			t.Fatalf("ReadRequest(%.20q) expected error", in)
+		}
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	p := &Policy{
+		SenderLogins: func(email utils.EmailAddress) ([]string, error) {
+			switch email.String() {
+			case "alice@example.com":
+				return []string{"alice@example.com"}, nil
+			case "info@example.com":
+				return []string{"alice@example.com", "newsletter"}, nil
+			case "broken@example.com":
+				return nil, errors.New("connection refused")
+			}
+			return nil, nil
+		},
+		RateLimits: func(login string) ([]RateLimit, error) {
+			if login == "newsletter" {
+				return []RateLimit{{Period: time.Hour, MaxMessages: 2}}, nil
+			}
+			return nil, nil
+		},
+		RecipientDelimiter: "+",
+		Limiter:            NewLimiter(),
+		Now:                func() time.Time { return now },
+	}
+
+	for _, tt := range []struct {
+		req    Request
+		action string
+	}{
+		{Request{"sender": "alice@example.com", "sasl_username": "alice@example.com"}, "DUNNO"},
+		{Request{"sender": "alice+lists@example.com", "sasl_username": "Alice@example.com"}, "DUNNO"},
+		{Request{"sender": "alice@example.com", "sasl_username": "bob@example.com"}, "REJECT 5.7.1 <alice@example.com>: Sender address rejected: not owned by user bob@example.com"},
+		{Request{"sender": "alice@example.com"}, "REJECT 5.7.1 <alice@example.com>: Sender address rejected: not logged in"},
+		{Request{"sender": "someone@example.org", "sasl_username": "alice@example.com"}, "REJECT 5.7.1 <someone@example.org>: Sender address rejected: not owned by user alice@example.com"},
+		{Request{"sender": "someone@example.org"}, "DUNNO"},
+		{Request{"sender": "", "sasl_username": "alice@example.com"}, "DUNNO"},
+		{Request{"protocol_state": "RCPT", "sender": "info@example.com", "sasl_username": "newsletter"}, "DUNNO"},
+		{Request{"protocol_state": "DATA", "sender": "info@example.com", "sasl_username": "newsletter"}, "DUNNO"},
+		{Request{"protocol_state": "DATA", "sender": "info@example.com", "sasl_username": "newsletter"}, "DUNNO"},
+		{Request{"protocol_state": "RCPT", "sender": "info@example.com", "sasl_username": "newsletter"}, "DEFER 4.7.1 Sending rate limit of 2 messages per 1h0m0s exceeded"},
+	} {
+		action, err := p.Check(tt.req)
+		if err != nil {
+			t.Fatalf("Check(%v) unexpected error: %v", tt.req, err)
+		}
+		if action != tt.action {
+			t.Fatalf("Check(%v) = %q, want %q", tt.req, action, tt.action)
+		}
+	}
+
+	if _, err := p.Check(Request{"sender": "broken@example.com"}); err == nil {
+		t.Fatalf("Check() expected error")
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limits := []RateLimit{
+		{Period: time.Minute, MaxMessages: 2},
+		{Period: time.Hour, MaxMessages: 3},
+	}
+
+	for _, tt := range []struct {
+		offset time.Duration
+		ok     bool
+	}{
+		{0, true},
+		{time.Second, true},
+		{2 * time.Second, false}, // 2 per minute
+		{2 * time.Minute, true},
+		{3 * time.Minute, false}, // 3 per hour
+		{61 * time.Minute, true},
+	} {
+		_, ok := l.Allow("newsletter", limits, start.Add(tt.offset), true)
+		if ok != tt.ok {
+			t.Fatalf("Allow() at +%v = %v, want %v", tt.offset, ok, tt.ok)
+		}
+	}
+
+	// Checks without counting don't use up the limit
+	for range 5 {
+		if _, ok := l.Allow("other", limits, start, false); !ok {
+			t.Fatalf("Allow() without counting = false, want true")
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	server := &Server{
+		Policy: &Policy{
+			SenderLogins: func(email utils.EmailAddress) ([]string, error) {
+				if email.LocalPart == "broken" {
+					return nil, errors.New("connection refused")
+				}
+				return []string{"alice@example.com"}, nil
+			},
+		},
+	}
+
+	client, conn := net.Pipe()
+	go func() {
+		_ = server.ServeConn(conn)
+		_ = conn.Close()
+	}()
+	defer client.Close()
+
+	r := bufio.NewReader(client)
+	for _, tt := range []struct {
+		request string
+		reply   string
+	}{
+		{"sender=alice@example.com\nsasl_username=alice@example.com\n\n", "action=DUNNO\n\n"},
+		{"sender=broken@example.com\n\n", "action=DEFER_IF_PERMIT Service temporarily unavailable\n\n"},
+	} {
+		if _, err := client.Write([]byte(tt.request)); err != nil {
+			t.Fatalf("Write() unexpected error: %v", err)
+		}
+		var reply strings.Builder
+		for !strings.HasSuffix(reply.String(), "\n\n") {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatalf("ReadString() unexpected error: %v", err)
+			}
+			reply.WriteString(line)
+		}
+		if reply.String() != tt.reply {
+			t.Fatalf("reply to %q = %q, want %q", tt.request, reply.String(), tt.reply)
+		}
+	}
+}
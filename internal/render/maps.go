@@ -0,0 +1,292 @@
+package render
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+// Map is a rendered lookup table, which is written to a file of the same name.
+type Map struct {
+	Name      string
+	Separator string // Between key and value
+	Secret    bool   // Contains password hashes
+	entries   map[string]string
+}
+
+func newMap(name, separator string, secret bool) *Map {
+	return &Map{Name: name, Separator: separator, Secret: secret, entries: make(map[string]string)}
+}
+
+func (m *Map) Set(key, value string) {
+	m.entries[key] = value
+}
+
+// Get returns the value of the key, if it is set.
+func (m *Map) Get(key string) (string, bool) {
+	value, ok := m.entries[key]
+	return value, ok
+}
+
+// Bytes returns the source of the map sorted by key.
+func (m *Map) Bytes() []byte {
+	keys := make([]string, 0, len(m.entries))
+	for key := range m.entries {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var b bytes.Buffer
+	for _, key := range keys {
+		b.WriteString(key)
+		b.WriteString(m.Separator)
+		b.WriteString(m.entries[key])
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+// Maps renders the Postfix lookup tables and Dovecot passwd-files, which
+// answer every lookup like the postfix.* and dovecot.* functions do. The
+// values are the results of these functions for every key, which can match:
+// the domains, the addresses of all recipients and send grants and "@domain"
+// keys for catchall targets, canonical domains and domain-wide send grants.
+//
+// Send grants with other patterns than "%" can't be expressed in a lookup
+// table and only apply to the known addresses, they are returned as warnings.
+func Maps(r sq.BaseRunner, maxDepth uint32) ([]*Map, []string, error) {
+	var warnings []string
+
+	domains, err := db.Domains(r).List(db.DomainsListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+
+	// Collect the addresses of all recipients
+	var addresses []utils.EmailAddress
+	mailboxes, err := db.Mailboxes(r).List(db.MailboxesListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list mailboxes: %w", err)
+	}
+	for _, m := range mailboxes {
+		addresses = append(addresses, utils.EmailAddress{LocalPart: m.Name, DomainFQDN: m.DomainFQDN})
+	}
+	aliases, err := db.Aliases(r).List(db.AliasesListOptions{IncludeAll: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list aliases: %w", err)
+	}
+	for _, a := range aliases {
+		if a.Name == nil || a.DeletedAt != nil {
+			continue
+		}
+		addresses = append(addresses, utils.EmailAddress{LocalPart: *a.Name, DomainFQDN: a.DomainFQDN})
+	}
+	recipients, err := db.RecipientsRelayed(r).List(db.RecipientsRelayedListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list relayed recipients: %w", err)
+	}
+	for _, rr := range recipients {
+		addresses = append(addresses, utils.EmailAddress{LocalPart: rr.Name, DomainFQDN: rr.DomainFQDN})
+	}
+
+	// Send grants can cover addresses without a recipient
+	sendGrants, err := db.RemotesSendGrants(r).List(db.RemotesSendGrantsListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list send grants: %w", err)
+	}
+	domainWideGrants := make(map[string][]string)
+	senders := slices.Clone(addresses)
+	for _, sg := range sendGrants {
+		switch localPart, literal := unescapeLikePattern(sg.Name); {
+		case sg.Name == "%":
+			domainWideGrants[sg.DomainFQDN] = append(domainWideGrants[sg.DomainFQDN], sg.RemoteName)
+		case literal:
+			senders = append(senders, utils.EmailAddress{LocalPart: localPart, DomainFQDN: sg.DomainFQDN})
+		default:
+			warnings = append(warnings, fmt.Sprintf("send grant %q of remote %s on %s only applies to known addresses, patterns can't be rendered", sg.Name, sg.RemoteName, sg.DomainFQDN))
+		}
+	}
+
+	virtualAliasDomains := newMap("virtual_alias_domains", " ", false)
+	virtualMailboxDomains := newMap("virtual_mailbox_domains", " ", false)
+	relayDomains := newMap("relay_domains", " ", false)
+	virtualAliasMaps := newMap("virtual_alias_maps", " ", false)
+	virtualMailboxMaps := newMap("virtual_mailbox_maps", " ", false)
+	relayRecipientMaps := newMap("relay_recipient_maps", " ", false)
+	transportMaps := newMap("transport_maps", " ", false)
+	senderLoginMaps := newMap("smtpd_sender_login_maps", " ", false)
+	canonicalMaps := newMap("canonical_maps", " ", false)
+	dovecotPasswd := newMap("dovecot_passwd", ":", true)
+	dovecotPasswdRemotes := newMap("dovecot_passwd_remotes", ":", true)
+
+	// Domains
+	for _, d := range domains {
+		for _, lookup := range []struct {
+			m      *Map
+			lookup func(sq.BaseRunner, string) (string, error)
+		}{
+			{virtualAliasDomains, db.PostfixVirtualAliasDomains},
+			{virtualMailboxDomains, db.PostfixVirtualMailboxDomains},
+			{relayDomains, db.PostfixRelayDomains},
+		} {
+			if err := setResult(lookup.m, d.FQDN, func() (string, error) { return lookup.lookup(r, d.FQDN) }); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// Lookups of unknown addresses, which Postfix falls back to with
+		// "@domain" keys
+		wildcard := utils.EmailAddress{DomainFQDN: d.FQDN}
+		if err := setResults(virtualAliasMaps, "@"+d.FQDN, func() ([]string, error) { return db.PostfixVirtualAliasMaps(r, wildcard, maxDepth) }); err != nil {
+			return nil, nil, err
+		}
+		if err := setResult(canonicalMaps, "@"+d.FQDN, func() (string, error) { return db.PostfixCanonicalMaps(r, wildcard) }); err != nil {
+			return nil, nil, err
+		}
+		if grants, ok := domainWideGrants[d.FQDN]; ok {
+			// The function still filters the remotes, e.g. if disabled
+			err := setResults(senderLoginMaps, "@"+d.FQDN, func() ([]string, error) {
+				logins, err := db.PostfixSMTPDSenderLoginMapsRemotes(r, utils.EmailAddress{LocalPart: "%", DomainFQDN: d.FQDN})
+				return slices.DeleteFunc(logins, func(login string) bool { return !slices.Contains(grants, login) }), err
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	// Addresses
+	for _, email := range addresses {
+		key := email.String()
+		if err := setResults(virtualAliasMaps, key, func() ([]string, error) { return db.PostfixVirtualAliasMaps(r, email, maxDepth) }); err != nil {
+			return nil, nil, err
+		}
+		if err := setResult(virtualMailboxMaps, key, func() (string, error) { return db.PostfixVirtualMailboxMaps(r, email) }); err != nil {
+			return nil, nil, err
+		}
+		if err := setResult(relayRecipientMaps, key, func() (string, error) { return db.PostfixRelayRecipientMaps(r, email) }); err != nil {
+			return nil, nil, err
+		}
+		if err := setResult(transportMaps, key, func() (string, error) { return db.PostfixTransportMaps(r, email) }); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, email := range senders {
+		if err := setResults(senderLoginMaps, email.String(), func() ([]string, error) { return db.PostfixSMTPDSenderLoginMaps(r, email, maxDepth) }); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Dovecot passwd-files with the fields user:password:uid:gid:gecos:home:shell:extra_fields
+	for _, m := range mailboxes {
+		email := utils.EmailAddress{LocalPart: m.Name, DomainFQDN: m.DomainFQDN}
+		passdb, err := db.DovecotPassDBMailboxes(r, email)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("failed to look up passdb of %s: %w", email.String(), err)
+		}
+		quota, err := db.DovecotUserDBMailboxes(r, email)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, fmt.Errorf("failed to look up userdb of %s: %w", email.String(), err)
+		}
+
+		extraFields := passdbExtraFields(passdb)
+		if quota != "" {
+			extraFields = append(extraFields, "userdb_quota_storage_size="+quota)
+		}
+		dovecotPasswd.Set(email.String(), passdb.Password.String+"::::::"+strings.Join(extraFields, " "))
+	}
+
+	remotes, err := db.Remotes(r).List(db.RemotesListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	for _, rm := range remotes {
+		passdb, err := db.DovecotPassDBRemotes(r, rm.Name)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("failed to look up passdb of %s: %w", rm.Name, err)
+		}
+		dovecotPasswdRemotes.Set(rm.Name, passdb.Password.String+"::::::"+strings.Join(passdbExtraFields(passdb), " "))
+	}
+
+	return []*Map{
+		virtualAliasDomains,
+		virtualMailboxDomains,
+		relayDomains,
+		virtualAliasMaps,
+		virtualMailboxMaps,
+		relayRecipientMaps,
+		transportMaps,
+		senderLoginMaps,
+		canonicalMaps,
+		dovecotPasswd,
+		dovecotPasswdRemotes,
+	}, warnings, nil
+}
+
+// setResult sets the key to the result of a lookup, which has no result if
+// it returns sql.ErrNoRows.
+func setResult(m *Map, key string, lookup func() (string, error)) error {
+	result, err := lookup()
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to look up %s of %s: %w", m.Name, key, err)
+	}
+	m.Set(key, result)
+	return nil
+}
+
+// setResults sets the key to the comma-separated results of a lookup, if
+// there are any.
+func setResults(m *Map, key string, lookup func() ([]string, error)) error {
+	results, err := lookup()
+	if err != nil {
+		return fmt.Errorf("failed to look up %s of %s: %w", m.Name, key, err)
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	slices.Sort(results)
+	m.Set(key, strings.Join(slices.Compact(results), ", "))
+	return nil
+}
+
+// passdbExtraFields returns the extra fields of a passdb result. The reason
+// is omitted, because extra fields can't contain spaces.
+func passdbExtraFields(passdb db.DovecotPassDBResult) []string {
+	if passdb.NoLogin.Valid && passdb.NoLogin.Bool {
+		return []string{"nologin"}
+	}
+	return nil
+}
+
+// unescapeLikePattern returns the string matched by a LIKE pattern and
+// whether it's matched literally, i.e. without any wildcards.
+func unescapeLikePattern(pattern string) (string, bool) {
+	var b strings.Builder
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+			continue
+		case r == '%' || r == '_':
+			return "", false
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), !escaped
+}
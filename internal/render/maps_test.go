@@ -0,0 +1,36 @@
+package render
+
+import "testing"
+
+func TestMapBytes(t *testing.T) {
+	m := newMap("virtual_alias_maps", " ", false)
+	m.Set("team@example.com", "alice@example.com, bob@example.com")
+	m.Set("@example.com", "alice@example.com")
+
+	want := "@example.com alice@example.com\nteam@example.com alice@example.com, bob@example.com\n"
+	if got := string(m.Bytes()); got != want {
+		t.Fatalf("Bytes() = %q, want %q", got, want)
+	}
+}
+
+func TestUnescapeLikePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+		literal bool
+	}{
+		{"alice", "alice", true},
+		{`first\_last`, "first_last", true},
+		{`100\%`, "100%", true},
+		{"%", "", false},
+		{"news%", "", false},
+		{"a_b", "", false},
+		{`trailing\`, "", false},
+	}
+	for _, tt := range tests {
+		got, literal := unescapeLikePattern(tt.pattern)
+		if literal != tt.literal || (literal && got != tt.want) {
+			t.Errorf("unescapeLikePattern(%q) = %q, %v, want %q, %v", tt.pattern, got, literal, tt.want, tt.literal)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package render
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+// SieveScript is a rendered Sieve script of a mailbox, which is written to
+// "<email>.sieve".
+type SieveScript struct {
+	Email  utils.EmailAddress
+	Source []byte
+}
+
+// SieveScripts renders the vacation scripts of all mailboxes, which have an
+// enabled vacation autoresponder, from the results of the
+// dovecot.vacation_mailboxes function.
+func SieveScripts(r sq.BaseRunner) ([]SieveScript, error) {
+	vacations, err := db.MailboxesVacation(r).List(db.MailboxesVacationListOptions{OnlyEnabled: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vacation autoresponders: %w", err)
+	}
+
+	scripts := make([]SieveScript, 0, len(vacations))
+	for _, v := range vacations {
+		email := utils.EmailAddress{LocalPart: v.Name, DomainFQDN: v.DomainFQDN}
+		vacation, err := db.DovecotVacationMailboxes(r, email)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to look up vacation of %s: %w", email.String(), err)
+		}
+		scripts = append(scripts, SieveScript{Email: email, Source: VacationScript(email, vacation)})
+	}
+
+	return scripts, nil
+}
+
+// VacationScript renders a Sieve script, which answers mails with the
+// vacation extension (RFC 5230) between the start and end date. The dates
+// are compared with the current date of the Sieve interpreter (RFC 5260).
+// Excluded senders are compared with the envelope sender, "@domain" entries
+// with its domain.
+func VacationScript(email utils.EmailAddress, vacation db.DovecotVacationResult) []byte {
+	var tests []string
+	if vacation.StartDate.Valid {
+		tests = append(tests, `currentdate :value "ge" "date" `+sieveString(vacation.StartDate.Time.Format("2006-01-02")))
+	}
+	if vacation.EndDate.Valid {
+		tests = append(tests, `currentdate :value "le" "date" `+sieveString(vacation.EndDate.Time.Format("2006-01-02")))
+	}
+
+	var addresses, domains []string
+	for _, sender := range vacation.ExcludedSenders {
+		if domain, ok := strings.CutPrefix(sender, "@"); ok {
+			domains = append(domains, domain)
+		} else {
+			addresses = append(addresses, sender)
+		}
+	}
+	if len(addresses) > 0 {
+		tests = append(tests, `not envelope :all :is "from" `+sieveStringList(addresses))
+	}
+	if len(domains) > 0 {
+		tests = append(tests, `not envelope :domain :is "from" `+sieveStringList(domains))
+	}
+
+	// Whole days are supported by every implementation, seconds need the
+	// vacation-seconds extension (RFC 6131)
+	extensions := []string{"vacation"}
+	interval := ":days " + strconv.FormatInt(int64(vacation.ReplyInterval/(24*time.Hour)), 10)
+	if vacation.ReplyInterval%(24*time.Hour) != 0 {
+		extensions = append(extensions, "vacation-seconds")
+		interval = ":seconds " + strconv.FormatInt(int64(vacation.ReplyInterval/time.Second), 10)
+	}
+	if vacation.StartDate.Valid || vacation.EndDate.Valid {
+		extensions = append(extensions, "date", "relational")
+	}
+	if len(addresses) > 0 || len(domains) > 0 {
+		extensions = append(extensions, "envelope")
+	}
+
+	action := "vacation " + interval + " :subject " + sieveString(vacation.Subject) + " " + sieveString(vacation.Body) + ";\n"
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Vacation autoresponder of %s, rendered by mailctl\n", email.String())
+	fmt.Fprintf(&b, "require %s;\n\n", sieveStringList(extensions))
+	switch len(tests) {
+	case 0:
+		b.WriteString(action)
+	case 1:
+		fmt.Fprintf(&b, "if %s {\n    %s}\n", tests[0], action)
+	default:
+		fmt.Fprintf(&b, "if allof(\n    %s\n) {\n    %s}\n", strings.Join(tests, ",\n    "), action)
+	}
+	return b.Bytes()
+}
+
+// sieveString quotes a string, escaping backslashes and double quotes.
+func sieveString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+func sieveStringList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, sieveString(value))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
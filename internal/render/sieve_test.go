@@ -0,0 +1,77 @@
+package render
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/sieve"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+func TestVacationScript(t *testing.T) {
+	email := utils.EmailAddress{LocalPart: "alice", DomainFQDN: "example.com"}
+
+	tests := []struct {
+		name     string
+		vacation db.DovecotVacationResult
+		want     string
+	}{
+		{
+			name: "unconditional",
+			vacation: db.DovecotVacationResult{
+				Subject:       "Out of office",
+				Body:          "I'm away.",
+				ReplyInterval: 7 * 24 * time.Hour,
+			},
+			want: "# Vacation autoresponder of alice@example.com, rendered by mailctl\n" +
+				"require [\"vacation\"];\n\n" +
+				"vacation :days 7 :subject \"Out of office\" \"I'm away.\";\n",
+		},
+		{
+			name: "start date and seconds",
+			vacation: db.DovecotVacationResult{
+				Subject:       "Away",
+				Body:          "Back soon.",
+				StartDate:     sql.NullTime{Valid: true, Time: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)},
+				ReplyInterval: 12 * time.Hour,
+			},
+			want: "# Vacation autoresponder of alice@example.com, rendered by mailctl\n" +
+				"require [\"vacation\", \"vacation-seconds\", \"date\", \"relational\"];\n\n" +
+				"if currentdate :value \"ge\" \"date\" \"2026-07-01\" {\n" +
+				"    vacation :seconds 43200 :subject \"Away\" \"Back soon.\";\n}\n",
+		},
+		{
+			name: "dates and excluded senders",
+			vacation: db.DovecotVacationResult{
+				Subject:         `Re: "Vacation"`,
+				Body:            "Line 1\nC:\\path",
+				StartDate:       sql.NullTime{Valid: true, Time: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)},
+				EndDate:         sql.NullTime{Valid: true, Time: time.Date(2026, 7, 31, 0, 0, 0, 0, time.UTC)},
+				ReplyInterval:   24 * time.Hour,
+				ExcludedSenders: []string{"bob@example.org", "@lists.example.com"},
+			},
+			want: "# Vacation autoresponder of alice@example.com, rendered by mailctl\n" +
+				"require [\"vacation\", \"date\", \"relational\", \"envelope\"];\n\n" +
+				"if allof(\n" +
+				"    currentdate :value \"ge\" \"date\" \"2026-07-01\",\n" +
+				"    currentdate :value \"le\" \"date\" \"2026-07-31\",\n" +
+				"    not envelope :all :is \"from\" [\"bob@example.org\"],\n" +
+				"    not envelope :domain :is \"from\" [\"lists.example.com\"]\n" +
+				") {\n" +
+				"    vacation :days 1 :subject \"Re: \\\"Vacation\\\"\" \"Line 1\nC:\\\\path\";\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(VacationScript(email, tt.vacation))
+			if got != tt.want {
+				t.Errorf("VacationScript() = %q, want %q", got, tt.want)
+			}
+			if err := sieve.Validate(got); err != nil {
+				t.Errorf("VacationScript() is invalid: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+/***************************************************************
+ * Table for vacation autoresponders of mailboxes
+ *
+ * Dovecot Pigeonhole answers the mails of a mailbox with the
+ * subject and body between the start and end date (both
+ * inclusive, open if NULL). Each sender is answered at most
+ * once per reply interval. Excluded senders are mail addresses
+ * or "@domain" for all addresses of a domain.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+CREATE TABLE mailboxes_vacation (
+    ID SERIAL PRIMARY KEY,
+    mailbox_id INT NOT NULL UNIQUE
+        REFERENCES mailboxes(ID)
+            ON DELETE CASCADE
+            ON UPDATE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT(true),
+    subject VARCHAR(256) NOT NULL,
+    body TEXT NOT NULL,
+    start_date DATE,
+    end_date DATE,
+    reply_interval INTERVAL NOT NULL DEFAULT INTERVAL '7 days'
+        CHECK (reply_interval > INTERVAL '0'),
+    excluded_senders VARCHAR(256)[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (start_date IS NULL OR end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE TRIGGER trigger_updated_at
+    BEFORE UPDATE ON mailboxes_vacation
+    FOR EACH ROW
+    EXECUTE FUNCTION hook_update_updated_at();
+
+CREATE TRIGGER trigger_audit
+    AFTER INSERT OR UPDATE OR DELETE ON mailboxes_vacation
+    FOR EACH ROW
+    EXECUTE FUNCTION hook_audit();
@@ -0,0 +1,33 @@
+/***************************************************************
+ * Dovecot vacation functions
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+/**
+ * Dovecot: Vacation lookup function for mailboxes.
+ *
+ * Returns the enabled vacation autoresponder of a mailbox regardless of
+ * its dates, which are checked by the Sieve script at delivery time.
+ *
+ * @param $1 domain name
+ * @param $2 user name
+ */
+CREATE FUNCTION dovecot.vacation_mailboxes(VARCHAR(256), VARCHAR(256)) RETURNS TABLE(subject VARCHAR(256), body TEXT, start_date DATE, end_date DATE, reply_interval_seconds BIGINT, excluded_senders VARCHAR(256)[]) AS $$
+    SELECT
+        v.subject,
+        v.body,
+        v.start_date,
+        v.end_date,
+        EXTRACT(EPOCH FROM v.reply_interval)::BIGINT AS reply_interval_seconds,
+        v.excluded_senders
+    FROM mailboxes_vacation v
+    JOIN mailboxes m ON m.ID = v.mailbox_id
+    JOIN domains_managed dm ON dm.ID = m.domain_id
+    WHERE
+        dm.fqdn = $1 AND
+        dm.deleted_at IS NULL AND
+        m.name = $2 AND
+        m.deleted_at IS NULL AND
+        v.enabled IS true
+$$ LANGUAGE SQL SECURITY DEFINER;
@@ -0,0 +1,37 @@
+/***************************************************************
+ * Table for Sieve scripts of mailboxes
+ *
+ * Each mailbox can have multiple named scripts, of which at
+ * most one is active. Dovecot Pigeonhole reads them with its
+ * dict storage (see dovecot.sieve_dict).
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+CREATE TABLE mailboxes_sieve_scripts (
+    ID SERIAL PRIMARY KEY,
+    mailbox_id INT NOT NULL
+        REFERENCES mailboxes(ID)
+            ON DELETE CASCADE
+            ON UPDATE CASCADE,
+    name VARCHAR(256) NOT NULL
+        CHECK (name ~ '^[^/@[:cntrl:]][^/[:cntrl:]]*$'),
+    script TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT(false),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(mailbox_id, name)
+);
+
+-- At most one active script per mailbox
+CREATE UNIQUE INDEX idx_mailboxes_sieve_scripts_active ON mailboxes_sieve_scripts(mailbox_id) WHERE active;
+
+CREATE TRIGGER trigger_updated_at
+    BEFORE UPDATE ON mailboxes_sieve_scripts
+    FOR EACH ROW
+    EXECUTE FUNCTION hook_update_updated_at();
+
+CREATE TRIGGER trigger_audit
+    AFTER INSERT OR UPDATE OR DELETE ON mailboxes_sieve_scripts
+    FOR EACH ROW
+    EXECUTE FUNCTION hook_audit();
@@ -0,0 +1,31 @@
+/***************************************************************
+ * Dovecot Sieve script functions
+ *
+ * The data ID of a script changes with its content, so
+ * Pigeonhole recompiles it after a change.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+/**
+ * Dovecot: Sieve script lookup function for mailboxes.
+ *
+ * @param $1 domain name
+ * @param $2 user name
+ */
+CREATE FUNCTION dovecot.sieve_scripts(VARCHAR(256), VARCHAR(256)) RETURNS TABLE(name VARCHAR(256), data_id TEXT, active BOOLEAN, script TEXT) AS $$
+    SELECT
+        s.name,
+        s.ID || '-' || md5(s.script) AS data_id,
+        s.active,
+        s.script
+    FROM mailboxes_sieve_scripts s
+    JOIN mailboxes m ON m.ID = s.mailbox_id
+    JOIN domains_managed dm ON dm.ID = m.domain_id
+    WHERE
+        dm.fqdn = $1 AND
+        dm.deleted_at IS NULL AND
+        m.name = $2 AND
+        m.deleted_at IS NULL
+    ORDER BY s.name
+$$ LANGUAGE SQL SECURITY DEFINER;
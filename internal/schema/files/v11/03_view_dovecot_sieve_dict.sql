@@ -0,0 +1,28 @@
+/***************************************************************
+ * View for Pigeonhole's dict storage of Sieve scripts
+ *
+ * Dovecot's SQL dict can only query tables, so this view provides
+ * the scripts of all mailboxes by user name, like
+ * dovecot.sieve_scripts does for a single mailbox. The active
+ * script is also available under the reserved name "@active".
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+CREATE VIEW dovecot.sieve_dict AS
+    SELECT
+        m.name || '@' || dm.fqdn AS username,
+        n.name AS script_name,
+        s.ID || '-' || md5(s.script) AS data_id,
+        s.script
+    FROM mailboxes_sieve_scripts s
+    JOIN mailboxes m ON m.ID = s.mailbox_id
+    JOIN domains_managed dm ON dm.ID = m.domain_id
+    CROSS JOIN LATERAL (
+        SELECT s.name
+        UNION ALL
+        SELECT '@active' WHERE s.active
+    ) n
+    WHERE
+        dm.deleted_at IS NULL AND
+        m.deleted_at IS NULL;
@@ -0,0 +1,15 @@
+/***************************************************************
+ * Table for audit logs
+ *
+ * Adds the ID of the top-level transaction, which recorded an
+ * entry. Unlike xmin, it is the same for entries recorded in
+ * savepoints, so all changes of a transaction can be found.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+-- Existing entries keep an unknown transaction
+ALTER TABLE audit.log ADD COLUMN transaction_id XID8;
+ALTER TABLE audit.log ALTER COLUMN transaction_id SET DEFAULT pg_current_xact_id();
+
+CREATE INDEX idx_audit_log_transaction_id ON audit.log(transaction_id);
@@ -0,0 +1,16 @@
+/***************************************************************
+ * Table for sending rate limits of mailboxes
+ *
+ * Adds an ID and records changes in the audit log.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+ALTER TABLE mailboxes_rate_limits DROP CONSTRAINT mailboxes_rate_limits_pkey;
+ALTER TABLE mailboxes_rate_limits ADD COLUMN ID SERIAL PRIMARY KEY;
+ALTER TABLE mailboxes_rate_limits ADD UNIQUE (mailbox_id, period);
+
+CREATE TRIGGER trigger_audit
+    AFTER INSERT OR UPDATE OR DELETE ON mailboxes_rate_limits
+    FOR EACH ROW
+    EXECUTE FUNCTION hook_audit();
@@ -0,0 +1,16 @@
+/***************************************************************
+ * Table for sending rate limits of remotes
+ *
+ * Adds an ID and records changes in the audit log.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+ALTER TABLE remotes_rate_limits DROP CONSTRAINT remotes_rate_limits_pkey;
+ALTER TABLE remotes_rate_limits ADD COLUMN ID SERIAL PRIMARY KEY;
+ALTER TABLE remotes_rate_limits ADD UNIQUE (remote_id, period);
+
+CREATE TRIGGER trigger_audit
+    AFTER INSERT OR UPDATE OR DELETE ON remotes_rate_limits
+    FOR EACH ROW
+    EXECUTE FUNCTION hook_audit();
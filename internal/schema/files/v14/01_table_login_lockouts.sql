@@ -0,0 +1,16 @@
+/***************************************************************
+ * Table for login lockouts
+ *
+ * Adds an ID and records changes in the audit log.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+ALTER TABLE login_lockouts DROP CONSTRAINT login_lockouts_pkey;
+ALTER TABLE login_lockouts ADD COLUMN ID SERIAL PRIMARY KEY;
+ALTER TABLE login_lockouts ADD UNIQUE (object_type);
+
+CREATE TRIGGER trigger_audit
+    AFTER INSERT OR UPDATE OR DELETE ON login_lockouts
+    FOR EACH ROW
+    EXECUTE FUNCTION hook_audit();
@@ -0,0 +1,10 @@
+/***************************************************************
+ * Table for mailboxes
+ *
+ * Adds the time the logins of a mailbox have been unlocked last,
+ * so unlocks are recorded in the audit log.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+ALTER TABLE mailboxes ADD COLUMN logins_unlocked_at TIMESTAMPTZ;
@@ -0,0 +1,10 @@
+/***************************************************************
+ * Table for remotes
+ *
+ * Adds the time the logins of a remote have been unlocked last,
+ * so unlocks are recorded in the audit log.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+ALTER TABLE remotes ADD COLUMN logins_unlocked_at TIMESTAMPTZ;
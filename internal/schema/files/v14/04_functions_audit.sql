@@ -0,0 +1,45 @@
+/***************************************************************
+ * Audit shorthand functions
+ *
+ * Adds labels for login lockouts.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+/**
+ * Audit: Creates a human readable label for an audited record.
+ *
+ * @version 14
+ * @param $1 table name
+ * @param $2 record data (row as JSON)
+ */
+CREATE OR REPLACE FUNCTION audit.object_label(VARCHAR(64), JSONB) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN $1 IN ('domains_managed', 'domains_relayed', 'domains_alias', 'domains_canonical') THEN
+            $2->>'fqdn'
+        WHEN $1 IN ('transports', 'remotes') THEN
+            $2->>'name'
+        WHEN $1 IN ('mailboxes', 'aliases', 'recipients_relayed') THEN
+            ($2->>'name') || '@' || audit.domain_label(($2->>'domain_id')::INT)
+        WHEN $1 = 'aliases_targets_recursive' THEN
+            audit.recipient_label(($2->>'alias_id')::INT) || ' -> ' || audit.recipient_label(($2->>'recipient_id')::INT)
+        WHEN $1 = 'aliases_targets_foreign' THEN
+            audit.recipient_label(($2->>'alias_id')::INT) || ' -> ' || ($2->>'name') || '@' || ($2->>'fqdn')
+        WHEN $1 = 'domains_catchall_targets' THEN
+            '@' || audit.domain_label(($2->>'domain_id')::INT) || ' -> ' || audit.recipient_label(($2->>'recipient_id')::INT)
+        WHEN $1 = 'remotes_send_grants' THEN
+            audit.remote_label(($2->>'remote_id')::INT) || ' -> ' || ($2->>'name') || '@' || audit.domain_label(($2->>'domain_id')::INT)
+        WHEN $1 = 'mailboxes_vacation' THEN
+            audit.recipient_label(($2->>'mailbox_id')::INT) || ' (vacation)'
+        WHEN $1 = 'mailboxes_sieve_scripts' THEN
+            audit.recipient_label(($2->>'mailbox_id')::INT) || ' (sieve: ' || ($2->>'name') || ')'
+        WHEN $1 = 'mailboxes_rate_limits' THEN
+            audit.recipient_label(($2->>'mailbox_id')::INT) || ' (rate limit: ' || ($2->>'max_messages') || ' per ' || ($2->>'period') || ')'
+        WHEN $1 = 'remotes_rate_limits' THEN
+            audit.remote_label(($2->>'remote_id')::INT) || ' (rate limit: ' || ($2->>'max_messages') || ' per ' || ($2->>'period') || ')'
+        WHEN $1 = 'login_lockouts' THEN
+            ($2->>'object_type') || ' (login lockout: ' || ($2->>'max_failures') || ' per ' || ($2->>'period') || ')'
+        ELSE
+            NULL
+    END
+$$ LANGUAGE SQL STABLE;
@@ -0,0 +1,60 @@
+/***************************************************************
+ * Dovecot login failure functions
+ *
+ * `mailctl checkpassword` counts the failed login attempts with
+ * these functions, so it doesn't need read access on the login
+ * attempts when connected as the dovecot user.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+/**
+ * Dovecot: Counts the failed login attempts of a mailbox within an
+ * interval since the last successful one, which aren't unlocked.
+ *
+ * @param $1 domain name
+ * @param $2 user name
+ * @param $3 interval
+ */
+CREATE FUNCTION dovecot.login_failures_mailboxes(VARCHAR(256), VARCHAR(256), INTERVAL) RETURNS BIGINT AS $$
+    SELECT COUNT(*)
+    FROM audit.mailboxes_login_attempts a
+    WHERE
+        a.domain_fqdn = $1 AND
+        a.name = $2 AND
+        a.succeeded IS false AND
+        a.unlocked_at IS NULL AND
+        a.attempted_at > CURRENT_TIMESTAMP - $3 AND
+        a.attempted_at > COALESCE((
+            SELECT MAX(s.attempted_at)
+            FROM audit.mailboxes_login_attempts s
+            WHERE
+                s.domain_fqdn = $1 AND
+                s.name = $2 AND
+                s.succeeded IS true
+        ), '-infinity')
+$$ LANGUAGE SQL STABLE SECURITY DEFINER;
+
+/**
+ * Dovecot: Counts the failed login attempts of a remote within an
+ * interval since the last successful one, which aren't unlocked.
+ *
+ * @param $1 login name
+ * @param $2 interval
+ */
+CREATE FUNCTION dovecot.login_failures_remotes(VARCHAR(256), INTERVAL) RETURNS BIGINT AS $$
+    SELECT COUNT(*)
+    FROM audit.remotes_login_attempts a
+    WHERE
+        a.name = $1 AND
+        a.succeeded IS false AND
+        a.unlocked_at IS NULL AND
+        a.attempted_at > CURRENT_TIMESTAMP - $2 AND
+        a.attempted_at > COALESCE((
+            SELECT MAX(s.attempted_at)
+            FROM audit.remotes_login_attempts s
+            WHERE
+                s.name = $1 AND
+                s.succeeded IS true
+        ), '-infinity')
+$$ LANGUAGE SQL STABLE SECURITY DEFINER;
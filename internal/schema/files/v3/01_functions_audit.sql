@@ -0,0 +1,130 @@
+/***************************************************************
+ * Audit shorthand functions
+ *
+ * These resolve the records referenced by audit log entries to
+ * human readable labels. Records that have been permanently
+ * deleted are resolved using their latest audit log entry.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+/**
+ * Audit: Resolves a domain id to its FQDN.
+ *
+ * @param $1 domain id
+ */
+CREATE FUNCTION audit.domain_label(INT) RETURNS TEXT AS $$
+    SELECT COALESCE(
+        (
+            SELECT d.fqdn
+            FROM domains d
+            WHERE d.ID = $1
+            LIMIT 1
+        ),
+        (
+            SELECT COALESCE(l.data_new, l.data_old)->>'fqdn'
+            FROM audit.log l
+            WHERE
+                l.table_name IN ('domains_managed', 'domains_relayed', 'domains_alias', 'domains_canonical') AND
+                l.record_id = $1
+            ORDER BY l.ID DESC
+            LIMIT 1
+        ),
+        '#' || $1
+    )
+$$ LANGUAGE SQL STABLE;
+
+/**
+ * Audit: Resolves a recipient id to its mail address.
+ *
+ * @param $1 recipient id
+ */
+CREATE FUNCTION audit.recipient_label(INT) RETURNS TEXT AS $$
+    SELECT COALESCE(
+        (
+            SELECT r.name || '@' || audit.domain_label(r.domain_id)
+            FROM recipients r
+            WHERE r.ID = $1
+            LIMIT 1
+        ),
+        (
+            SELECT (COALESCE(l.data_new, l.data_old)->>'name') || '@' || audit.domain_label((COALESCE(l.data_new, l.data_old)->>'domain_id')::INT)
+            FROM audit.log l
+            WHERE
+                l.table_name IN ('mailboxes', 'aliases', 'recipients_relayed') AND
+                l.record_id = $1
+            ORDER BY l.ID DESC
+            LIMIT 1
+        ),
+        '#' || $1
+    )
+$$ LANGUAGE SQL STABLE;
+
+/**
+ * Audit: Resolves a remote id to its name.
+ *
+ * @param $1 remote id
+ */
+CREATE FUNCTION audit.remote_label(INT) RETURNS TEXT AS $$
+    SELECT COALESCE(
+        (
+            SELECT r.name
+            FROM remotes r
+            WHERE r.ID = $1
+        ),
+        (
+            SELECT COALESCE(l.data_new, l.data_old)->>'name'
+            FROM audit.log l
+            WHERE
+                l.table_name = 'remotes' AND
+                l.record_id = $1
+            ORDER BY l.ID DESC
+            LIMIT 1
+        ),
+        '#' || $1
+    )
+$$ LANGUAGE SQL STABLE;
+
+/**
+ * Audit: Creates a human readable label for an audited record.
+ *
+ * @param $1 table name
+ * @param $2 record data (row as JSON)
+ */
+CREATE FUNCTION audit.object_label(VARCHAR(64), JSONB) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN $1 IN ('domains_managed', 'domains_relayed', 'domains_alias', 'domains_canonical') THEN
+            $2->>'fqdn'
+        WHEN $1 IN ('transports', 'remotes') THEN
+            $2->>'name'
+        WHEN $1 IN ('mailboxes', 'aliases', 'recipients_relayed') THEN
+            ($2->>'name') || '@' || audit.domain_label(($2->>'domain_id')::INT)
+        WHEN $1 = 'aliases_targets_recursive' THEN
+            audit.recipient_label(($2->>'alias_id')::INT) || ' -> ' || audit.recipient_label(($2->>'recipient_id')::INT)
+        WHEN $1 = 'aliases_targets_foreign' THEN
+            audit.recipient_label(($2->>'alias_id')::INT) || ' -> ' || ($2->>'name') || '@' || ($2->>'fqdn')
+        WHEN $1 = 'domains_catchall_targets' THEN
+            '@' || audit.domain_label(($2->>'domain_id')::INT) || ' -> ' || audit.recipient_label(($2->>'recipient_id')::INT)
+        WHEN $1 = 'remotes_send_grants' THEN
+            audit.remote_label(($2->>'remote_id')::INT) || ' -> ' || ($2->>'name') || '@' || audit.domain_label(($2->>'domain_id')::INT)
+        ELSE
+            NULL
+    END
+$$ LANGUAGE SQL STABLE;
+
+/**
+ * Audit: Masks secrets in record data.
+ *
+ * Password hashes are replaced by a short fingerprint, so changes remain
+ * visible without disclosing the hash itself.
+ *
+ * @param $1 record data (row as JSON)
+ */
+CREATE FUNCTION audit.redact(JSONB) RETURNS JSONB AS $$
+    SELECT CASE
+        WHEN $1 ? 'password_hash' AND $1->>'password_hash' IS NOT NULL THEN
+            jsonb_set($1, '{password_hash}', to_jsonb('***' || left(md5($1->>'password_hash'), 8)))
+        ELSE
+            $1
+    END
+$$ LANGUAGE SQL IMMUTABLE;
@@ -0,0 +1,10 @@
+/***************************************************************
+ * Table for audit logs
+ *
+ * Adds the reason for a change, which can be provided by the
+ * operator.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+ALTER TABLE audit.log ADD COLUMN reason TEXT;
@@ -0,0 +1,120 @@
+/***************************************************************
+ * Table for audit logs
+ *
+ * Partitions the audit log by month (in UTC), so old entries can
+ * be pruned by dropping whole partitions. Partitions are created
+ * on demand by hook_audit().
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+-- Move the existing table out of the way
+ALTER TABLE audit.log RENAME TO log_unpartitioned;
+ALTER INDEX audit.log_pkey RENAME TO log_unpartitioned_pkey;
+ALTER INDEX audit.idx_audit_log_table_record RENAME TO idx_audit_log_unpartitioned_table_record;
+ALTER INDEX audit.idx_audit_log_changed_at RENAME TO idx_audit_log_unpartitioned_changed_at;
+ALTER INDEX audit.idx_audit_log_table_name RENAME TO idx_audit_log_unpartitioned_table_name;
+ALTER SEQUENCE audit.log_id_seq OWNED BY NONE;
+
+CREATE TABLE audit.log (
+    ID BIGINT NOT NULL DEFAULT nextval('audit.log_id_seq'),
+    table_name VARCHAR(64) NOT NULL,
+    record_id INT NOT NULL,
+    operation VARCHAR(10) NOT NULL,  -- INSERT, UPDATE, DELETE
+    data_old JSONB,
+    data_new JSONB,
+    changed_by VARCHAR(256),  -- Application user/session
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reason TEXT,
+    PRIMARY KEY (ID, changed_at)  -- The partition key must be part of the primary key
+) PARTITION BY RANGE (changed_at);
+
+ALTER SEQUENCE audit.log_id_seq OWNED BY audit.log.ID;
+
+CREATE INDEX idx_audit_log_table_record ON audit.log(table_name, record_id);
+CREATE INDEX idx_audit_log_changed_at ON audit.log(changed_at);
+CREATE INDEX idx_audit_log_table_name ON audit.log(table_name);
+
+/**
+ * Audit: Ensures the monthly partition of the audit log for a point in
+ * time exists and returns its name.
+ *
+ * @param $1 point in time
+ */
+CREATE FUNCTION audit.ensure_log_partition(TIMESTAMPTZ) RETURNS TEXT AS $$
+DECLARE
+    range_start TIMESTAMPTZ := date_trunc('month', $1 AT TIME ZONE 'UTC') AT TIME ZONE 'UTC';
+    partition_name TEXT := 'log_' || to_char($1 AT TIME ZONE 'UTC', 'YYYYMM');
+BEGIN
+    IF to_regclass('audit.' || partition_name) IS NULL THEN
+        BEGIN
+            EXECUTE format(
+                'CREATE TABLE audit.%I PARTITION OF audit.log FOR VALUES FROM (%L) TO (%L)',
+                partition_name,
+                range_start,
+                range_start + INTERVAL '1 month'
+            );
+        EXCEPTION WHEN duplicate_table THEN
+            -- Created concurrently by another transaction
+            NULL;
+        END;
+    END IF;
+    RETURN partition_name;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+/**
+ * Audit: Lists the monthly partitions of the audit log.
+ */
+CREATE FUNCTION audit.log_partitions() RETURNS TABLE(name TEXT, range_start TIMESTAMPTZ, range_end TIMESTAMPTZ) AS $$
+    SELECT
+        p.name,
+        p.range_start,
+        p.range_start + INTERVAL '1 month' AS range_end
+    FROM (
+        SELECT
+            c.relname::TEXT AS name,
+            to_date(substring(c.relname FROM 5), 'YYYYMM')::TIMESTAMP AT TIME ZONE 'UTC' AS range_start
+        FROM pg_inherits i
+        JOIN pg_class c ON c.oid = i.inhrelid
+        WHERE
+            i.inhparent = 'audit.log'::regclass AND
+            c.relname ~ '^log_[0-9]{6}$'
+    ) p
+    ORDER BY p.range_start
+$$ LANGUAGE SQL STABLE;
+
+/**
+ * Audit: Drops a monthly partition of the audit log.
+ *
+ * @param $1 partition name
+ */
+CREATE FUNCTION audit.drop_log_partition(TEXT) RETURNS VOID AS $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM audit.log_partitions() p WHERE p.name = $1) THEN
+        RAISE EXCEPTION 'Audit log partition % does not exist', $1;
+    END IF;
+    EXECUTE format('DROP TABLE audit.%I', $1);
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+-- Move the existing entries into partitions
+DO $$
+DECLARE
+    month TIMESTAMPTZ;
+BEGIN
+    FOR month IN SELECT DISTINCT date_trunc('month', changed_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' FROM audit.log_unpartitioned LOOP
+        PERFORM audit.ensure_log_partition(month);
+    END LOOP;
+
+    -- Create partitions ahead of time
+    PERFORM audit.ensure_log_partition(CURRENT_TIMESTAMP);
+    PERFORM audit.ensure_log_partition(CURRENT_TIMESTAMP + INTERVAL '1 month');
+END;
+$$;
+
+INSERT INTO audit.log (ID, table_name, record_id, operation, data_old, data_new, changed_by, changed_at, reason)
+    SELECT ID, table_name, record_id, operation, data_old, data_new, changed_by, changed_at, reason
+    FROM audit.log_unpartitioned;
+
+DROP TABLE audit.log_unpartitioned;
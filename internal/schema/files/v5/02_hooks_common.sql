@@ -0,0 +1,44 @@
+/***************************************************************
+ * Common hook functions
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+/**
+ * Generic audit trigger function
+ *
+ * This captures changes (INSERT, UPDATE, DELETE) to an audit.log table.
+ * The operator and the reason of a change are read from the transaction
+ * local settings "mailctl.current_user" and "mailctl.reason". Without an
+ * operator the session user is recorded. Partitions of the audit log
+ * are created on demand.
+ *
+ * @version 5
+ */
+CREATE OR REPLACE FUNCTION hook_audit()
+RETURNS TRIGGER AS $$
+DECLARE
+    changed_by TEXT;
+    reason TEXT;
+BEGIN
+    -- Settings are empty (instead of undefined) after a transaction which set them locally
+    changed_by := COALESCE(NULLIF(current_setting('mailctl.current_user', true), ''), session_user);
+    reason := NULLIF(current_setting('mailctl.reason', true), '');
+
+    PERFORM audit.ensure_log_partition(CURRENT_TIMESTAMP);
+
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO audit.log (table_name, record_id, operation, data_old, changed_by, reason)
+        VALUES (TG_TABLE_NAME, OLD.ID, TG_OP, row_to_json(OLD), changed_by, reason);
+        RETURN OLD;
+    ELSIF TG_OP = 'UPDATE' THEN
+        INSERT INTO audit.log (table_name, record_id, operation, data_old, data_new, changed_by, reason)
+        VALUES (TG_TABLE_NAME, NEW.ID, TG_OP, row_to_json(OLD), row_to_json(NEW), changed_by, reason);
+        RETURN NEW;
+    ELSIF TG_OP = 'INSERT' THEN
+        INSERT INTO audit.log (table_name, record_id, operation, data_new, changed_by, reason)
+        VALUES (TG_TABLE_NAME, NEW.ID, TG_OP, row_to_json(NEW), changed_by, reason);
+        RETURN NEW;
+    END IF;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER;
@@ -0,0 +1,33 @@
+/***************************************************************
+ * Table for garbage collection retention periods
+ *
+ * Soft-deleted objects of a type are permanently deleted by
+ * `mailctl gc` once they have been deleted for longer than the
+ * retention period of their type.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+CREATE TABLE gc_retentions (
+    object_type VARCHAR(64) PRIMARY KEY
+        CHECK (object_type IN (
+            'alias-targets',
+            'catchall-targets',
+            'send-grants',
+            'recipients-relayed',
+            'aliases',
+            'mailboxes',
+            'domains',
+            'remotes',
+            'transports'
+        )),
+    retention INTERVAL NOT NULL
+        CHECK (retention > INTERVAL '0'),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER trigger_updated_at
+    BEFORE UPDATE ON gc_retentions
+    FOR EACH ROW
+    EXECUTE FUNCTION hook_update_updated_at();
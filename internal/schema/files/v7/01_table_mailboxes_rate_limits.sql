@@ -0,0 +1,28 @@
+/***************************************************************
+ * Table for sending rate limits of mailboxes
+ *
+ * `mailctl serve policy` defers messages of a SASL login, which
+ * has sent max_messages messages within the period. A mailbox
+ * can have multiple limits with different periods.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+CREATE TABLE mailboxes_rate_limits (
+    mailbox_id INT NOT NULL
+        REFERENCES mailboxes(ID)
+            ON DELETE CASCADE
+            ON UPDATE CASCADE,
+    period INTERVAL NOT NULL
+        CHECK (period > INTERVAL '0'),
+    max_messages INT NOT NULL
+        CHECK (max_messages > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (mailbox_id, period)
+);
+
+CREATE TRIGGER trigger_updated_at
+    BEFORE UPDATE ON mailboxes_rate_limits
+    FOR EACH ROW
+    EXECUTE FUNCTION hook_update_updated_at();
@@ -0,0 +1,27 @@
+/***************************************************************
+ * Table for sending rate limits of remotes
+ *
+ * Same as mailboxes_rate_limits, but for the SASL logins of
+ * remotes.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+CREATE TABLE remotes_rate_limits (
+    remote_id INT NOT NULL
+        REFERENCES remotes(ID)
+            ON DELETE CASCADE
+            ON UPDATE CASCADE,
+    period INTERVAL NOT NULL
+        CHECK (period > INTERVAL '0'),
+    max_messages INT NOT NULL
+        CHECK (max_messages > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (remote_id, period)
+);
+
+CREATE TRIGGER trigger_updated_at
+    BEFORE UPDATE ON remotes_rate_limits
+    FOR EACH ROW
+    EXECUTE FUNCTION hook_update_updated_at();
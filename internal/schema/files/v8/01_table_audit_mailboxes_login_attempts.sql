@@ -0,0 +1,21 @@
+/***************************************************************
+ * Table for login attempts of mailboxes
+ *
+ * `mailctl checkpassword` records every login attempt and
+ * rejects logins of mailboxes with too many recent attempts.
+ * Attempts are recorded by address, so unknown addresses are
+ * limited as well.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+CREATE TABLE audit.mailboxes_login_attempts (
+    domain_fqdn VARCHAR(256) NOT NULL,
+    name VARCHAR(256) NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    failure_reason VARCHAR(256) NOT NULL DEFAULT '',
+    attempted_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_mailboxes_login_attempts_address ON audit.mailboxes_login_attempts(domain_fqdn, name, attempted_at);
+CREATE INDEX idx_audit_mailboxes_login_attempts_attempted_at ON audit.mailboxes_login_attempts(attempted_at);
@@ -0,0 +1,16 @@
+/***************************************************************
+ * Table for login attempts of mailboxes
+ *
+ * Records where the attempts came from and whether failed
+ * attempts have been unlocked, so they don't count towards the
+ * login lockout anymore.
+ *
+ * @version 9
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+ALTER TABLE audit.mailboxes_login_attempts
+    ADD COLUMN remote_ip INET,
+    ADD COLUMN protocol VARCHAR(64) NOT NULL DEFAULT '',  -- e.g. imap, pop3, smtp
+    ADD COLUMN service VARCHAR(64) NOT NULL DEFAULT '',  -- Which recorded the attempt, e.g. dovecot
+    ADD COLUMN unlocked_at TIMESTAMPTZ;
@@ -0,0 +1,19 @@
+/***************************************************************
+ * Table for login attempts of remotes
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+CREATE TABLE audit.remotes_login_attempts (
+    name VARCHAR(256) NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    failure_reason VARCHAR(256) NOT NULL DEFAULT '',
+    remote_ip INET,
+    protocol VARCHAR(64) NOT NULL DEFAULT '',  -- e.g. smtp
+    service VARCHAR(64) NOT NULL DEFAULT '',  -- Which recorded the attempt, e.g. dovecot
+    unlocked_at TIMESTAMPTZ,
+    attempted_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_remotes_login_attempts_name ON audit.remotes_login_attempts(name, attempted_at);
+CREATE INDEX idx_audit_remotes_login_attempts_attempted_at ON audit.remotes_login_attempts(attempted_at);
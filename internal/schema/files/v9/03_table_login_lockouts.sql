@@ -0,0 +1,25 @@
+/***************************************************************
+ * Table for login lockouts
+ *
+ * Logins of an object type are locked, once max_failures failed
+ * attempts have been recorded within the period since the last
+ * successful attempt. Unlocked attempts aren't counted.
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+CREATE TABLE login_lockouts (
+    object_type VARCHAR(64) PRIMARY KEY
+        CHECK (object_type IN ('mailbox', 'remote')),
+    max_failures INT NOT NULL
+        CHECK (max_failures > 0),
+    period INTERVAL NOT NULL
+        CHECK (period > INTERVAL '0'),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER trigger_updated_at
+    BEFORE UPDATE ON login_lockouts
+    FOR EACH ROW
+    EXECUTE FUNCTION hook_update_updated_at();
@@ -0,0 +1,60 @@
+/***************************************************************
+ * Audit login lockout functions
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+/**
+ * Audit: Checks whether the logins of a mailbox are locked.
+ *
+ * @param $1 domain name
+ * @param $2 user name
+ */
+CREATE FUNCTION audit.mailbox_login_locked(VARCHAR(256), VARCHAR(256)) RETURNS BOOLEAN AS $$
+    SELECT COALESCE((
+        SELECT COUNT(*) >= l.max_failures
+        FROM login_lockouts l
+        JOIN audit.mailboxes_login_attempts a ON
+            a.domain_fqdn = $1 AND
+            a.name = $2 AND
+            a.succeeded IS false AND
+            a.unlocked_at IS NULL AND
+            a.attempted_at > CURRENT_TIMESTAMP - l.period AND
+            a.attempted_at > COALESCE((
+                SELECT MAX(s.attempted_at)
+                FROM audit.mailboxes_login_attempts s
+                WHERE
+                    s.domain_fqdn = $1 AND
+                    s.name = $2 AND
+                    s.succeeded IS true
+            ), '-infinity')
+        WHERE l.object_type = 'mailbox'
+        GROUP BY l.max_failures
+    ), false)
+$$ LANGUAGE SQL STABLE;
+
+/**
+ * Audit: Checks whether the logins of a remote are locked.
+ *
+ * @param $1 login name
+ */
+CREATE FUNCTION audit.remote_login_locked(VARCHAR(256)) RETURNS BOOLEAN AS $$
+    SELECT COALESCE((
+        SELECT COUNT(*) >= l.max_failures
+        FROM login_lockouts l
+        JOIN audit.remotes_login_attempts a ON
+            a.name = $1 AND
+            a.succeeded IS false AND
+            a.unlocked_at IS NULL AND
+            a.attempted_at > CURRENT_TIMESTAMP - l.period AND
+            a.attempted_at > COALESCE((
+                SELECT MAX(s.attempted_at)
+                FROM audit.remotes_login_attempts s
+                WHERE
+                    s.name = $1 AND
+                    s.succeeded IS true
+            ), '-infinity')
+        WHERE l.object_type = 'remote'
+        GROUP BY l.max_failures
+    ), false)
+$$ LANGUAGE SQL STABLE;
@@ -0,0 +1,96 @@
+/***************************************************************
+ * Dovecot shorthand functions
+ *
+ * The passdb functions deny logins, which are locked because of
+ * too many failed attempts (see login_lockouts).
+ *
+ * @author Gerolf Vent <dev@gerolfvent.de>
+ ***************************************************************/
+
+/**
+ * Dovecot: PassDB lookup function for mailboxes.
+ *
+ * @version 9
+ * @param $1 domain name
+ * @param $2 user name
+ */
+CREATE OR REPLACE FUNCTION dovecot.passdb_mailboxes(VARCHAR(256), VARCHAR(256)) RETURNS TABLE(password VARCHAR(1024), nologin BOOLEAN, reason VARCHAR(256)) AS $$
+    SELECT
+        CASE
+            WHEN m.login_enabled IS false OR dm.enabled IS false THEN
+                NULL
+            WHEN audit.mailbox_login_locked($1, $2) THEN
+                NULL
+            ELSE
+                dovecot.ensure_password_scheme(m.password_hash)
+        END AS password,
+        CASE
+            WHEN m.login_enabled IS false OR dm.enabled IS false THEN
+                true
+            WHEN audit.mailbox_login_locked($1, $2) THEN
+                true
+            WHEN m.password_hash IS NULL THEN
+                true
+            ELSE
+                NULL
+        END AS nologin,
+        CASE
+            WHEN m.login_enabled IS false OR dm.enabled IS false THEN
+                'Login is disabled.'
+            WHEN audit.mailbox_login_locked($1, $2) THEN
+                'Too many failed login attempts.'
+            WHEN m.password_hash IS NULL THEN
+                'No password set.'
+            ELSE
+                NULL
+        END AS reason
+    FROM mailboxes m
+    JOIN domains_managed dm ON dm.ID = m.domain_id
+    WHERE
+        dm.fqdn = $1 AND
+        dm.deleted_at IS NULL AND
+        m.name = $2 AND
+        m.deleted_at IS NULL
+$$ LANGUAGE SQL SECURITY DEFINER;
+
+/**
+ * Dovecot: PassDB lookup function for remotes.
+ *
+ * @version 9
+ * @param $1 login name
+ */
+CREATE OR REPLACE FUNCTION dovecot.passdb_remotes(VARCHAR(256)) RETURNS TABLE(password VARCHAR(1024), nologin BOOLEAN, reason VARCHAR(256)) AS $$
+    SELECT
+        CASE
+            WHEN r.enabled IS false THEN
+                NULL
+            WHEN audit.remote_login_locked($1) THEN
+                NULL
+            ELSE
+                dovecot.ensure_password_scheme(r.password_hash)
+        END AS password,
+        CASE
+            WHEN r.enabled IS false THEN
+                true
+            WHEN audit.remote_login_locked($1) THEN
+                true
+            WHEN r.password_hash IS NULL THEN
+                true
+            ELSE
+                NULL
+        END AS nologin,
+        CASE
+            WHEN r.enabled IS false THEN
+                'Remote is disabled.'
+            WHEN audit.remote_login_locked($1) THEN
+                'Too many failed login attempts.'
+            WHEN r.password_hash IS NULL THEN
+                'No password set.'
+            ELSE
+                NULL
+        END AS reason
+    FROM remotes r
+    WHERE
+        r.name = $1 AND
+        r.deleted_at IS NULL
+$$ LANGUAGE SQL SECURITY DEFINER;
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gerolf-vent/mailctl/internal/db"
+)
+
+func TestAuditLogRevertUpdate(t *testing.T) {
+	tx, transportID := beginRevertTest(t)
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var port int
+	if err := tx.QueryRow("SELECT port FROM transports WHERE ID = $1", transportID).Scan(&port); err != nil {
+		t.Fatalf("query port: %v", err)
+	}
+
+	if _, err := tx.Exec("UPDATE transports SET port = $1 WHERE ID = $2", port+1, transportID); err != nil {
+		t.Fatalf("update transport: %v", err)
+	}
+	entryID := latestAuditLogID(t, tx, "transports", transportID)
+
+	if err := db.AuditLog(tx).Revert([]int64{entryID}); err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+
+	var got int
+	if err := tx.QueryRow("SELECT port FROM transports WHERE ID = $1", transportID).Scan(&got); err != nil {
+		t.Fatalf("query port: %v", err)
+	}
+	if got != port {
+		t.Fatalf("unexpected port after revert: got %d want %d", got, port)
+	}
+}
+
+func TestAuditLogRevertConflict(t *testing.T) {
+	tx, transportID := beginRevertTest(t)
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec("UPDATE transports SET port = 2525 WHERE ID = $1", transportID); err != nil {
+		t.Fatalf("update transport: %v", err)
+	}
+	firstID := latestAuditLogID(t, tx, "transports", transportID)
+
+	if _, err := tx.Exec("UPDATE transports SET port = 2526 WHERE ID = $1", transportID); err != nil {
+		t.Fatalf("update transport: %v", err)
+	}
+	secondID := latestAuditLogID(t, tx, "transports", transportID)
+
+	// Reverting the first change alone would overwrite the second one
+	var conflictErr *db.AuditLogConflictError
+	if err := db.AuditLog(tx).Revert([]int64{firstID}); !errors.As(err, &conflictErr) {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+
+	// Reverting both changes is fine
+	if err := db.AuditLog(tx).Revert([]int64{firstID, secondID}); err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+}
+
+func beginRevertTest(t *testing.T) (*sql.Tx, int) {
+	t.Helper()
+
+	var transportID int
+	for _, tr := range fixtures.Transports {
+		if !tr.DeletedAt.Valid {
+			transportID = tr.ID
+			break
+		}
+	}
+	if transportID == 0 {
+		t.Fatalf("no active transport found in fixtures")
+	}
+
+	tx, err := testDB.Begin()
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+	return tx, transportID
+}
+
+func latestAuditLogID(t *testing.T, tx *sql.Tx, table string, recordID int) int64 {
+	t.Helper()
+
+	var id int64
+	err := sq.
+		Select("MAX(ID)").
+		From("audit.log").
+		Where(sq.Eq{
+			"table_name": table,
+			"record_id":  recordID,
+		}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx).
+		QueryRow().
+		Scan(&id)
+	if err != nil {
+		t.Fatalf("query audit log: %v", err)
+	}
+	return id
+}
@@ -0,0 +1,172 @@
+package test
+
+import (
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gerolf-vent/mailctl/internal/db"
+)
+
+func TestAuditLogOperatorAndReason(t *testing.T) {
+	var transportID int
+	for _, tr := range fixtures.Transports {
+		if !tr.DeletedAt.Valid {
+			transportID = tr.ID
+			break
+		}
+	}
+	if transportID == 0 {
+		t.Fatalf("no active transport found in fixtures")
+	}
+
+	tx, err := testDB.Begin()
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec("SELECT set_config('mailctl.current_user', $1, true), set_config('mailctl.reason', $2, true)", "alice@test", "testing"); err != nil {
+		t.Fatalf("set session: %v", err)
+	}
+
+	if _, err := tx.Exec("UPDATE transports SET port = port WHERE ID = $1", transportID); err != nil {
+		t.Fatalf("update transport: %v", err)
+	}
+
+	var changedBy, reason string
+	err = sq.
+		Select("changed_by", "reason").
+		From("audit.log").
+		Where(sq.Eq{
+			"table_name": "transports",
+			"record_id":  transportID,
+		}).
+		OrderBy("ID DESC").
+		Limit(1).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx).
+		QueryRow().
+		Scan(&changedBy, &reason)
+	if err != nil {
+		t.Fatalf("query audit log: %v", err)
+	}
+
+	if changedBy != "alice@test" {
+		t.Fatalf("unexpected changed_by: got %q want %q", changedBy, "alice@test")
+	}
+	if reason != "testing" {
+		t.Fatalf("unexpected reason: got %q want %q", reason, "testing")
+	}
+}
+
+func TestAuditLogPartitions(t *testing.T) {
+	tx, err := testDB.Begin()
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var name string
+	if err := tx.QueryRow("SELECT audit.ensure_log_partition('2001-02-03 04:05:06+00')").Scan(&name); err != nil {
+		t.Fatalf("ensure partition: %v", err)
+	}
+	if name != "log_200102" {
+		t.Fatalf("unexpected partition name: got %q want %q", name, "log_200102")
+	}
+
+	if _, err := tx.Exec("INSERT INTO audit.log (table_name, record_id, operation, changed_at) VALUES ('transports', 0, 'INSERT', '2001-02-03 04:05:06+00')"); err != nil {
+		t.Fatalf("insert audit log entry: %v", err)
+	}
+
+	var count int
+	err = sq.
+		Select("COUNT(*)").
+		From("audit.log_partitions() p").
+		Where(sq.Eq{"p.name": name}).
+		Where("p.range_start = '2001-02-01 00:00:00+00' AND p.range_end = '2001-03-01 00:00:00+00'").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx).
+		QueryRow().
+		Scan(&count)
+	if err != nil {
+		t.Fatalf("list partitions: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("partition %s not listed with the expected range", name)
+	}
+
+	if _, err := tx.Exec("SELECT audit.drop_log_partition($1)", name); err != nil {
+		t.Fatalf("drop partition: %v", err)
+	}
+
+	err = sq.
+		Select("COUNT(*)").
+		From("audit.log").
+		Where("changed_at < '2001-03-01 00:00:00+00'").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx).
+		QueryRow().
+		Scan(&count)
+	if err != nil {
+		t.Fatalf("query audit log: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected entries of dropped partition to be gone, got %d", count)
+	}
+
+	if _, err := tx.Exec("SELECT audit.drop_log_partition('transports')"); err == nil {
+		t.Fatalf("expected dropping a non-partition table to fail")
+	}
+}
+
+func TestAuditLogCurrentTransactionSavepoint(t *testing.T) {
+	var transportID int
+	for _, tr := range fixtures.Transports {
+		if !tr.DeletedAt.Valid {
+			transportID = tr.ID
+			break
+		}
+	}
+	if transportID == 0 {
+		t.Fatalf("no active transport found in fixtures")
+	}
+
+	tx, err := testDB.Begin()
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	entries, err := db.AuditLog(tx).List(db.AuditLogListOptions{CurrentTransaction: true})
+	if err != nil {
+		t.Fatalf("list audit log: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected no entries before any change, got %d", len(entries))
+	}
+
+	// Changes in savepoints get a subtransaction id in xmin
+	if _, err := tx.Exec("SAVEPOINT change"); err != nil {
+		t.Fatalf("create savepoint: %v", err)
+	}
+	if _, err := tx.Exec("UPDATE transports SET port = port + 1 WHERE ID = $1", transportID); err != nil {
+		t.Fatalf("update transport: %v", err)
+	}
+	if _, err := tx.Exec("RELEASE SAVEPOINT change"); err != nil {
+		t.Fatalf("release savepoint: %v", err)
+	}
+
+	entries, err = db.AuditLog(tx).List(db.AuditLogListOptions{CurrentTransaction: true})
+	if err != nil {
+		t.Fatalf("list audit log: %v", err)
+	}
+	if len(entries) != 1 || entries[0].TableName != "transports" || entries[0].RecordID != transportID {
+		t.Fatalf("expected the update of transport %d, got %+v", transportID, entries)
+	}
+}
@@ -0,0 +1,150 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gerolf-vent/mailctl/internal/db"
+)
+
+func TestAuditObjectLabelMailboxes(t *testing.T) {
+	for _, m := range fixtures.Mailboxes {
+		fqdn, _, _, _, ok := lookupDomain(m.DomainID)
+		if !ok {
+			t.Fatalf("domain %d not found for mailbox %d", m.DomainID, m.ID)
+		}
+
+		assertAuditObjectLabel(t, "mailboxes", m.ID, fmt.Sprintf("%s@%s", m.Name, fqdn))
+	}
+}
+
+func TestAuditObjectLabelDomains(t *testing.T) {
+	for _, d := range fixtures.DomainsManaged {
+		assertAuditObjectLabel(t, "domains_managed", d.ID, d.FQDN)
+	}
+	for _, d := range fixtures.DomainsRelayed {
+		assertAuditObjectLabel(t, "domains_relayed", d.ID, d.FQDN)
+	}
+}
+
+func TestAuditObjectLabelRateLimits(t *testing.T) {
+	email, ok := lookupActiveMailbox()
+	if !ok {
+		t.Skip("no mailbox")
+	}
+
+	tx, err := testDB.Begin()
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := db.RateLimits(tx).SetMailbox(email, time.Hour, 100); err != nil {
+		t.Fatalf("set rate limit: %v", err)
+	}
+
+	entries, err := db.AuditLog(tx).List(db.AuditLogListOptions{
+		FilterObjects:      []db.AuditLogObject{{Email: &email}},
+		CurrentTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("list audit log: %v", err)
+	}
+	if len(entries) != 1 || entries[0].TableName != "mailboxes_rate_limits" || entries[0].Operation != "INSERT" {
+		t.Fatalf("expected the insert of the rate limit, got %+v", entries)
+	}
+
+	want := email.String() + " (rate limit: 100 per 01:00:00)"
+	if entries[0].Object == nil || *entries[0].Object != want {
+		t.Fatalf("unexpected label: got %v want %q", entries[0].Object, want)
+	}
+}
+
+func TestAuditLoginsUnlock(t *testing.T) {
+	email, ok := lookupActiveMailbox()
+	if !ok {
+		t.Skip("no mailbox")
+	}
+
+	tx, err := testDB.Begin()
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := db.MailboxesLoginAttempts(tx).Unlock(email); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+
+	entries, err := db.AuditLog(tx).List(db.AuditLogListOptions{
+		FilterObjects:      []db.AuditLogObject{{Email: &email}},
+		CurrentTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("list audit log: %v", err)
+	}
+	if len(entries) != 1 || entries[0].TableName != "mailboxes" || entries[0].Operation != "UPDATE" {
+		t.Fatalf("expected the update of the mailbox, got %+v", entries)
+	}
+}
+
+func TestAuditRedact(t *testing.T) {
+	for _, m := range fixtures.Mailboxes {
+		var got string
+		err := sq.
+			Select("COALESCE(audit.redact(data_new)->>'password_hash', '')").
+			From("audit.log").
+			Where(sq.Eq{
+				"table_name": "mailboxes",
+				"record_id":  m.ID,
+				"operation":  "INSERT",
+			}).
+			PlaceholderFormat(sq.Dollar).
+			RunWith(testDB).
+			QueryRow().
+			Scan(&got)
+		if err != nil {
+			t.Fatalf("query redacted mailbox %d: %v", m.ID, err)
+		}
+
+		if !m.PasswordHash.Valid {
+			if got != "" {
+				t.Fatalf("unexpected password hash for mailbox %d: got %q want empty", m.ID, got)
+			}
+		} else if !strings.HasPrefix(got, "***") || strings.Contains(got, m.PasswordHash.String) {
+			t.Fatalf("password hash of mailbox %d not redacted: %q", m.ID, got)
+		}
+	}
+}
+
+func assertAuditObjectLabel(t *testing.T, table string, recordID int, expected string) {
+	t.Helper()
+
+	var got string
+	err := sq.
+		Select("audit.object_label(table_name, COALESCE(data_new, data_old))").
+		From("audit.log").
+		Where(sq.Eq{
+			"table_name": table,
+			"record_id":  recordID,
+			"operation":  "INSERT",
+		}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(testDB).
+		QueryRow().
+		Scan(&got)
+	if err != nil {
+		t.Fatalf("query label for %s %d: %v", table, recordID, err)
+	}
+
+	if got != expected {
+		t.Fatalf("unexpected label for %s %d: got %q want %q", table, recordID, got, expected)
+	}
+}
@@ -0,0 +1,147 @@
+package test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+func TestDovecotPassdbLockout(t *testing.T) {
+	tx, err := testDB.Begin()
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// A mailbox and a remote, which can log in
+	var email utils.EmailAddress
+	for _, m := range fixtures.Mailboxes {
+		d := fixtures.DomainsManaged[m.DomainID]
+		if !d.DeletedAt.Valid && d.Enabled && !m.DeletedAt.Valid && m.LoginEnabled && m.PasswordHash.Valid {
+			email = utils.EmailAddress{LocalPart: m.Name, DomainFQDN: d.FQDN}
+			break
+		}
+	}
+	var remoteName string
+	for _, r := range fixtures.Remotes {
+		if !r.DeletedAt.Valid && r.Enabled && r.Password.Valid {
+			remoteName = r.Name
+			break
+		}
+	}
+	if email.LocalPart == "" || remoteName == "" {
+		t.Skip("no mailbox or remote, which can log in")
+	}
+
+	lockedReason := sql.NullString{String: "Too many failed login attempts.", Valid: true}
+	for _, tt := range []struct {
+		objectType string
+		passdb     func() (db.DovecotPassDBResult, error)
+		record     func(succeeded bool) error
+		unlock     func() error
+	}{
+		{
+			objectType: "mailbox",
+			passdb:     func() (db.DovecotPassDBResult, error) { return db.DovecotPassDBMailboxes(tx, email) },
+			record: func(succeeded bool) error {
+				return db.MailboxesLoginAttempts(tx).Record(email, succeeded, "", db.LoginAttemptsRecordOptions{RemoteIP: "192.0.2.1", Protocol: "imap", Service: "test"})
+			},
+			unlock: func() error { return db.MailboxesLoginAttempts(tx).Unlock(email) },
+		},
+		{
+			objectType: "remote",
+			passdb:     func() (db.DovecotPassDBResult, error) { return db.DovecotPassDBRemotes(tx, remoteName) },
+			record: func(succeeded bool) error {
+				return db.RemotesLoginAttempts(tx).Record(remoteName, succeeded, "", db.LoginAttemptsRecordOptions{Protocol: "smtp", Service: "test"})
+			},
+			unlock: func() error { return db.RemotesLoginAttempts(tx).Unlock(remoteName) },
+		},
+	} {
+		assertLocked := func(step string, want bool) {
+			t.Helper()
+			got, err := tt.passdb()
+			if err != nil {
+				t.Fatalf("%s %s: passdb: %v", tt.objectType, step, err)
+			}
+			locked := got.NoLogin.Valid && got.NoLogin.Bool && got.Reason == lockedReason && !got.Password.Valid
+			if locked != want {
+				t.Fatalf("%s %s: locked = %t, want %t (%+v)", tt.objectType, step, locked, want, got)
+			}
+		}
+
+		for range 3 {
+			if err := tt.record(false); err != nil {
+				t.Fatalf("record failed attempt: %v", err)
+			}
+		}
+		assertLocked("without lockout", false)
+
+		if err := db.LoginLockouts(tx).Set(tt.objectType, 3, time.Hour); err != nil {
+			t.Fatalf("set lockout: %v", err)
+		}
+		assertLocked("after 3 failures", true)
+
+		if err := tt.unlock(); err != nil {
+			t.Fatalf("unlock: %v", err)
+		}
+		assertLocked("after unlock", false)
+
+		for range 2 {
+			if err := tt.record(false); err != nil {
+				t.Fatalf("record failed attempt: %v", err)
+			}
+		}
+		assertLocked("after 2 failures", false)
+	}
+}
+
+func TestLoginAttemptsCheckRateLimit(t *testing.T) {
+	email, ok := lookupActiveMailbox()
+	if !ok {
+		t.Skip("no mailbox")
+	}
+
+	tx, err := testDB.Begin()
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	attempts := db.MailboxesLoginAttempts(tx)
+	assertAllowed := func(step string, want bool) {
+		t.Helper()
+		got, err := attempts.CheckRateLimit(email, 3, time.Hour)
+		if err != nil {
+			t.Fatalf("%s: check rate limit: %v", step, err)
+		}
+		if got != want {
+			t.Fatalf("%s: allowed = %t, want %t", step, got, want)
+		}
+	}
+	recordFailure := func() {
+		t.Helper()
+		if err := attempts.Record(email, false, "", db.LoginAttemptsRecordOptions{Protocol: "imap", Service: "test"}); err != nil {
+			t.Fatalf("record attempt: %v", err)
+		}
+	}
+
+	for range 2 {
+		recordFailure()
+	}
+	assertAllowed("after 2 failures", true)
+
+	recordFailure()
+	assertAllowed("after 3 failures", false)
+
+	if err := attempts.Unlock(email); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	assertAllowed("after unlock", true)
+}
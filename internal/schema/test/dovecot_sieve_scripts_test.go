@@ -0,0 +1,76 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+)
+
+func TestDovecotSieveScripts(t *testing.T) {
+	tx, err := testDB.Begin()
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	email, ok := lookupActiveMailbox()
+	if !ok {
+		t.Skip("no mailbox")
+	}
+
+	repo := db.MailboxesSieveScripts(tx)
+	if err := repo.Put(email, "filters", "keep;"); err != nil {
+		t.Fatalf("put filters: %v", err)
+	}
+	if err := repo.Put(email, "spam", "discard;"); err != nil {
+		t.Fatalf("put spam: %v", err)
+	}
+	if err := repo.Activate(email, "filters"); err != nil {
+		t.Fatalf("activate filters: %v", err)
+	}
+	if err := repo.Activate(email, "spam"); err != nil {
+		t.Fatalf("activate spam: %v", err)
+	}
+
+	scripts, err := db.DovecotSieveScripts(tx, email)
+	if err != nil {
+		t.Fatalf("sieve scripts: %v", err)
+	}
+	if len(scripts) != 2 || scripts[0].Name != "filters" || scripts[0].Active || scripts[1].Name != "spam" || !scripts[1].Active {
+		t.Fatalf("sieve scripts = %+v", scripts)
+	}
+
+	// The data ID changes with the content
+	dataID := scripts[1].DataID
+	if err := repo.Put(email, "spam", "if true { discard; }"); err != nil {
+		t.Fatalf("replace spam: %v", err)
+	}
+
+	// The dict view provides the active script under "@active"
+	var script, activeDataID string
+	err = tx.QueryRow(
+		"SELECT script, data_id FROM dovecot.sieve_dict WHERE username = $1 AND script_name = '@active'",
+		email.String(),
+	).Scan(&script, &activeDataID)
+	if err != nil {
+		t.Fatalf("dict lookup: %v", err)
+	}
+	if script != "if true { discard; }" || activeDataID == dataID {
+		t.Fatalf("dict lookup = %q, %q (previous data id %q)", script, activeDataID, dataID)
+	}
+
+	if err := repo.Deactivate(email); err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+	if err := repo.Deactivate(email); err == nil {
+		t.Fatalf("deactivate without active script succeeded")
+	}
+	if err := repo.Delete(email, "filters"); err != nil {
+		t.Fatalf("delete filters: %v", err)
+	}
+	if err := repo.Activate(email, "filters"); err == nil {
+		t.Fatalf("activate deleted script succeeded")
+	}
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/render"
+)
+
+func TestDovecotVacationMailboxes(t *testing.T) {
+	tx, err := testDB.Begin()
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	email, ok := lookupActiveMailbox()
+	if !ok {
+		t.Skip("no mailbox")
+	}
+
+	if _, err := db.DovecotVacationMailboxes(tx, email); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("vacation before create: err = %v, want sql.ErrNoRows", err)
+	}
+
+	err = db.MailboxesVacation(tx).Create(email, db.MailboxesVacationCreateOptions{
+		Enabled:         true,
+		Subject:         "Out of office",
+		Body:            "I'm away.",
+		StartDate:       sql.NullTime{Valid: true, Time: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)},
+		ExcludedSenders: []string{"@lists.example.com"},
+	})
+	if err != nil {
+		t.Fatalf("create vacation: %v", err)
+	}
+
+	got, err := db.DovecotVacationMailboxes(tx, email)
+	if err != nil {
+		t.Fatalf("vacation: %v", err)
+	}
+	if got.Subject != "Out of office" || got.Body != "I'm away." || got.ReplyInterval != 7*24*time.Hour ||
+		!got.StartDate.Valid || got.StartDate.Time.Format("2006-01-02") != "2026-07-01" || got.EndDate.Valid ||
+		!slices.Equal(got.ExcludedSenders, []string{"@lists.example.com"}) {
+		t.Fatalf("vacation = %+v", got)
+	}
+
+	scripts, err := render.SieveScripts(tx)
+	if err != nil {
+		t.Fatalf("render sieve scripts: %v", err)
+	}
+	if !slices.ContainsFunc(scripts, func(s render.SieveScript) bool { return s.Email == email }) {
+		t.Fatalf("no sieve script of %s", email.String())
+	}
+
+	// Disabled vacations aren't returned
+	enabled := false
+	if err := db.MailboxesVacation(tx).Patch(email, db.MailboxesVacationPatchOptions{Enabled: &enabled}); err != nil {
+		t.Fatalf("patch vacation: %v", err)
+	}
+	if _, err := db.DovecotVacationMailboxes(tx, email); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("disabled vacation: err = %v, want sql.ErrNoRows", err)
+	}
+
+	if err := db.MailboxesVacation(tx).Delete(email); err != nil {
+		t.Fatalf("delete vacation: %v", err)
+	}
+	if vacations, err := db.MailboxesVacation(tx).List(db.MailboxesVacationListOptions{ByEmail: &email}); err != nil || len(vacations) != 0 {
+		t.Fatalf("vacations after delete = %+v, %v", vacations, err)
+	}
+}
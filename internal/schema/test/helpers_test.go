@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"regexp"
 	"strings"
+
+	"github.com/gerolf-vent/mailctl/internal/utils"
 )
 
 func lookupDomain(domainID int) (fqdn string, dType string, enabled bool, deletedAt sql.NullTime, ok bool) {
@@ -22,6 +24,18 @@ func lookupDomain(domainID int) (fqdn string, dType string, enabled bool, delete
 	return "", "", false, sql.NullTime{}, false
 }
 
+// lookupActiveMailbox returns a mailbox, which isn't deleted and whose domain
+// isn't deleted.
+func lookupActiveMailbox() (email utils.EmailAddress, ok bool) {
+	for _, m := range fixtures.Mailboxes {
+		d, ok := fixtures.DomainsManaged[m.DomainID]
+		if ok && !d.DeletedAt.Valid && !m.DeletedAt.Valid {
+			return utils.EmailAddress{LocalPart: m.Name, DomainFQDN: d.FQDN}, true
+		}
+	}
+	return utils.EmailAddress{}, false
+}
+
 func SQLPatternToRegex(pattern string) *regexp.Regexp {
 	var b strings.Builder
 	b.WriteString("^")
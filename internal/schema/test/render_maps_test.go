@@ -0,0 +1,99 @@
+package test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/render"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+func TestRenderMaps(t *testing.T) {
+	const maxDepth = 10
+
+	tx, err := testDB.Begin()
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	rendered, _, err := render.Maps(tx, maxDepth)
+	if err != nil {
+		t.Fatalf("render.Maps() unexpected error: %v", err)
+	}
+	maps := make(map[string]*render.Map, len(rendered))
+	for _, m := range rendered {
+		maps[m.Name] = m
+	}
+
+	// All recipients, including deleted ones, and an unknown name per domain
+	rows, err := tx.Query(`SELECT r.name, d.fqdn FROM recipients r JOIN domains d ON d.ID = r.domain_id
+		UNION SELECT 'render-unknown', fqdn FROM domains`)
+	if err != nil {
+		t.Fatalf("query recipients: %v", err)
+	}
+	var emails []utils.EmailAddress
+	for rows.Next() {
+		var email utils.EmailAddress
+		if err := rows.Scan(&email.LocalPart, &email.DomainFQDN); err != nil {
+			t.Fatalf("scan recipient: %v", err)
+		}
+		emails = append(emails, email)
+	}
+	rows.Close()
+
+	single := func(result string, err error) []string {
+		if err != nil {
+			return nil
+		}
+		return []string{result}
+	}
+
+	for _, email := range emails {
+		for name, lookup := range map[string]func() ([]string, error){
+			"virtual_alias_maps": func() ([]string, error) { return db.PostfixVirtualAliasMaps(tx, email, maxDepth) },
+			"virtual_mailbox_maps": func() ([]string, error) {
+				return single(db.PostfixVirtualMailboxMaps(tx, email)), nil
+			},
+			"relay_recipient_maps": func() ([]string, error) {
+				return single(db.PostfixRelayRecipientMaps(tx, email)), nil
+			},
+			"transport_maps": func() ([]string, error) { return single(db.PostfixTransportMaps(tx, email)), nil },
+			"canonical_maps": func() ([]string, error) { return single(db.PostfixCanonicalMaps(tx, email)), nil },
+		} {
+			want, err := lookup()
+			if err != nil {
+				t.Fatalf("%s(%s) unexpected error: %v", name, email.String(), err)
+			}
+			slices.Sort(want)
+
+			got := lookupRenderedMap(maps[name], email)
+			if !slices.Equal(got, slices.Compact(want)) {
+				t.Errorf("rendered %s of %s = %q, want %q", name, email.String(), got, want)
+			}
+		}
+	}
+}
+
+// lookupRenderedMap looks up an address in the order Postfix uses for hash
+// tables: the address, then "@domain". Results of the form "@domain" are
+// rewritten to the same local part in that domain.
+func lookupRenderedMap(m *render.Map, email utils.EmailAddress) []string {
+	value, ok := m.Get(email.String())
+	if !ok {
+		value, ok = m.Get("@" + email.DomainFQDN)
+	}
+	if !ok {
+		return []string{}
+	}
+
+	results := strings.Split(value, ", ")
+	if strings.HasPrefix(results[0], "@") {
+		results[0] = email.LocalPart + results[0]
+	}
+	return results
+}
@@ -44,7 +44,7 @@ func EnsureUser(db *sql.DB, dbName, userType string, user User) error {
 	case "postfix":
 		grantFn = ensureIntegrationSchemaGrants("postfix")
 	case "dovecot":
-		grantFn = ensureIntegrationSchemaGrants("dovecot")
+		grantFn = ensureDovecotGrants
 	case "stalwart":
 		grantFn = ensureIntegrationSchemaGrants("stalwart")
 	default:
@@ -242,6 +242,23 @@ func ensureManagerGrants(tx *sql.Tx, userName string) error {
 		return err
 	}
 
+	// Allow usage on audit schema (and its functions)
+	if err := ensureIntegrationSchemaGrants("audit")(tx, userName); err != nil {
+		return err
+	}
+
+	// Allow read access on tables in audit schema
+	q = fmt.Sprintf("GRANT SELECT ON ALL TABLES IN SCHEMA audit TO %s", pq.QuoteIdentifier(userName))
+	if _, err := tx.Exec(q); err != nil {
+		return err
+	}
+
+	// Allow recording and unlocking login attempts in audit schema
+	q = fmt.Sprintf("GRANT INSERT, UPDATE (unlocked_at) ON audit.mailboxes_login_attempts, audit.remotes_login_attempts TO %s", pq.QuoteIdentifier(userName))
+	if _, err := tx.Exec(q); err != nil {
+		return err
+	}
+
 	// Allow usage on postfix schema
 	if err := ensureIntegrationSchemaGrants("postfix")(tx, userName); err != nil {
 		return err
@@ -260,6 +277,28 @@ func ensureManagerGrants(tx *sql.Tx, userName string) error {
 	return nil
 }
 
+func ensureDovecotGrants(tx *sql.Tx, userName string) error {
+	// Allow usage on dovecot schema
+	if err := ensureIntegrationSchemaGrants("dovecot")(tx, userName); err != nil {
+		return err
+	}
+
+	// Allow usage on audit schema (without access on its tables)
+	q := fmt.Sprintf("GRANT USAGE ON SCHEMA audit TO %s", pq.QuoteIdentifier(userName))
+	if _, err := tx.Exec(q); err != nil {
+		return err
+	}
+
+	// Allow recording login attempts for `mailctl checkpassword`, which reads
+	// password hashes and failed attempts with the dovecot functions
+	q = fmt.Sprintf("GRANT INSERT ON audit.mailboxes_login_attempts, audit.remotes_login_attempts TO %s", pq.QuoteIdentifier(userName))
+	if _, err := tx.Exec(q); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func ensureIntegrationSchemaGrants(schema string) func(*sql.Tx, string) error {
 	return func(tx *sql.Tx, username string) error {
 		// Allow usage on schema
@@ -274,6 +313,12 @@ func ensureIntegrationSchemaGrants(schema string) func(*sql.Tx, string) error {
 			return err
 		}
 
+		// Allow select on views in schema (e.g. for Dovecot's SQL dict)
+		q = fmt.Sprintf("GRANT SELECT ON ALL TABLES IN SCHEMA %s TO %s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(username))
+		if _, err := tx.Exec(q); err != nil {
+			return err
+		}
+
 		return nil
 	}
 }
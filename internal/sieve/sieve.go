@@ -0,0 +1,434 @@
+package sieve
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxScriptSize is the maximum size of a script, which is the default of
+// Pigeonhole's sieve_max_script_size.
+const MaxScriptSize = 1024 * 1024
+
+// SyntaxError is an error in the syntax of a script at a position.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Validate checks the syntax of a script (RFC 5228, section 8), without
+// checking the commands, tests and extensions themselves. Besides the
+// grammar, it checks that "require" is only used at the beginning of the
+// script and that "elsif" and "else" follow "if".
+func Validate(script string) error {
+	if len(script) > MaxScriptSize {
+		return fmt.Errorf("script exceeds the maximum size of %d bytes", MaxScriptSize)
+	}
+
+	p := &parser{lexer: lexer{src: script, line: 1, column: 1}}
+	if err := p.next(); err != nil {
+		return err
+	}
+	if err := p.commands(true); err != nil {
+		return err
+	}
+	if p.tok.kind != tokenEOF {
+		return p.errorf("unexpected %s", p.tok)
+	}
+	return nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenTag
+	tokenNumber
+	tokenString
+	tokenSpecial // One of ;,{}[]()
+)
+
+type token struct {
+	kind   tokenKind
+	value  string
+	line   int
+	column int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of script"
+	case tokenString:
+		return "string"
+	case tokenNumber:
+		return "number " + t.value
+	case tokenTag:
+		return "tag :" + t.value
+	default:
+		return fmt.Sprintf("%q", t.value)
+	}
+}
+
+type lexer struct {
+	src    string
+	pos    int
+	line   int
+	column int
+}
+
+func (l *lexer) errorf(format string, a ...any) error {
+	return &SyntaxError{Line: l.line, Column: l.column, Msg: fmt.Sprintf(format, a...)}
+}
+
+func (l *lexer) peek(offset int) byte {
+	if l.pos+offset < len(l.src) {
+		return l.src[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *lexer) advance(n int) {
+	for range n {
+		if l.src[l.pos] == '\n' {
+			l.line++
+			l.column = 1
+		} else {
+			l.column++
+		}
+		l.pos++
+	}
+}
+
+// skip skips white space and comments.
+func (l *lexer) skip() error {
+	for l.pos < len(l.src) {
+		switch c := l.peek(0); {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			l.advance(1)
+		case c == '#':
+			for l.pos < len(l.src) && l.peek(0) != '\n' {
+				l.advance(1)
+			}
+		case c == '/' && l.peek(1) == '*':
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return l.errorf("unterminated comment")
+			}
+			l.advance(end + 4)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (l *lexer) next() (token, error) {
+	if err := l.skip(); err != nil {
+		return token{}, err
+	}
+
+	t := token{line: l.line, column: l.column}
+	if l.pos >= len(l.src) {
+		return t, nil
+	}
+
+	start := l.pos
+	switch c := l.peek(0); {
+	case strings.IndexByte(";,{}[]()", c) >= 0:
+		l.advance(1)
+		t.kind = tokenSpecial
+		t.value = string(c)
+
+	case c == '"':
+		l.advance(1)
+		for {
+			switch l.peek(0) {
+			case 0:
+				if l.pos >= len(l.src) {
+					return t, &SyntaxError{Line: t.line, Column: t.column, Msg: "unterminated string"}
+				}
+				l.advance(1)
+			case '\\':
+				if l.pos+1 >= len(l.src) {
+					return t, &SyntaxError{Line: t.line, Column: t.column, Msg: "unterminated string"}
+				}
+				l.advance(2)
+			case '"':
+				l.advance(1)
+				t.kind = tokenString
+				t.value = l.src[start:l.pos]
+				return t, nil
+			default:
+				l.advance(1)
+			}
+		}
+
+	case c == ':':
+		l.advance(1)
+		if !isIdentifierStart(l.peek(0)) {
+			return t, l.errorf("expected tag name after ':'")
+		}
+		for isIdentifierChar(l.peek(0)) {
+			l.advance(1)
+		}
+		t.kind = tokenTag
+		t.value = l.src[start+1 : l.pos]
+
+	case isDigit(c):
+		for isDigit(l.peek(0)) {
+			l.advance(1)
+		}
+		switch l.peek(0) {
+		case 'K', 'k', 'M', 'm', 'G', 'g':
+			l.advance(1)
+		}
+		if isIdentifierChar(l.peek(0)) {
+			return t, l.errorf("invalid number")
+		}
+		t.kind = tokenNumber
+		t.value = l.src[start:l.pos]
+
+	case isIdentifierStart(c):
+		for isIdentifierChar(l.peek(0)) {
+			l.advance(1)
+		}
+		t.kind = tokenIdentifier
+		t.value = l.src[start:l.pos]
+
+		// Multi-line strings start with "text:" followed by the end of line
+		if strings.EqualFold(t.value, "text") && l.peek(0) == ':' {
+			l.advance(1)
+			return l.multiLine(t)
+		}
+
+	default:
+		return t, l.errorf("unexpected character %q", c)
+	}
+
+	return t, nil
+}
+
+// multiLine reads the rest of a multi-line string after "text:", which ends
+// with a line containing only ".".
+func (l *lexer) multiLine(t token) (token, error) {
+	for l.peek(0) == ' ' || l.peek(0) == '\t' {
+		l.advance(1)
+	}
+	switch {
+	case l.peek(0) == '#':
+		for l.pos < len(l.src) && l.peek(0) != '\n' {
+			l.advance(1)
+		}
+	case l.peek(0) == '\r' && l.peek(1) == '\n':
+	case l.peek(0) == '\n':
+	default:
+		return t, l.errorf("expected end of line after 'text:'")
+	}
+	if l.pos >= len(l.src) {
+		return t, &SyntaxError{Line: t.line, Column: t.column, Msg: "unterminated multi-line string"}
+	}
+	l.advance(1 + strings.IndexByte(l.src[l.pos:], '\n'))
+
+	for l.pos < len(l.src) {
+		end := strings.IndexByte(l.src[l.pos:], '\n')
+		if end < 0 {
+			break
+		}
+		line := strings.TrimSuffix(l.src[l.pos:l.pos+end], "\r")
+		l.advance(end + 1)
+		if line == "." {
+			t.kind = tokenString
+			return t, nil
+		}
+	}
+	return t, &SyntaxError{Line: t.line, Column: t.column, Msg: "unterminated multi-line string"}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentifierStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentifierChar(c byte) bool {
+	return isIdentifierStart(c) || isDigit(c)
+}
+
+type parser struct {
+	lexer lexer
+	tok   token
+}
+
+func (p *parser) next() error {
+	var err error
+	p.tok, err = p.lexer.next()
+	return err
+}
+
+func (p *parser) errorf(format string, a ...any) error {
+	return &SyntaxError{Line: p.tok.line, Column: p.tok.column, Msg: fmt.Sprintf(format, a...)}
+}
+
+func (p *parser) isSpecial(value string) bool {
+	return p.tok.kind == tokenSpecial && p.tok.value == value
+}
+
+func (p *parser) expectSpecial(value string) error {
+	if !p.isSpecial(value) {
+		return p.errorf("expected %q, got %s", value, p.tok)
+	}
+	return p.next()
+}
+
+// commands parses commands up to the end of the script or block.
+func (p *parser) commands(topLevel bool) error {
+	requireAllowed := topLevel
+	previous := ""
+	for p.tok.kind == tokenIdentifier {
+		name := strings.ToLower(p.tok.value)
+		switch name {
+		case "require":
+			if !requireAllowed {
+				return p.errorf("require must be used before any other command")
+			}
+		case "elsif", "else":
+			if previous != "if" && previous != "elsif" {
+				return p.errorf("%s without preceding if", name)
+			}
+		}
+		if name != "require" {
+			requireAllowed = false
+		}
+
+		if err := p.command(name); err != nil {
+			return err
+		}
+		previous = name
+	}
+	return nil
+}
+
+// command parses a command: identifier arguments (";" / block).
+func (p *parser) command(name string) error {
+	if err := p.next(); err != nil {
+		return err
+	}
+
+	hasTest, err := p.arguments()
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "if", "elsif":
+		if !hasTest {
+			return p.errorf("%s requires a test", name)
+		}
+		if !p.isSpecial("{") {
+			return p.errorf("%s requires a block", name)
+		}
+	case "else":
+		if hasTest {
+			return p.errorf("else doesn't take a test")
+		}
+		if !p.isSpecial("{") {
+			return p.errorf("else requires a block")
+		}
+	}
+
+	if p.isSpecial("{") {
+		if err := p.next(); err != nil {
+			return err
+		}
+		if err := p.commands(false); err != nil {
+			return err
+		}
+		return p.expectSpecial("}")
+	}
+	if !p.isSpecial(";") {
+		return p.errorf("expected \";\" or \"{\", got %s", p.tok)
+	}
+	return p.next()
+}
+
+// arguments parses: *argument [ test / test-list ]. It reports whether there
+// is a test or test-list.
+func (p *parser) arguments() (bool, error) {
+	for {
+		switch {
+		case p.tok.kind == tokenTag || p.tok.kind == tokenNumber || p.tok.kind == tokenString:
+			if err := p.next(); err != nil {
+				return false, err
+			}
+		case p.isSpecial("["):
+			if err := p.stringList(); err != nil {
+				return false, err
+			}
+		case p.tok.kind == tokenIdentifier:
+			return true, p.test()
+		case p.isSpecial("("):
+			return true, p.testList()
+		default:
+			return false, nil
+		}
+	}
+}
+
+// stringList parses: "[" string *("," string) "]".
+func (p *parser) stringList() error {
+	if err := p.expectSpecial("["); err != nil {
+		return err
+	}
+	for {
+		if p.tok.kind != tokenString {
+			return p.errorf("expected string, got %s", p.tok)
+		}
+		if err := p.next(); err != nil {
+			return err
+		}
+		if !p.isSpecial(",") {
+			return p.expectSpecial("]")
+		}
+		if err := p.next(); err != nil {
+			return err
+		}
+	}
+}
+
+// test parses: identifier arguments.
+func (p *parser) test() error {
+	if p.tok.kind != tokenIdentifier {
+		return p.errorf("expected test, got %s", p.tok)
+	}
+	if err := p.next(); err != nil {
+		return err
+	}
+	_, err := p.arguments()
+	return err
+}
+
+// testList parses: "(" test *("," test) ")".
+func (p *parser) testList() error {
+	if err := p.expectSpecial("("); err != nil {
+		return err
+	}
+	for {
+		if err := p.test(); err != nil {
+			return err
+		}
+		if !p.isSpecial(",") {
+			return p.expectSpecial(")")
+		}
+		if err := p.next(); err != nil {
+			return err
+		}
+	}
+}
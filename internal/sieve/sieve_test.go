@@ -0,0 +1,67 @@
+package sieve
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []string{
+		"",
+		"keep;",
+		"# Comment\n/* Block\n comment */ discard;",
+		`require ["fileinto", "envelope"];
+if header :contains "subject" "[spam]" {
+    fileinto "Junk";
+    stop;
+} elsif allof(envelope :domain :is "from" "example.com", not size :over 100K) {
+    fileinto "Example";
+} else {
+    keep;
+}`,
+		"require \"vacation\";\nvacation :days 7 :subject \"Away\" text:\nI'm away.\n..with a dot\n.\n;",
+		"require \"vacation\";\r\nvacation text: # Comment\r\nLine\r\n.\r\n;\r\n",
+		`if true { if false { } }`,
+		`redirect "a\"b\\c@example.com";`,
+	}
+	for _, script := range valid {
+		if err := Validate(script); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", script, err)
+		}
+	}
+
+	invalid := []struct {
+		script string
+		line   int
+		column int
+	}{
+		{"keep", 1, 5},
+		{"keep;\nrequire \"fileinto\";", 2, 1},
+		{"if true keep;", 1, 13},
+		{"if { keep; }", 1, 4},
+		{"else { keep; }", 1, 1},
+		{"if true { keep; } stop; else { keep; }", 1, 25},
+		{"else", 1, 1},
+		{"fileinto \"Junk;", 1, 10},
+		{"fileinto [\"a\" \"b\"];", 1, 15},
+		{"fileinto [];", 1, 11},
+		{"if anyof() { }", 1, 10},
+		{"if true {\n keep;\n", 3, 1},
+		{"/* unterminated", 1, 1},
+		{"vacation text:\nbody\n", 1, 10},
+		{"discard 12X;", 1, 11},
+		{"}", 1, 1},
+		{"keep; $", 1, 7},
+	}
+	for _, tt := range invalid {
+		err := Validate(tt.script)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Validate(%q) = %v, want syntax error", tt.script, err)
+			continue
+		}
+		if syntaxErr.Line != tt.line || syntaxErr.Column != tt.column {
+			t.Errorf("Validate(%q) = %v, want error at line %d, column %d", tt.script, err, tt.line, tt.column)
+		}
+	}
+}
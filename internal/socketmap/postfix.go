@@ -0,0 +1,74 @@
+package socketmap
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+// PostfixMaps returns a map for each postfix.* function, named like in the
+// Postfix integration. Keys of address maps without local part (e.g.
+// "@example.com" or "example.com") aren't found, like with the SQL
+// configuration, where Postfix skips queries with an empty %u.
+func PostfixMaps(r sq.BaseRunner, maxDepth uint32) map[string]Lookup {
+	return map[string]Lookup{
+		"transport_maps":        addressLookup(single(func(email utils.EmailAddress) (string, error) { return db.PostfixTransportMaps(r, email) })),
+		"canonical_maps":        addressLookup(single(func(email utils.EmailAddress) (string, error) { return db.PostfixCanonicalMaps(r, email) })),
+		"virtual_alias_domains": single(func(fqdn string) (string, error) { return db.PostfixVirtualAliasDomains(r, fqdn) }),
+		"virtual_alias_maps": addressLookup(multiple(func(email utils.EmailAddress) ([]string, error) {
+			return db.PostfixVirtualAliasMaps(r, email, maxDepth)
+		})),
+		"virtual_mailbox_domains": single(func(fqdn string) (string, error) { return db.PostfixVirtualMailboxDomains(r, fqdn) }),
+		"virtual_mailbox_maps":    addressLookup(single(func(email utils.EmailAddress) (string, error) { return db.PostfixVirtualMailboxMaps(r, email) })),
+		"relay_domains":           single(func(fqdn string) (string, error) { return db.PostfixRelayDomains(r, fqdn) }),
+		"relay_recipient_maps":    addressLookup(single(func(email utils.EmailAddress) (string, error) { return db.PostfixRelayRecipientMaps(r, email) })),
+		"smtpd_sender_login_maps": addressLookup(multiple(func(email utils.EmailAddress) ([]string, error) {
+			return db.PostfixSMTPDSenderLoginMaps(r, email, maxDepth)
+		})),
+		"smtpd_sender_login_maps_mailboxes": addressLookup(multiple(func(email utils.EmailAddress) ([]string, error) {
+			return db.PostfixSMTPDSenderLoginMapsMailboxes(r, email, maxDepth)
+		})),
+		"smtpd_sender_login_maps_remotes": addressLookup(multiple(func(email utils.EmailAddress) ([]string, error) {
+			return db.PostfixSMTPDSenderLoginMapsRemotes(r, email)
+		})),
+	}
+}
+
+// addressLookup turns a lookup by address into a lookup by key.
+func addressLookup(lookup func(utils.EmailAddress) (string, bool, error)) Lookup {
+	return func(key string) (string, bool, error) {
+		i := strings.LastIndex(key, "@")
+		if i < 1 || i == len(key)-1 {
+			return "", false, nil
+		}
+		return lookup(utils.EmailAddress{LocalPart: key[:i], DomainFQDN: key[i+1:]})
+	}
+}
+
+// single wraps a lookup, which returns sql.ErrNoRows if the key isn't found.
+func single[K any](lookup func(K) (string, error)) func(K) (string, bool, error) {
+	return func(key K) (string, bool, error) {
+		result, err := lookup(key)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		} else if err != nil {
+			return "", false, err
+		}
+		return result, true, nil
+	}
+}
+
+// multiple wraps a lookup of multiple results, which are joined by commas.
+func multiple[K any](lookup func(K) ([]string, error)) func(K) (string, bool, error) {
+	return func(key K) (string, bool, error) {
+		results, err := lookup(key)
+		if err != nil || len(results) == 0 {
+			return "", false, err
+		}
+		return strings.Join(results, ","), true, nil
+	}
+}
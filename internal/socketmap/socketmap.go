@@ -0,0 +1,180 @@
+package socketmap
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+// MaxNetstringLength is the maximum length of requests and replies, which is
+// the default of Postfix's socketmap_max_reply_size.
+const MaxNetstringLength = 100000
+
+// Lookup looks up a key of a map. It returns false, if the key isn't found.
+type Lookup func(key string) (string, bool, error)
+
+// Server answers the requests of Postfix's socketmap client
+// ("socketmap:unix:/path:name" or "socketmap:inet:host:port:name").
+type Server struct {
+	Maps  map[string]Lookup
+	Cache *Cache // Optional
+
+	// OnError is called for failed lookups, which are answered with TEMP
+	OnError func(name, key string, err error)
+}
+
+// Serve accepts connections on the listener until the context is done.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	return utils.ServeConns(ctx, l, func(conn net.Conn) {
+		_ = s.ServeConn(conn)
+	})
+}
+
+// ServeConn answers the requests of a single connection until it is closed.
+func (s *Server) ServeConn(conn io.ReadWriter) error {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		request, err := ReadNetstring(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := WriteNetstring(w, s.reply(request)); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+// reply answers a request of the form "<name> <key>".
+func (s *Server) reply(request string) string {
+	name, key, ok := strings.Cut(request, " ")
+	if !ok {
+		return "PERM invalid request"
+	}
+	lookup, ok := s.Maps[name]
+	if !ok {
+		return "PERM unknown map " + name
+	}
+
+	if s.Cache != nil {
+		if reply, ok := s.Cache.Get(name, key); ok {
+			return reply
+		}
+	}
+
+	result, found, err := lookup(key)
+	if err != nil {
+		if s.OnError != nil {
+			s.OnError(name, key, err)
+		}
+		return "TEMP lookup failed"
+	}
+
+	reply := "NOTFOUND "
+	if found {
+		reply = "OK " + result
+	}
+	if s.Cache != nil {
+		s.Cache.Set(name, key, reply)
+	}
+	return reply
+}
+
+// ReadNetstring reads a netstring ("<length>:<data>,").
+func ReadNetstring(r *bufio.Reader) (string, error) {
+	prefix, err := r.ReadString(':')
+	if err != nil {
+		if errors.Is(err, io.EOF) && prefix != "" {
+			return "", io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+
+	length, err := strconv.Atoi(prefix[:len(prefix)-1])
+	if err != nil || length < 0 {
+		return "", fmt.Errorf("invalid netstring length %q", prefix[:len(prefix)-1])
+	}
+	if length > MaxNetstringLength {
+		return "", fmt.Errorf("netstring length %d exceeds the maximum of %d", length, MaxNetstringLength)
+	}
+
+	data := make([]byte, length+1)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return "", io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	if data[length] != ',' {
+		return "", fmt.Errorf("netstring isn't terminated by a comma")
+	}
+	return string(data[:length]), nil
+}
+
+// WriteNetstring writes a netstring ("<length>:<data>,").
+func WriteNetstring(w io.Writer, data string) error {
+	_, err := fmt.Fprintf(w, "%d:%s,", len(data), data)
+	return err
+}
+
+// Cache holds the replies of lookups for a fixed time.
+type Cache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	reply   string
+	expires time.Time
+}
+
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+func (c *Cache) Get(name, key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[name+" "+key]
+	if !ok || time.Now().After(entry.expires) {
+		return "", false
+	}
+	return entry.reply, true
+}
+
+func (c *Cache) Set(name, key, reply string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[name+" "+key] = cacheEntry{reply: reply, expires: time.Now().Add(c.ttl)}
+}
+
+// Prune removes the expired entries.
+func (c *Cache) Prune() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package socketmap
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+func TestNetstring(t *testing.T) {
+	var b strings.Builder
+	if err := WriteNetstring(&b, "transport_maps alice@example.com"); err != nil {
+		t.Fatalf("WriteNetstring() unexpected error: %v", err)
+	}
+	if got, want := b.String(), "32:transport_maps alice@example.com,"; got != want {
+		t.Fatalf("WriteNetstring() = %q, want %q", got, want)
+	}
+
+	r := bufio.NewReader(strings.NewReader(b.String() + "0:,"))
+	for _, want := range []string{"transport_maps alice@example.com", ""} {
+		got, err := ReadNetstring(r)
+		if err != nil {
+			t.Fatalf("ReadNetstring() unexpected error: %v", err)
+		}
+		if got != want {
+			t.Fatalf("ReadNetstring() = %q, want %q", got, want)
+		}
+	}
+
+	for _, in := range []string{"3:abc;", "x:abc,", "-1:,", "5:abc", "5", "1000001:"} {
+		if _, err := ReadNetstring(bufio.NewReader(strings.NewReader(in))); err == nil {
+			t.Fatalf("ReadNetstring(%q) expected error", in)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	lookups := 0
+	server := &Server{
+		Maps: map[string]Lookup{
+			"virtual_alias_maps": func(key string) (string, bool, error) {
+				lookups++
+				switch key {
+				case "team@example.com":
+					return "alice@example.com,bob@example.com", true, nil
+				case "broken@example.com":
+					return "", false, errors.New("connection refused")
+				}
+				return "", false, nil
+			},
+		},
+		Cache: NewCache(time.Minute),
+	}
+
+	client, conn := net.Pipe()
+	go func() {
+		_ = server.ServeConn(conn)
+		_ = conn.Close()
+	}()
+	defer client.Close()
+
+	r := bufio.NewReader(client)
+	for _, tt := range []struct {
+		request string
+		reply   string
+	}{
+		{"virtual_alias_maps team@example.com", "OK alice@example.com,bob@example.com"},
+		{"virtual_alias_maps team@example.com", "OK alice@example.com,bob@example.com"},
+		{"virtual_alias_maps nobody@example.com", "NOTFOUND "},
+		{"virtual_alias_maps broken@example.com", "TEMP lookup failed"},
+		{"virtual_alias_maps broken@example.com", "TEMP lookup failed"},
+		{"transport_maps team@example.com", "PERM unknown map transport_maps"},
+		{"invalid", "PERM invalid request"},
+	} {
+		if err := WriteNetstring(client, tt.request); err != nil {
+			t.Fatalf("WriteNetstring() unexpected error: %v", err)
+		}
+		reply, err := ReadNetstring(r)
+		if err != nil {
+			t.Fatalf("ReadNetstring() unexpected error: %v", err)
+		}
+		if reply != tt.reply {
+			t.Fatalf("reply to %q = %q, want %q", tt.request, reply, tt.reply)
+		}
+	}
+
+	// Found and missing keys are cached, failed lookups aren't
+	if lookups != 4 {
+		t.Fatalf("lookups = %d, want 4", lookups)
+	}
+}
+
+func TestAddressLookup(t *testing.T) {
+	lookup := addressLookup(func(email utils.EmailAddress) (string, bool, error) {
+		return email.LocalPart + " " + email.DomainFQDN, true, nil
+	})
+
+	for key, want := range map[string]string{
+		"alice@example.com":   "alice example.com",
+		"\"a@b\"@example.com": "\"a@b\" example.com",
+		"@example.com":        "",
+		"example.com":         "",
+		"alice@":              "",
+	} {
+		got, found, err := lookup(key)
+		if err != nil {
+			t.Fatalf("lookup(%q) unexpected error: %v", key, err)
+		}
+		if found != (want != "") || got != want {
+			t.Fatalf("lookup(%q) = %q, %v, want %q", key, got, found, want)
+		}
+	}
+}
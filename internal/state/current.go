@@ -0,0 +1,168 @@
+package state
+
+import (
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gerolf-vent/mailctl/internal/db"
+)
+
+type CurrentOptions struct {
+	IncludeDeleted        bool // Include soft-deleted objects (with DeletedAt set)
+	IncludePasswordHashes bool
+}
+
+// Current reads the current state of all mail system objects through the
+// repositories.
+func Current(r sq.BaseRunner, options CurrentOptions) (*Document, error) {
+	doc := &Document{Version: DocumentVersion}
+
+	// Transports
+	transports, err := db.Transports(r).List(db.TransportsListOptions{IncludeAll: options.IncludeDeleted})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transports: %w", err)
+	}
+	for _, t := range transports {
+		doc.Transports = append(doc.Transports, Transport{
+			Name:      t.Name,
+			Method:    t.Method,
+			Host:      t.Host,
+			Port:      t.Port,
+			MXLookup:  t.MXLookup,
+			DeletedAt: t.DeletedAt,
+		})
+	}
+
+	// Remotes and their send grants
+	remotes, err := db.Remotes(r).List(db.RemotesListOptions{
+		IncludeAll:          options.IncludeDeleted,
+		IncludePasswordHash: options.IncludePasswordHashes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	sendGrants, err := db.RemotesSendGrants(r).List(db.RemotesSendGrantsListOptions{IncludeAll: options.IncludeDeleted})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list send grants: %w", err)
+	}
+	for _, rm := range remotes {
+		remote := Remote{
+			Name:         rm.Name,
+			Enabled:      &rm.Enabled,
+			PasswordHash: rm.PasswordHash,
+			DeletedAt:    rm.DeletedAt,
+		}
+		for _, sg := range sendGrants {
+			if sg.RemoteName != rm.Name {
+				continue
+			}
+			remote.SendGrants = append(remote.SendGrants, SendGrant{
+				Email:     sg.Name + "@" + sg.DomainFQDN,
+				DeletedAt: sg.DeletedAt,
+			})
+		}
+		doc.Remotes = append(doc.Remotes, remote)
+	}
+
+	// Domains and their catchall targets
+	domains, err := db.Domains(r).List(db.DomainsListOptions{IncludeAll: options.IncludeDeleted})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+	catchallTargets, err := db.DomainsCatchallTargets(r).List(db.DomainsCatchallTargetsListOptions{IncludeAll: options.IncludeDeleted})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list catchall targets: %w", err)
+	}
+	for _, d := range domains {
+		domain := Domain{
+			FQDN:         d.FQDN,
+			Type:         d.Type,
+			Enabled:      &d.Enabled,
+			Transport:    d.TransportName,
+			TargetDomain: d.TargetDomainFQDN,
+			DeletedAt:    d.DeletedAt,
+		}
+		for _, ct := range catchallTargets {
+			if ct.DomainFQDN != d.FQDN {
+				continue
+			}
+			domain.CatchallTargets = append(domain.CatchallTargets, CatchallTarget{
+				Target:       ct.TargetEmail,
+				Forwarding:   &ct.ForwardingToTargetEnabled,
+				FallbackOnly: &ct.FallbackOnly,
+				DeletedAt:    ct.DeletedAt,
+			})
+		}
+		doc.Domains = append(doc.Domains, domain)
+	}
+
+	// Mailboxes
+	mailboxes, err := db.Mailboxes(r).List(db.MailboxesListOptions{
+		IncludeAll:          options.IncludeDeleted,
+		IncludePasswordHash: options.IncludePasswordHashes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mailboxes: %w", err)
+	}
+	for _, m := range mailboxes {
+		doc.Mailboxes = append(doc.Mailboxes, Mailbox{
+			Email:        m.Name + "@" + m.DomainFQDN,
+			Login:        &m.LoginEnabled,
+			Receiving:    &m.ReceivingEnabled,
+			Sending:      &m.SendingEnabled,
+			Quota:        m.StorageQuota,
+			Transport:    m.TransportName,
+			PasswordHash: m.PasswordHash,
+			DeletedAt:    m.DeletedAt,
+		})
+	}
+
+	// Aliases and their targets
+	// Deleted aliases are filtered below, because without IncludeAll aliases
+	// with only deleted targets are omitted as well
+	aliases, err := db.Aliases(r).List(db.AliasesListOptions{IncludeAll: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aliases: %w", err)
+	}
+	aliasTargets, err := db.AliasesTargets(r).List(db.AliasesTargetsListOptions{IncludeAll: options.IncludeDeleted})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alias targets: %w", err)
+	}
+	for _, a := range aliases {
+		if a.Name == nil || (a.DeletedAt != nil && !options.IncludeDeleted) {
+			continue
+		}
+		alias := Alias{
+			Email:     *a.Name + "@" + a.DomainFQDN,
+			Enabled:   &a.Enabled,
+			DeletedAt: a.DeletedAt,
+		}
+		for _, at := range aliasTargets {
+			if at.AliasEmail != alias.Email {
+				continue
+			}
+			alias.Targets = append(alias.Targets, AliasTarget{
+				Email:      at.TargetEmail,
+				Forwarding: &at.ForwardingToTargetEnabled,
+				Sending:    &at.SendingFromTargetEnabled,
+				DeletedAt:  at.DeletedAt,
+			})
+		}
+		doc.Aliases = append(doc.Aliases, alias)
+	}
+
+	// Relayed recipients
+	recipients, err := db.RecipientsRelayed(r).List(db.RecipientsRelayedListOptions{IncludeAll: options.IncludeDeleted})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relayed recipients: %w", err)
+	}
+	for _, rr := range recipients {
+		doc.RecipientsRelayed = append(doc.RecipientsRelayed, RecipientRelayed{
+			Email:     rr.Name + "@" + rr.DomainFQDN,
+			Enabled:   &rr.Enabled,
+			DeletedAt: rr.DeletedAt,
+		})
+	}
+
+	return doc, nil
+}
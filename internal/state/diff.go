@@ -0,0 +1,120 @@
+package state
+
+import (
+	"encoding/json"
+
+	"github.com/goccy/go-yaml"
+)
+
+// ObjectDiff describes an object differing between the current and the
+// desired state as YAML documents. Current or Desired is empty, if the object
+// is missing on that side. Nested objects are compared on their own, and
+// password hashes are redacted.
+type ObjectDiff struct {
+	ObjectType string `json:"type"`
+	Name       string `json:"name"`
+	Current    string `json:"current"`
+	Desired    string `json:"desired"`
+}
+
+// Diffs renders the objects of all changes of the plan.
+func (p *Plan) Diffs() ([]ObjectDiff, error) {
+	var out []ObjectDiff
+	seen := make(map[string]struct{})
+	for _, c := range p.Changes {
+		// Deleted objects missing in the current state are created and deleted
+		key := c.ObjectType + "\x00" + c.Name
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		current, desired := redactObjects(c.current, c.desired)
+		d := ObjectDiff{ObjectType: c.ObjectType, Name: c.Name}
+		var err error
+		if d.Current, err = renderObject(current); err != nil {
+			return nil, err
+		}
+		if d.Desired, err = renderObject(desired); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+func renderObject(o any) (string, error) {
+	if o == nil {
+		return "", nil
+	}
+	jsonData, err := json.Marshal(o)
+	if err != nil {
+		return "", err
+	}
+	yamlData, err := yaml.JSONToYAML(jsonData)
+	if err != nil {
+		return "", err
+	}
+	return string(yamlData), nil
+}
+
+// redactObjects removes nested objects and replaces password hashes. Hashes
+// are omitted, if they aren't managed by the desired state.
+func redactObjects(current, desired any) (any, any) {
+	current, desired = withoutChildren(current), withoutChildren(desired)
+
+	currentHash, desiredHash := passwordHashOf(current), passwordHashOf(desired)
+	var c, d *string
+	if desiredHash != nil {
+		redacted, changed := redactedValue, redactedValue+" (changed)"
+		if currentHash != nil {
+			c = &redacted
+		}
+		if currentHash != nil && *currentHash == *desiredHash {
+			d = &redacted
+		} else {
+			d = &changed
+		}
+	}
+	return withPasswordHash(current, c), withPasswordHash(desired, d)
+}
+
+func withoutChildren(o any) any {
+	switch o := o.(type) {
+	case Remote:
+		o.SendGrants = nil
+		return o
+	case Domain:
+		o.CatchallTargets = nil
+		return o
+	case Alias:
+		o.Targets = nil
+		return o
+	default:
+		return o
+	}
+}
+
+func passwordHashOf(o any) *string {
+	switch o := o.(type) {
+	case Remote:
+		return o.PasswordHash
+	case Mailbox:
+		return o.PasswordHash
+	default:
+		return nil
+	}
+}
+
+func withPasswordHash(o any, passwordHash *string) any {
+	switch o := o.(type) {
+	case Remote:
+		o.PasswordHash = passwordHash
+		return o
+	case Mailbox:
+		o.PasswordHash = passwordHash
+		return o
+	default:
+		return o
+	}
+}
@@ -0,0 +1,54 @@
+package state
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlanDiffs(t *testing.T) {
+	current := mustLoad(t, planBase)
+	hash := "{ARGON2ID}secret"
+	current.Mailboxes[0].PasswordHash = &hash
+
+	desired := mustLoad(t, planBase)
+	disabled := false
+	desired.Mailboxes[0].Login = &disabled
+	desired.Aliases[0].Targets = append(desired.Aliases[0].Targets, AliasTarget{Email: "new@example.com"})
+	if err := desired.Normalize(); err != nil {
+		t.Fatalf("Normalize() unexpected error: %v", err)
+	}
+
+	plan, err := NewPlan(current, desired, PlanOptions{})
+	if err != nil {
+		t.Fatalf("NewPlan() unexpected error: %v", err)
+	}
+	diffs, err := plan.Diffs()
+	if err != nil {
+		t.Fatalf("Diffs() unexpected error: %v", err)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("Diffs() = %+v, want 2 diffs", diffs)
+	}
+
+	mailbox := diffs[0]
+	if mailbox.Name != "user@example.com" || !strings.Contains(mailbox.Current, "login: true") || !strings.Contains(mailbox.Desired, "login: false") {
+		t.Fatalf("Diffs() mailbox = %+v", mailbox)
+	}
+	if strings.Contains(mailbox.Current, "passwordHash") || strings.Contains(mailbox.Current, hash) {
+		t.Fatalf("Diffs() mailbox shows unmanaged password hash: %s", mailbox.Current)
+	}
+
+	target := diffs[1]
+	if target.Current != "" || !strings.Contains(target.Desired, "email: new@example.com") {
+		t.Fatalf("Diffs() alias target = %+v", target)
+	}
+
+	// Managed password hashes are redacted
+	other := "{ARGON2ID}other"
+	desired.Mailboxes[0].PasswordHash = &other
+	plan, _ = NewPlan(current, desired, PlanOptions{})
+	diffs, _ = plan.Diffs()
+	if !strings.Contains(diffs[0].Current, "passwordHash: \"***\"") || !strings.Contains(diffs[0].Desired, "(changed)") || strings.Contains(diffs[0].Desired, other) {
+		t.Fatalf("Diffs() mailbox = %+v, want redacted password hashes", diffs[0])
+	}
+}
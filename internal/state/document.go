@@ -0,0 +1,363 @@
+package state
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gerolf-vent/mailctl/internal/utils"
+	"github.com/goccy/go-yaml"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+const (
+	DocumentVersion int = 1
+
+	SchemaURL = "https://raw.githubusercontent.com/gerolf-vent/mailctl/main/internal/state/state.schema.json"
+)
+
+//go:embed state.schema.json
+var SchemaJSON []byte
+
+var (
+	compiledSchema     *jsonschema.Schema
+	compiledSchemaErr  error
+	compiledSchemaOnce sync.Once
+)
+
+// Document describes the state of the mail system objects. Objects are
+// identified by their natural key (name, FQDN or email address).
+type Document struct {
+	Schema            string             `json:"$schema,omitempty"`
+	Version           int                `json:"version"`
+	Transports        []Transport        `json:"transports,omitempty"`
+	Remotes           []Remote           `json:"remotes,omitempty"`
+	Domains           []Domain           `json:"domains,omitempty"`
+	Mailboxes         []Mailbox          `json:"mailboxes,omitempty"`
+	Aliases           []Alias            `json:"aliases,omitempty"`
+	RecipientsRelayed []RecipientRelayed `json:"recipientsRelayed,omitempty"`
+}
+
+type Transport struct {
+	Name      string     `json:"name"`
+	Method    string     `json:"method"`
+	Host      string     `json:"host"`
+	Port      *uint16    `json:"port,omitempty"`
+	MXLookup  bool       `json:"mxLookup"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+type Remote struct {
+	Name         string      `json:"name"`
+	Enabled      *bool       `json:"enabled,omitempty"`
+	PasswordHash *string     `json:"passwordHash,omitempty"` // Unmanaged if omitted
+	SendGrants   []SendGrant `json:"sendGrants,omitempty"`
+	DeletedAt    *time.Time  `json:"deletedAt,omitempty"`
+}
+
+// SendGrant is an address (the local part may be a SQL LIKE pattern) a remote
+// may send from. It is encoded as a plain string, unless it is soft-deleted.
+type SendGrant struct {
+	Email     string
+	DeletedAt *time.Time
+}
+
+type sendGrantObject struct {
+	Email     string     `json:"email"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+func (g SendGrant) MarshalJSON() ([]byte, error) {
+	if g.DeletedAt == nil {
+		return json.Marshal(g.Email)
+	}
+	return json.Marshal(sendGrantObject(g))
+}
+
+func (g *SendGrant) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		g.DeletedAt = nil
+		return json.Unmarshal(data, &g.Email)
+	}
+
+	var o sendGrantObject
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&o); err != nil {
+		return err
+	}
+	*g = SendGrant(o)
+	return nil
+}
+
+type Domain struct {
+	FQDN            string           `json:"fqdn"`
+	Type            string           `json:"type,omitempty"`
+	Enabled         *bool            `json:"enabled,omitempty"`
+	Transport       *string          `json:"transport,omitempty"`    // For managed and relayed domains
+	TargetDomain    *string          `json:"targetDomain,omitempty"` // For canonical domains
+	CatchallTargets []CatchallTarget `json:"catchallTargets,omitempty"`
+	DeletedAt       *time.Time       `json:"deletedAt,omitempty"`
+}
+
+type CatchallTarget struct {
+	Target       string     `json:"target"`
+	Forwarding   *bool      `json:"forwarding,omitempty"`
+	FallbackOnly *bool      `json:"fallbackOnly,omitempty"`
+	DeletedAt    *time.Time `json:"deletedAt,omitempty"`
+}
+
+type Mailbox struct {
+	Email        string     `json:"email"`
+	Login        *bool      `json:"login,omitempty"`
+	Receiving    *bool      `json:"receiving,omitempty"`
+	Sending      *bool      `json:"sending,omitempty"`
+	Quota        *int32     `json:"quota,omitempty"`
+	Transport    *string    `json:"transport,omitempty"`
+	PasswordHash *string    `json:"passwordHash,omitempty"` // Unmanaged if omitted
+	DeletedAt    *time.Time `json:"deletedAt,omitempty"`
+}
+
+type Alias struct {
+	Email     string        `json:"email"`
+	Enabled   *bool         `json:"enabled,omitempty"`
+	Targets   []AliasTarget `json:"targets,omitempty"`
+	DeletedAt *time.Time    `json:"deletedAt,omitempty"`
+}
+
+type AliasTarget struct {
+	Email      string     `json:"email"`
+	Forwarding *bool      `json:"forwarding,omitempty"`
+	Sending    *bool      `json:"sending,omitempty"`
+	DeletedAt  *time.Time `json:"deletedAt,omitempty"`
+}
+
+type RecipientRelayed struct {
+	Email     string     `json:"email"`
+	Enabled   *bool      `json:"enabled,omitempty"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// Schema returns the compiled JSON Schema of documents.
+func Schema() (*jsonschema.Schema, error) {
+	compiledSchemaOnce.Do(func() {
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(SchemaJSON))
+		if err != nil {
+			compiledSchemaErr = err
+			return
+		}
+
+		c := jsonschema.NewCompiler()
+		if err := c.AddResource(SchemaURL, doc); err != nil {
+			compiledSchemaErr = err
+			return
+		}
+		compiledSchema, compiledSchemaErr = c.Compile(SchemaURL)
+	})
+	return compiledSchema, compiledSchemaErr
+}
+
+// Load parses a YAML or JSON document, validates it against the JSON Schema
+// and applies the defaults of omitted fields.
+func Load(data []byte) (*Document, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	schema, err := Schema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema: %w", err)
+	}
+	if err := schema.Validate(instance); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+
+	var doc Document
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode document: %w", err)
+	}
+
+	if err := doc.Normalize(); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+
+	return &doc, nil
+}
+
+// Validate validates a document, which hasn't been loaded (e.g. a mapped one),
+// against the JSON Schema.
+func (d *Document) Validate() error {
+	jsonData, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("failed to encode document: %w", err)
+	}
+
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to encode document: %w", err)
+	}
+
+	schema, err := Schema()
+	if err != nil {
+		return fmt.Errorf("failed to compile schema: %w", err)
+	}
+	if err := schema.Validate(instance); err != nil {
+		return fmt.Errorf("invalid document: %w", err)
+	}
+	return nil
+}
+
+// Marshal encodes a document as YAML or JSON.
+func Marshal(doc *Document, format string) ([]byte, error) {
+	jsonData, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "json":
+		return append(jsonData, '\n'), nil
+	case "yaml":
+		return yaml.JSONToYAML(jsonData)
+	default:
+		return nil, fmt.Errorf("invalid format %q, must be one of: yaml, json", format)
+	}
+}
+
+// Normalize validates the object keys, applies the defaults of omitted fields
+// and rejects duplicate objects.
+func (d *Document) Normalize() error {
+	var errs []error
+	seen := make(map[string]struct{})
+	checkUnique := func(kind, key string) {
+		k := kind + "\x00" + key
+		if _, ok := seen[k]; ok {
+			errs = append(errs, fmt.Errorf("duplicate %s %s", kind, key))
+		}
+		seen[k] = struct{}{}
+	}
+
+	for i := range d.Transports {
+		t := &d.Transports[i]
+		checkUnique("transport", t.Name)
+	}
+
+	for i := range d.Remotes {
+		r := &d.Remotes[i]
+		checkUnique("remote", r.Name)
+		setDefault(&r.Enabled, true)
+		for j := range r.SendGrants {
+			g := &r.SendGrants[j]
+			email, err := utils.ParseEmailAddressOrWildcard(g.Email)
+			if err != nil || email.IsWildcard() {
+				errs = append(errs, fmt.Errorf("remote %s: invalid send grant %q", r.Name, g.Email))
+				continue
+			}
+			g.Email = email.String()
+			checkUnique("send grant", r.Name+": "+g.Email)
+		}
+	}
+
+	for i := range d.Domains {
+		dm := &d.Domains[i]
+		fqdn, err := utils.ParseDomainFQDN(dm.FQDN)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("domain %s: %w", dm.FQDN, err))
+			continue
+		}
+		dm.FQDN = fqdn
+		checkUnique("domain", dm.FQDN)
+		if dm.Type == "" {
+			dm.Type = "managed"
+		}
+		setDefault(&dm.Enabled, true)
+		for j := range dm.CatchallTargets {
+			ct := &dm.CatchallTargets[j]
+			if err := normalizeEmail(&ct.Target); err != nil {
+				errs = append(errs, fmt.Errorf("domain %s: catchall target %s: %w", dm.FQDN, ct.Target, err))
+				continue
+			}
+			checkUnique("catchall target", dm.FQDN+" -> "+ct.Target)
+			setDefault(&ct.Forwarding, true)
+			setDefault(&ct.FallbackOnly, true)
+		}
+	}
+
+	for i := range d.Mailboxes {
+		m := &d.Mailboxes[i]
+		if err := normalizeEmail(&m.Email); err != nil {
+			errs = append(errs, fmt.Errorf("mailbox %s: %w", m.Email, err))
+			continue
+		}
+		checkUnique("recipient", m.Email)
+		setDefault(&m.Login, true)
+		setDefault(&m.Receiving, true)
+		setDefault(&m.Sending, true)
+	}
+
+	for i := range d.Aliases {
+		a := &d.Aliases[i]
+		if err := normalizeEmail(&a.Email); err != nil {
+			errs = append(errs, fmt.Errorf("alias %s: %w", a.Email, err))
+			continue
+		}
+		checkUnique("recipient", a.Email)
+		setDefault(&a.Enabled, true)
+		for j := range a.Targets {
+			t := &a.Targets[j]
+			if err := normalizeEmail(&t.Email); err != nil {
+				errs = append(errs, fmt.Errorf("alias %s: target %s: %w", a.Email, t.Email, err))
+				continue
+			}
+			checkUnique("alias target", a.Email+" -> "+t.Email)
+			setDefault(&t.Forwarding, true)
+			setDefault(&t.Sending, false)
+		}
+	}
+
+	for i := range d.RecipientsRelayed {
+		r := &d.RecipientsRelayed[i]
+		if err := normalizeEmail(&r.Email); err != nil {
+			errs = append(errs, fmt.Errorf("relayed recipient %s: %w", r.Email, err))
+			continue
+		}
+		checkUnique("recipient", r.Email)
+		setDefault(&r.Enabled, true)
+	}
+
+	return errors.Join(errs...)
+}
+
+func setDefault[T any](p **T, value T) {
+	if *p == nil {
+		*p = &value
+	}
+}
+
+func normalizeEmail(address *string) error {
+	email, err := utils.ParseEmailAddress(*address)
+	if err != nil {
+		return err
+	}
+	*address = email.String()
+	return nil
+}
+
+// splitEmail splits a normalized email address.
+func splitEmail(address string) utils.EmailAddress {
+	localPart, domainFQDN, _ := strings.Cut(address, "@")
+	return utils.EmailAddress{LocalPart: localPart, DomainFQDN: domainFQDN}
+}
@@ -0,0 +1,157 @@
+package state
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	doc, err := Load([]byte(`
+version: 1
+transports:
+  - name: dovecot
+    method: lmtp
+    host: mail.internal
+    port: 24
+remotes:
+  - name: webapp
+    sendGrants: [noreply@example.com]
+domains:
+  - fqdn: example.com
+    transport: dovecot
+    catchallTargets:
+      - target: postmaster@example.com
+mailboxes:
+  - email: user@example.com
+    quota: 1024
+aliases:
+  - email: team@example.com
+    targets:
+      - email: user@example.com
+`))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	if got := doc.Domains[0]; got.FQDN != "example.com" || got.Type != "managed" || !*got.Enabled {
+		t.Fatalf("Load() domain = %+v, want normalized managed domain", got)
+	}
+	if got := doc.Domains[0].CatchallTargets[0]; !*got.Forwarding || !*got.FallbackOnly {
+		t.Fatalf("Load() catchall target defaults = %v, %v, want true, true", *got.Forwarding, *got.FallbackOnly)
+	}
+	if got := doc.Mailboxes[0]; got.Email != "user@example.com" || !*got.Login || !*got.Receiving || !*got.Sending || *got.Quota != 1024 {
+		t.Fatalf("Load() mailbox = %+v, want normalized mailbox with defaults", got)
+	}
+	if got := doc.Aliases[0].Targets[0]; !*got.Forwarding || *got.Sending {
+		t.Fatalf("Load() alias target defaults = %v, %v, want true, false", *got.Forwarding, *got.Sending)
+	}
+	if got := doc.Remotes[0].SendGrants[0].Email; got != "noreply@example.com" {
+		t.Fatalf("Load() send grant = %q, want %q", got, "noreply@example.com")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr string
+	}{
+		{"unknown field", "version: 1\nmailboxes:\n  - email: user@example.com\n    quotaMB: 10\n", "invalid document"},
+		{"missing version", "mailboxes: []\n", "invalid document"},
+		{"wrong version", "version: 2\n", "invalid document"},
+		{"managed domain without transport", "version: 1\ndomains:\n  - fqdn: example.com\n", "invalid document"},
+		{"canonical domain with transport", "version: 1\ndomains:\n  - fqdn: example.org\n    type: canonical\n    targetDomain: example.com\n    transport: dovecot\n", "invalid document"},
+		{"invalid email", "version: 1\nmailboxes:\n  - email: user\n", "invalid document"},
+		{"duplicate recipient", "version: 1\nmailboxes:\n  - email: user@example.com\naliases:\n  - email: user@example.com\n", "duplicate recipient user@example.com"},
+		{"wildcard send grant", "version: 1\nremotes:\n  - name: webapp\n    sendGrants: ['@example.com']\n", "invalid document"},
+		{"malformed yaml", "version: [1\n", "failed to parse document"},
+	}
+
+	for _, tc := range tests {
+		_, err := Load([]byte(tc.in))
+		if err == nil {
+			t.Fatalf("%s: Load() expected error", tc.name)
+		}
+		if !strings.Contains(err.Error(), tc.wantErr) {
+			t.Fatalf("%s: Load() error = %v, want %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	transport := "dovecot"
+	doc := &Document{
+		Version: DocumentVersion,
+		Domains: []Domain{{FQDN: "example.com", Type: "managed", Transport: &transport}},
+	}
+	if err := doc.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	doc.Domains[0].Transport = nil
+	if err := doc.Validate(); err == nil || !strings.Contains(err.Error(), "invalid document") {
+		t.Fatalf("Validate() error = %v, want invalid document for managed domain without transport", err)
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	in := []byte("version: 1\nremotes:\n  - name: webapp\n    sendGrants: [noreply@example.com]\nmailboxes:\n  - email: user@example.com\n")
+	doc, err := Load(in)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	for _, format := range []string{"yaml", "json"} {
+		data, err := Marshal(doc, format)
+		if err != nil {
+			t.Fatalf("Marshal(%s) unexpected error: %v", format, err)
+		}
+		again, err := Load(data)
+		if err != nil {
+			t.Fatalf("Load(Marshal(%s)) unexpected error: %v\n%s", format, err, data)
+		}
+		if again.Mailboxes[0].Email != "user@example.com" || again.Remotes[0].SendGrants[0].Email != "noreply@example.com" {
+			t.Fatalf("Load(Marshal(%s)) = %+v", format, again)
+		}
+	}
+
+	if _, err := Marshal(doc, "toml"); err == nil {
+		t.Fatalf("Marshal(toml) expected error")
+	}
+}
+
+func TestMarshalDeletedRoundTrip(t *testing.T) {
+	deletedAt := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	doc := &Document{
+		Schema:  SchemaURL,
+		Version: DocumentVersion,
+		Remotes: []Remote{{
+			Name: "webapp",
+			SendGrants: []SendGrant{
+				{Email: "noreply@example.com"},
+				{Email: "old@example.com", DeletedAt: &deletedAt},
+			},
+		}},
+		Mailboxes: []Mailbox{{Email: "user@example.com", DeletedAt: &deletedAt}},
+	}
+
+	for _, format := range []string{"yaml", "json"} {
+		data, err := Marshal(doc, format)
+		if err != nil {
+			t.Fatalf("Marshal(%s) unexpected error: %v", format, err)
+		}
+		again, err := Load(data)
+		if err != nil {
+			t.Fatalf("Load(Marshal(%s)) unexpected error: %v\n%s", format, err, data)
+		}
+
+		grants := again.Remotes[0].SendGrants
+		if grants[0].DeletedAt != nil || grants[1].DeletedAt == nil || !grants[1].DeletedAt.Equal(deletedAt) {
+			t.Fatalf("Load(Marshal(%s)) send grants = %+v", format, grants)
+		}
+		if m := again.Mailboxes[0]; m.DeletedAt == nil || !m.DeletedAt.Equal(deletedAt) {
+			t.Fatalf("Load(Marshal(%s)) mailbox deletedAt = %v, want %v", format, m.DeletedAt, deletedAt)
+		}
+	}
+}
@@ -0,0 +1,860 @@
+package state
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/gerolf-vent/mailctl/internal/db"
+	"github.com/gerolf-vent/mailctl/internal/utils"
+)
+
+type Action string
+
+const (
+	ActionCreate  Action = "create"
+	ActionUpdate  Action = "update"
+	ActionRestore Action = "restore"
+	ActionDelete  Action = "delete"
+)
+
+// Stages in which changes are applied. Creates, restores and updates are
+// applied in ascending order, deletes in descending order, so objects exist
+// before others reference them.
+const (
+	stageTransports = iota
+	stageRemotes
+	stageDomains
+	stageDomainsCanonical
+	stageMailboxes
+	stageRecipientsRelayed
+	stageAliases
+	stageAliasTargets
+	stageCatchallTargets
+	stageSendGrants
+)
+
+// redactedValue replaces password hashes in field changes.
+const redactedValue = "***"
+
+type Change struct {
+	Action     Action              `json:"action"`
+	ObjectType string              `json:"type"`
+	Name       string              `json:"name"`
+	Fields     []utils.FieldChange `json:"fields,omitempty"`
+
+	stage   int
+	exec    func(tx *sql.Tx) error
+	current any // Object in the current state (nil if missing)
+	desired any // Object in the desired state (nil if not described)
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s %s %s", c.Action, c.ObjectType, c.Name)
+}
+
+type Plan struct {
+	Changes []Change `json:"changes"`
+}
+
+// ConflictPolicy decides how objects, which exist in a different state than
+// described, are handled.
+type ConflictPolicy string
+
+const (
+	ConflictOverwrite ConflictPolicy = "overwrite" // Change the object to the described state
+	ConflictSkip      ConflictPolicy = "skip"      // Keep the object unchanged
+	ConflictFail      ConflictPolicy = "fail"      // Refuse to compute a plan
+)
+
+var ConflictPolicies = []ConflictPolicy{ConflictSkip, ConflictOverwrite, ConflictFail}
+
+type PlanOptions struct {
+	Prune      bool           // Soft-delete objects, which are not in the desired state
+	OnConflict ConflictPolicy // Default: overwrite
+}
+
+// Count returns the number of changes with the given action.
+func (p *Plan) Count(action Action) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Apply executes all changes of the plan in order. The runner should be a
+// transaction, so a failing change doesn't leave a partially applied plan.
+func (p *Plan) Apply(tx *sql.Tx) error {
+	for _, c := range p.Changes {
+		if err := c.exec(tx); err != nil {
+			return fmt.Errorf("failed to %s: %w", c.String(), err)
+		}
+	}
+	return nil
+}
+
+// NewPlan computes the changes required to turn the current state into the
+// desired state. The current state has to include soft-deleted objects and
+// password hashes, so deleted objects are restored instead of recreated.
+func NewPlan(current, desired *Document, options PlanOptions) (*Plan, error) {
+	if options.OnConflict == "" {
+		options.OnConflict = ConflictOverwrite
+	}
+
+	// Objects are only restored, if conflicts are overwritten
+	p := &planner{options: options}
+	if options.OnConflict == ConflictOverwrite {
+		current = withCascadedRestores(current, desired)
+	}
+
+	p.transports(current.Transports, desired.Transports)
+	p.remotes(current.Remotes, desired.Remotes)
+	p.domains(current.Domains, desired.Domains)
+	p.mailboxes(current.Mailboxes, desired.Mailboxes)
+	p.recipientsRelayed(current.RecipientsRelayed, desired.RecipientsRelayed)
+	p.aliases(current.Aliases, desired.Aliases)
+
+	if len(p.errs) > 0 {
+		return nil, errors.Join(p.errs...)
+	}
+
+	// Order changes by stage, keeping the document order within a stage
+	slices.SortStableFunc(p.changes, func(a, b Change) int {
+		aDelete, bDelete := a.Action == ActionDelete, b.Action == ActionDelete
+		switch {
+		case aDelete && !bDelete:
+			return 1
+		case !aDelete && bDelete:
+			return -1
+		case aDelete && bDelete:
+			return cmp.Compare(b.stage, a.stage)
+		default:
+			return cmp.Compare(a.stage, b.stage)
+		}
+	})
+
+	return &Plan{Changes: p.changes}, nil
+}
+
+// withCascadedRestores returns a copy of the current state, in which objects
+// are active, if restoring a desired active object restores them as well.
+// Restores cascade to objects deleted at the same time as their parent.
+func withCascadedRestores(current, desired *Document) *Document {
+	out := *current
+	restored := func(parent, child *time.Time) *time.Time {
+		if parent != nil && child != nil && parent.Equal(*child) {
+			return nil
+		}
+		return child
+	}
+
+	desiredDomains := make(map[string]struct{}, len(desired.Domains))
+	for _, d := range desired.Domains {
+		if d.DeletedAt == nil {
+			desiredDomains[d.FQDN] = struct{}{}
+		}
+	}
+	restoredDomains := make(map[string]*time.Time)
+	for _, d := range current.Domains {
+		if _, ok := desiredDomains[d.FQDN]; ok && d.DeletedAt != nil {
+			restoredDomains[d.FQDN] = d.DeletedAt
+		}
+	}
+	domainOf := func(email string) *time.Time {
+		return restoredDomains[splitEmail(email).DomainFQDN]
+	}
+
+	out.Domains = slices.Clone(current.Domains)
+	for i := range out.Domains {
+		d := &out.Domains[i]
+		if d.TargetDomain != nil {
+			d.DeletedAt = restored(restoredDomains[*d.TargetDomain], d.DeletedAt)
+		}
+		if parent, ok := restoredDomains[d.FQDN]; ok {
+			d.CatchallTargets = slices.Clone(d.CatchallTargets)
+			for j := range d.CatchallTargets {
+				d.CatchallTargets[j].DeletedAt = restored(parent, d.CatchallTargets[j].DeletedAt)
+			}
+		}
+	}
+
+	out.Mailboxes = slices.Clone(current.Mailboxes)
+	for i := range out.Mailboxes {
+		m := &out.Mailboxes[i]
+		m.DeletedAt = restored(domainOf(m.Email), m.DeletedAt)
+	}
+
+	out.RecipientsRelayed = slices.Clone(current.RecipientsRelayed)
+	for i := range out.RecipientsRelayed {
+		r := &out.RecipientsRelayed[i]
+		r.DeletedAt = restored(domainOf(r.Email), r.DeletedAt)
+	}
+
+	desiredAliases := make(map[string]struct{}, len(desired.Aliases))
+	for _, a := range desired.Aliases {
+		if a.DeletedAt == nil {
+			desiredAliases[a.Email] = struct{}{}
+		}
+	}
+	out.Aliases = slices.Clone(current.Aliases)
+	for i := range out.Aliases {
+		a := &out.Aliases[i]
+		parent := a.DeletedAt
+		a.DeletedAt = restored(domainOf(a.Email), a.DeletedAt)
+		if _, ok := desiredAliases[a.Email]; !ok && a.DeletedAt != nil {
+			continue
+		}
+		a.Targets = slices.Clone(a.Targets)
+		for j := range a.Targets {
+			a.Targets[j].DeletedAt = restored(parent, a.Targets[j].DeletedAt)
+		}
+	}
+
+	desiredRemotes := make(map[string]struct{}, len(desired.Remotes))
+	for _, r := range desired.Remotes {
+		if r.DeletedAt == nil {
+			desiredRemotes[r.Name] = struct{}{}
+		}
+	}
+	out.Remotes = slices.Clone(current.Remotes)
+	for i := range out.Remotes {
+		r := &out.Remotes[i]
+		if _, ok := desiredRemotes[r.Name]; !ok || r.DeletedAt == nil {
+			continue
+		}
+		r.SendGrants = slices.Clone(r.SendGrants)
+		for j := range r.SendGrants {
+			r.SendGrants[j].DeletedAt = restored(r.DeletedAt, r.SendGrants[j].DeletedAt)
+		}
+	}
+
+	return &out
+}
+
+type planner struct {
+	options PlanOptions
+	changes []Change
+	errs    []error
+}
+
+// objectOps describes how to compare and change objects of a type.
+type objectOps[T any] struct {
+	stage      int
+	objectType string
+	key        func(o T) string
+	deletedAt  func(o T) *time.Time
+	fields     func(have, want T) []utils.FieldChange
+	create     func(tx *sql.Tx, want T) error
+	patch      func(tx *sql.Tx, have, want T) error
+	restore    func(tx *sql.Tx, want T) error
+	delete     func(tx *sql.Tx, have T, deletedAt *time.Time) error
+	object     func(o T) any // Object shown in diffs (default: o itself)
+}
+
+// diffObjects plans the changes of one object type. Objects only present in
+// the current state are deleted if prune is set. Objects with a deletion time
+// in the desired state are soft-deleted at that time.
+func diffObjects[T any](p *planner, have, want []T, prune bool, ops objectOps[T]) {
+	current := make(map[string]T, len(have))
+	for _, o := range have {
+		current[ops.key(o)] = o
+	}
+
+	object := func(o *T) any {
+		switch {
+		case o == nil:
+			return nil
+		case ops.object != nil:
+			return ops.object(*o)
+		default:
+			return *o
+		}
+	}
+	change := func(action Action, key string, h, w *T, fields []utils.FieldChange, exec func(tx *sql.Tx) error) Change {
+		return Change{
+			Action:     action,
+			ObjectType: ops.objectType,
+			Name:       key,
+			Fields:     fields,
+			stage:      ops.stage,
+			exec:       exec,
+			current:    object(h),
+			desired:    object(w),
+		}
+	}
+	deleteChange := func(key string, h, w *T, deletedAt *time.Time) Change {
+		var fields []utils.FieldChange
+		if deletedAt != nil {
+			fields = []utils.FieldChange{{Field: "deletedAt", New: deletedAt.Format(time.RFC3339)}}
+		}
+		o := h
+		if o == nil {
+			o = w
+		}
+		return change(ActionDelete, key, h, w, fields, func(tx *sql.Tx) error { return ops.delete(tx, *o, deletedAt) })
+	}
+
+	wanted := make(map[string]struct{}, len(want))
+	for _, w := range want {
+		key := ops.key(w)
+		wanted[key] = struct{}{}
+		wantDeletedAt := ops.deletedAt(w)
+
+		h, exists := current[key]
+		if !exists {
+			// Deleted objects are created first, so their children can be created
+			p.changes = append(p.changes, change(ActionCreate, key, nil, &w, nil, func(tx *sql.Tx) error { return ops.create(tx, w) }))
+			if wantDeletedAt != nil {
+				p.changes = append(p.changes, deleteChange(key, nil, &w, wantDeletedAt))
+			}
+			continue
+		}
+
+		var fields []utils.FieldChange
+		if ops.fields != nil {
+			fields = ops.fields(h, w)
+		}
+
+		var c Change
+		switch {
+		case wantDeletedAt != nil && ops.deletedAt(h) != nil:
+			// Fields of deleted objects can't be changed
+			continue
+		case wantDeletedAt != nil:
+			c = deleteChange(key, &h, &w, wantDeletedAt)
+		case ops.deletedAt(h) != nil:
+			c = change(ActionRestore, key, &h, &w, fields, func(tx *sql.Tx) error {
+				if err := ops.restore(tx, w); err != nil {
+					return err
+				}
+				if len(fields) > 0 {
+					return ops.patch(tx, h, w)
+				}
+				return nil
+			})
+		case len(fields) > 0:
+			c = change(ActionUpdate, key, &h, &w, fields, func(tx *sql.Tx) error { return ops.patch(tx, h, w) })
+		default:
+			continue
+		}
+
+		switch p.options.OnConflict {
+		case ConflictSkip:
+			continue
+		case ConflictFail:
+			p.errs = append(p.errs, fmt.Errorf("%s %s already exists in a different state", ops.objectType, key))
+			continue
+		}
+		p.changes = append(p.changes, c)
+	}
+
+	if !prune {
+		return
+	}
+
+	for _, h := range have {
+		key := ops.key(h)
+		if _, ok := wanted[key]; ok || ops.deletedAt(h) != nil {
+			continue
+		}
+		p.changes = append(p.changes, deleteChange(key, &h, nil, nil))
+	}
+}
+
+// fieldChanges collects changed fields of an object.
+type fieldChanges []utils.FieldChange
+
+func (f *fieldChanges) add(field string, old, new any) {
+	if !reflect.DeepEqual(old, new) {
+		*f = append(*f, utils.FieldChange{Field: field, Old: old, New: new})
+	}
+}
+
+// addSecret compares a secret, which is only managed if it is set in the
+// desired state, and redacts its values.
+func (f *fieldChanges) addSecret(field string, old, new *string) {
+	if new == nil || (old != nil && *old == *new) {
+		return
+	}
+	var oldValue any
+	if old != nil {
+		oldValue = redactedValue
+	}
+	*f = append(*f, utils.FieldChange{Field: field, Old: oldValue, New: redactedValue})
+}
+
+func value[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func nullString(p *string) sql.NullString {
+	if p == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *p, Valid: true}
+}
+
+func nullInt32(p *int32) sql.NullInt32 {
+	if p == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *p, Valid: true}
+}
+
+func (p *planner) transports(have, want []Transport) {
+	diffObjects(p, have, want, p.options.Prune, objectOps[Transport]{
+		stage:      stageTransports,
+		objectType: "transport",
+		key:        func(o Transport) string { return o.Name },
+		deletedAt:  func(o Transport) *time.Time { return o.DeletedAt },
+		fields: func(h, w Transport) []utils.FieldChange {
+			var f fieldChanges
+			f.add("method", h.Method, w.Method)
+			f.add("host", h.Host, w.Host)
+			f.add("port", value(h.Port), value(w.Port))
+			f.add("mxLookup", h.MXLookup, w.MXLookup)
+			return f
+		},
+		create: func(tx *sql.Tx, w Transport) error {
+			options := db.TransportsCreateOptions{
+				Method:   w.Method,
+				Host:     w.Host,
+				MxLookup: w.MXLookup,
+			}
+			if w.Port != nil {
+				options.Port = sql.NullInt32{Int32: int32(*w.Port), Valid: true}
+			}
+			return db.Transports(tx).Create(w.Name, options)
+		},
+		patch: func(tx *sql.Tx, h, w Transport) error {
+			var options db.TransportsPatchOptions
+			if h.Method != w.Method {
+				options.Method = &w.Method
+			}
+			if h.Host != w.Host {
+				options.Host = &w.Host
+			}
+			if !reflect.DeepEqual(h.Port, w.Port) {
+				port := sql.NullInt32{}
+				if w.Port != nil {
+					port = sql.NullInt32{Int32: int32(*w.Port), Valid: true}
+				}
+				options.Port = &port
+			}
+			if h.MXLookup != w.MXLookup {
+				options.MxLookup = &w.MXLookup
+			}
+			return db.Transports(tx).Patch(w.Name, options)
+		},
+		restore: func(tx *sql.Tx, w Transport) error {
+			return db.Transports(tx).Restore(w.Name)
+		},
+		delete: func(tx *sql.Tx, h Transport, deletedAt *time.Time) error {
+			return db.Transports(tx).Delete(h.Name, db.DeleteOptions{DeletedAt: deletedAt})
+		},
+	})
+}
+
+func (p *planner) remotes(have, want []Remote) {
+	diffObjects(p, have, want, p.options.Prune, objectOps[Remote]{
+		stage:      stageRemotes,
+		objectType: "remote",
+		key:        func(o Remote) string { return o.Name },
+		deletedAt:  func(o Remote) *time.Time { return o.DeletedAt },
+		fields: func(h, w Remote) []utils.FieldChange {
+			var f fieldChanges
+			f.add("enabled", value(h.Enabled), value(w.Enabled))
+			f.addSecret("passwordHash", h.PasswordHash, w.PasswordHash)
+			return f
+		},
+		create: func(tx *sql.Tx, w Remote) error {
+			return db.Remotes(tx).Create(w.Name, db.RemotesCreateOptions{
+				PasswordHash: nullString(w.PasswordHash),
+				Enabled:      *w.Enabled,
+			})
+		},
+		patch: func(tx *sql.Tx, h, w Remote) error {
+			var options db.RemotesPatchOptions
+			if *h.Enabled != *w.Enabled {
+				options.Enabled = w.Enabled
+			}
+			if w.PasswordHash != nil && !reflect.DeepEqual(h.PasswordHash, w.PasswordHash) {
+				passwordHash := nullString(w.PasswordHash)
+				options.PasswordHash = &passwordHash
+			}
+			return db.Remotes(tx).Patch(w.Name, options)
+		},
+		restore: func(tx *sql.Tx, w Remote) error {
+			return db.Remotes(tx).Restore(w.Name)
+		},
+		delete: func(tx *sql.Tx, h Remote, deletedAt *time.Time) error {
+			return db.Remotes(tx).Delete(h.Name, db.DeleteOptions{DeletedAt: deletedAt})
+		},
+	})
+
+	// Send grants of the remotes in the desired state
+	type sendGrantRef struct {
+		Remote string
+		SendGrant
+	}
+	desiredRemotes := make(map[string]struct{}, len(want))
+	var wantGrants, haveGrants []sendGrantRef
+	for _, r := range want {
+		desiredRemotes[r.Name] = struct{}{}
+		for _, g := range r.SendGrants {
+			wantGrants = append(wantGrants, sendGrantRef{r.Name, g})
+		}
+	}
+	for _, r := range have {
+		if _, ok := desiredRemotes[r.Name]; !ok {
+			continue
+		}
+		for _, g := range r.SendGrants {
+			haveGrants = append(haveGrants, sendGrantRef{r.Name, g})
+		}
+	}
+
+	email := func(o sendGrantRef) utils.EmailAddressOrWildcard {
+		email, _ := utils.ParseEmailAddressOrWildcard(o.Email)
+		return email
+	}
+	diffObjects(p, haveGrants, wantGrants, p.options.Prune, objectOps[sendGrantRef]{
+		stage:      stageSendGrants,
+		objectType: "send grant",
+		key:        func(o sendGrantRef) string { return o.Remote + ": " + o.Email },
+		object:     func(o sendGrantRef) any { return o.SendGrant },
+		deletedAt:  func(o sendGrantRef) *time.Time { return o.DeletedAt },
+		create: func(tx *sql.Tx, w sendGrantRef) error {
+			return db.RemotesSendGrants(tx).Create(w.Remote, email(w), db.RemotesSendGrantsCreateOptions{})
+		},
+		restore: func(tx *sql.Tx, w sendGrantRef) error {
+			return db.RemotesSendGrants(tx).Restore(w.Remote, email(w))
+		},
+		delete: func(tx *sql.Tx, h sendGrantRef, deletedAt *time.Time) error {
+			return db.RemotesSendGrants(tx).Delete(h.Remote, email(h), db.DeleteOptions{DeletedAt: deletedAt})
+		},
+	})
+}
+
+func (p *planner) domains(have, want []Domain) {
+	current := make(map[string]Domain, len(have))
+	for _, d := range have {
+		current[d.FQDN] = d
+	}
+	for _, w := range want {
+		if h, ok := current[w.FQDN]; ok && h.Type != w.Type {
+			p.errs = append(p.errs, fmt.Errorf("cannot change type of domain %s from %s to %s", w.FQDN, h.Type, w.Type))
+		}
+	}
+
+	// Canonical domains reference other domains, so they are handled separately
+	isCanonical := func(d Domain) bool { return d.Type == "canonical" }
+	for _, canonical := range []bool{false, true} {
+		stage := stageDomains
+		if canonical {
+			stage = stageDomainsCanonical
+		}
+
+		filter := func(domains []Domain) []Domain {
+			var out []Domain
+			for _, d := range domains {
+				if isCanonical(d) == canonical {
+					out = append(out, d)
+				}
+			}
+			return out
+		}
+
+		diffObjects(p, filter(have), filter(want), p.options.Prune, objectOps[Domain]{
+			stage:      stage,
+			objectType: "domain",
+			key:        func(o Domain) string { return o.FQDN },
+			deletedAt:  func(o Domain) *time.Time { return o.DeletedAt },
+			fields: func(h, w Domain) []utils.FieldChange {
+				var f fieldChanges
+				f.add("enabled", value(h.Enabled), value(w.Enabled))
+				f.add("transport", value(h.Transport), value(w.Transport))
+				f.add("targetDomain", value(h.TargetDomain), value(w.TargetDomain))
+				return f
+			},
+			create: func(tx *sql.Tx, w Domain) error {
+				options := db.DomainsCreateOptions{
+					DomainType: w.Type,
+					Enabled:    *w.Enabled,
+				}
+				if w.Transport != nil {
+					options.TransportName = *w.Transport
+				}
+				if w.TargetDomain != nil {
+					options.TargetDomainFQDN = *w.TargetDomain
+				}
+				return db.Domains(tx).Create(w.FQDN, options)
+			},
+			patch: func(tx *sql.Tx, h, w Domain) error {
+				var options db.DomainsPatchOptions
+				if *h.Enabled != *w.Enabled {
+					options.Enabled = w.Enabled
+				}
+				if w.Transport != nil && !reflect.DeepEqual(h.Transport, w.Transport) {
+					options.TransportName = w.Transport
+				}
+				if w.TargetDomain != nil && !reflect.DeepEqual(h.TargetDomain, w.TargetDomain) {
+					options.TargetDomainFQDN = w.TargetDomain
+				}
+				return db.Domains(tx).Patch(w.FQDN, options)
+			},
+			restore: func(tx *sql.Tx, w Domain) error {
+				return db.Domains(tx).Restore(w.FQDN)
+			},
+			delete: func(tx *sql.Tx, h Domain, deletedAt *time.Time) error {
+				return db.Domains(tx).Delete(h.FQDN, db.DeleteOptions{DeletedAt: deletedAt})
+			},
+		})
+	}
+
+	// Catchall targets of the domains in the desired state
+	type catchallTargetRef struct {
+		Domain string
+		CatchallTarget
+	}
+	desiredDomains := make(map[string]struct{}, len(want))
+	var wantTargets, haveTargets []catchallTargetRef
+	for _, d := range want {
+		desiredDomains[d.FQDN] = struct{}{}
+		for _, t := range d.CatchallTargets {
+			wantTargets = append(wantTargets, catchallTargetRef{d.FQDN, t})
+		}
+	}
+	for _, d := range have {
+		if _, ok := desiredDomains[d.FQDN]; !ok {
+			continue
+		}
+		for _, t := range d.CatchallTargets {
+			haveTargets = append(haveTargets, catchallTargetRef{d.FQDN, t})
+		}
+	}
+
+	diffObjects(p, haveTargets, wantTargets, p.options.Prune, objectOps[catchallTargetRef]{
+		stage:      stageCatchallTargets,
+		objectType: "catchall target",
+		key:        func(o catchallTargetRef) string { return "@" + o.Domain + " -> " + o.Target },
+		object:     func(o catchallTargetRef) any { return o.CatchallTarget },
+		deletedAt:  func(o catchallTargetRef) *time.Time { return o.DeletedAt },
+		fields: func(h, w catchallTargetRef) []utils.FieldChange {
+			var f fieldChanges
+			f.add("forwarding", value(h.Forwarding), value(w.Forwarding))
+			f.add("fallbackOnly", value(h.FallbackOnly), value(w.FallbackOnly))
+			return f
+		},
+		create: func(tx *sql.Tx, w catchallTargetRef) error {
+			return db.DomainsCatchallTargets(tx).Create(w.Domain, splitEmail(w.Target), db.DomainsCatchallTargetsCreateOptions{
+				ForwardEnabled: *w.Forwarding,
+				FallbackOnly:   *w.FallbackOnly,
+			})
+		},
+		patch: func(tx *sql.Tx, h, w catchallTargetRef) error {
+			var options db.DomainsCatchallTargetsPatchOptions
+			if *h.Forwarding != *w.Forwarding {
+				options.ForwardingToTargetEnabled = w.Forwarding
+			}
+			if *h.FallbackOnly != *w.FallbackOnly {
+				options.FallbackOnly = w.FallbackOnly
+			}
+			return db.DomainsCatchallTargets(tx).Patch(w.Domain, splitEmail(w.Target), options)
+		},
+		restore: func(tx *sql.Tx, w catchallTargetRef) error {
+			return db.DomainsCatchallTargets(tx).Restore(w.Domain, splitEmail(w.Target))
+		},
+		delete: func(tx *sql.Tx, h catchallTargetRef, deletedAt *time.Time) error {
+			return db.DomainsCatchallTargets(tx).Delete(h.Domain, splitEmail(h.Target), db.DeleteOptions{DeletedAt: deletedAt})
+		},
+	})
+}
+
+func (p *planner) mailboxes(have, want []Mailbox) {
+	diffObjects(p, have, want, p.options.Prune, objectOps[Mailbox]{
+		stage:      stageMailboxes,
+		objectType: "mailbox",
+		key:        func(o Mailbox) string { return o.Email },
+		deletedAt:  func(o Mailbox) *time.Time { return o.DeletedAt },
+		fields: func(h, w Mailbox) []utils.FieldChange {
+			var f fieldChanges
+			f.add("login", value(h.Login), value(w.Login))
+			f.add("receiving", value(h.Receiving), value(w.Receiving))
+			f.add("sending", value(h.Sending), value(w.Sending))
+			f.add("quota", value(h.Quota), value(w.Quota))
+			f.add("transport", value(h.Transport), value(w.Transport))
+			f.addSecret("passwordHash", h.PasswordHash, w.PasswordHash)
+			return f
+		},
+		create: func(tx *sql.Tx, w Mailbox) error {
+			return db.Mailboxes(tx).Create(splitEmail(w.Email), db.MailboxesCreateOptions{
+				PasswordHash:     nullString(w.PasswordHash),
+				Quota:            nullInt32(w.Quota),
+				TransportName:    nullString(w.Transport),
+				LoginEnabled:     *w.Login,
+				ReceivingEnabled: *w.Receiving,
+				SendingEnabled:   *w.Sending,
+			})
+		},
+		patch: func(tx *sql.Tx, h, w Mailbox) error {
+			var options db.MailboxesPatchOptions
+			if *h.Login != *w.Login {
+				options.Login = w.Login
+			}
+			if *h.Receiving != *w.Receiving {
+				options.Receiving = w.Receiving
+			}
+			if *h.Sending != *w.Sending {
+				options.Sending = w.Sending
+			}
+			if !reflect.DeepEqual(h.Quota, w.Quota) {
+				quota := nullInt32(w.Quota)
+				options.Quota = &quota
+			}
+			if !reflect.DeepEqual(h.Transport, w.Transport) {
+				transport := nullString(w.Transport)
+				options.TransportName = &transport
+			}
+			if w.PasswordHash != nil && !reflect.DeepEqual(h.PasswordHash, w.PasswordHash) {
+				passwordHash := nullString(w.PasswordHash)
+				options.PasswordHash = &passwordHash
+			}
+			return db.Mailboxes(tx).Patch(splitEmail(w.Email), options)
+		},
+		restore: func(tx *sql.Tx, w Mailbox) error {
+			return db.Mailboxes(tx).Restore(splitEmail(w.Email))
+		},
+		delete: func(tx *sql.Tx, h Mailbox, deletedAt *time.Time) error {
+			return db.Mailboxes(tx).Delete(splitEmail(h.Email), db.DeleteOptions{DeletedAt: deletedAt})
+		},
+	})
+}
+
+func (p *planner) recipientsRelayed(have, want []RecipientRelayed) {
+	diffObjects(p, have, want, p.options.Prune, objectOps[RecipientRelayed]{
+		stage:      stageRecipientsRelayed,
+		objectType: "relayed recipient",
+		key:        func(o RecipientRelayed) string { return o.Email },
+		deletedAt:  func(o RecipientRelayed) *time.Time { return o.DeletedAt },
+		fields: func(h, w RecipientRelayed) []utils.FieldChange {
+			var f fieldChanges
+			f.add("enabled", value(h.Enabled), value(w.Enabled))
+			return f
+		},
+		create: func(tx *sql.Tx, w RecipientRelayed) error {
+			return db.RecipientsRelayed(tx).Create(splitEmail(w.Email), db.RecipientsRelayedCreateOptions{
+				Enabled: *w.Enabled,
+			})
+		},
+		patch: func(tx *sql.Tx, h, w RecipientRelayed) error {
+			return db.RecipientsRelayed(tx).Patch(splitEmail(w.Email), db.RecipientsRelayedPatchOptions{
+				Enabled: w.Enabled,
+			})
+		},
+		restore: func(tx *sql.Tx, w RecipientRelayed) error {
+			return db.RecipientsRelayed(tx).Restore(splitEmail(w.Email))
+		},
+		delete: func(tx *sql.Tx, h RecipientRelayed, deletedAt *time.Time) error {
+			return db.RecipientsRelayed(tx).Delete(splitEmail(h.Email), db.DeleteOptions{DeletedAt: deletedAt})
+		},
+	})
+}
+
+func (p *planner) aliases(have, want []Alias) {
+	diffObjects(p, have, want, p.options.Prune, objectOps[Alias]{
+		stage:      stageAliases,
+		objectType: "alias",
+		key:        func(o Alias) string { return o.Email },
+		deletedAt:  func(o Alias) *time.Time { return o.DeletedAt },
+		fields: func(h, w Alias) []utils.FieldChange {
+			var f fieldChanges
+			f.add("enabled", value(h.Enabled), value(w.Enabled))
+			return f
+		},
+		create: func(tx *sql.Tx, w Alias) error {
+			return db.Aliases(tx).Create(splitEmail(w.Email), db.AliasesCreateOptions{
+				Disabled: !*w.Enabled,
+			})
+		},
+		patch: func(tx *sql.Tx, h, w Alias) error {
+			return db.Aliases(tx).Patch(splitEmail(w.Email), db.AliasesPatchOptions{
+				Enabled: w.Enabled,
+			})
+		},
+		restore: func(tx *sql.Tx, w Alias) error {
+			return db.Aliases(tx).Restore(splitEmail(w.Email))
+		},
+		delete: func(tx *sql.Tx, h Alias, deletedAt *time.Time) error {
+			return db.Aliases(tx).Delete(splitEmail(h.Email), db.DeleteOptions{DeletedAt: deletedAt})
+		},
+	})
+
+	// Targets of the aliases in the desired state
+	type aliasTargetRef struct {
+		Alias string
+		AliasTarget
+	}
+	desiredAliases := make(map[string]struct{}, len(want))
+	var wantTargets, haveTargets []aliasTargetRef
+	for _, a := range want {
+		desiredAliases[a.Email] = struct{}{}
+		for _, t := range a.Targets {
+			wantTargets = append(wantTargets, aliasTargetRef{a.Email, t})
+		}
+	}
+	for _, a := range have {
+		if _, ok := desiredAliases[a.Email]; !ok {
+			continue
+		}
+		for _, t := range a.Targets {
+			haveTargets = append(haveTargets, aliasTargetRef{a.Email, t})
+		}
+	}
+
+	diffObjects(p, haveTargets, wantTargets, p.options.Prune, objectOps[aliasTargetRef]{
+		stage:      stageAliasTargets,
+		objectType: "alias target",
+		key:        func(o aliasTargetRef) string { return o.Alias + " -> " + o.Email },
+		object:     func(o aliasTargetRef) any { return o.AliasTarget },
+		deletedAt:  func(o aliasTargetRef) *time.Time { return o.DeletedAt },
+		fields: func(h, w aliasTargetRef) []utils.FieldChange {
+			var f fieldChanges
+			f.add("forwarding", value(h.Forwarding), value(w.Forwarding))
+			f.add("sending", value(h.Sending), value(w.Sending))
+			return f
+		},
+		create: func(tx *sql.Tx, w aliasTargetRef) error {
+			return db.AliasesTargets(tx).Create(splitEmail(w.Alias), splitEmail(w.Email), db.AliasesTargetsCreateOptions{
+				ForwardEnabled: *w.Forwarding,
+				SendEnabled:    *w.Sending,
+			})
+		},
+		patch: func(tx *sql.Tx, h, w aliasTargetRef) error {
+			var options db.AliasesTargetsPatchOptions
+			if *h.Forwarding != *w.Forwarding {
+				options.ForwardingToTargetEnabled = w.Forwarding
+			}
+			if *h.Sending != *w.Sending {
+				options.SendingFromTargetEnabled = w.Sending
+			}
+			return db.AliasesTargets(tx).Patch(splitEmail(w.Alias), splitEmail(w.Email), options)
+		},
+		restore: func(tx *sql.Tx, w aliasTargetRef) error {
+			return db.AliasesTargets(tx).Restore(splitEmail(w.Alias), splitEmail(w.Email))
+		},
+		delete: func(tx *sql.Tx, h aliasTargetRef, deletedAt *time.Time) error {
+			return db.AliasesTargets(tx).Delete(splitEmail(h.Alias), splitEmail(h.Email), db.DeleteOptions{DeletedAt: deletedAt})
+		},
+	})
+}
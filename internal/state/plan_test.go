@@ -0,0 +1,232 @@
+package state
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, in string) *Document {
+	t.Helper()
+	doc, err := Load([]byte(in))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	return doc
+}
+
+func planStrings(p *Plan) []string {
+	out := make([]string, 0, len(p.Changes))
+	for _, c := range p.Changes {
+		out = append(out, c.String())
+	}
+	return out
+}
+
+const planBase = `
+version: 1
+transports:
+  - name: dovecot
+    method: lmtp
+    host: mail.internal
+domains:
+  - fqdn: example.com
+    transport: dovecot
+mailboxes:
+  - email: user@example.com
+aliases:
+  - email: team@example.com
+    targets:
+      - email: user@example.com
+`
+
+func TestNewPlanCreate(t *testing.T) {
+	plan, err := NewPlan(&Document{Version: DocumentVersion}, mustLoad(t, planBase), PlanOptions{})
+	if err != nil {
+		t.Fatalf("NewPlan() unexpected error: %v", err)
+	}
+
+	want := []string{
+		"create transport dovecot",
+		"create domain example.com",
+		"create mailbox user@example.com",
+		"create alias team@example.com",
+		"create alias target team@example.com -> user@example.com",
+	}
+	if got := planStrings(plan); !slices.Equal(got, want) {
+		t.Fatalf("NewPlan() = %q, want %q", got, want)
+	}
+}
+
+func TestNewPlanNoChanges(t *testing.T) {
+	plan, err := NewPlan(mustLoad(t, planBase), mustLoad(t, planBase), PlanOptions{Prune: true})
+	if err != nil {
+		t.Fatalf("NewPlan() unexpected error: %v", err)
+	}
+	if len(plan.Changes) != 0 {
+		t.Fatalf("NewPlan() = %q, want no changes", planStrings(plan))
+	}
+}
+
+func TestNewPlanUpdateRestorePrune(t *testing.T) {
+	current := mustLoad(t, planBase+`
+  - email: old@example.com
+recipientsRelayed: []
+`)
+	deletedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	current.Mailboxes[0].DeletedAt = &deletedAt
+	hash := "{ARGON2ID}old"
+	current.Mailboxes[0].PasswordHash = &hash
+
+	desired := mustLoad(t, `
+version: 1
+transports:
+  - name: dovecot
+    method: lmtp
+    host: mail.internal
+    port: 24
+domains:
+  - fqdn: example.com
+    transport: dovecot
+mailboxes:
+  - email: user@example.com
+    sending: false
+aliases:
+  - email: team@example.com
+`)
+
+	plan, err := NewPlan(current, desired, PlanOptions{})
+	if err != nil {
+		t.Fatalf("NewPlan() unexpected error: %v", err)
+	}
+	want := []string{
+		"update transport dovecot",
+		"restore mailbox user@example.com",
+	}
+	if got := planStrings(plan); !slices.Equal(got, want) {
+		t.Fatalf("NewPlan() = %q, want %q", got, want)
+	}
+	if fields := plan.Changes[1].Fields; len(fields) != 1 || fields[0].Field != "sending" {
+		t.Fatalf("NewPlan() restore fields = %+v, want sending only (password hash unmanaged)", fields)
+	}
+
+	plan, err = NewPlan(current, desired, PlanOptions{Prune: true})
+	if err != nil {
+		t.Fatalf("NewPlan() unexpected error: %v", err)
+	}
+	want = []string{
+		"update transport dovecot",
+		"restore mailbox user@example.com",
+		"delete alias target team@example.com -> user@example.com",
+		"delete alias old@example.com",
+	}
+	if got := planStrings(plan); !slices.Equal(got, want) {
+		t.Fatalf("NewPlan(prune) = %q, want %q", got, want)
+	}
+}
+
+func TestNewPlanCascadedRestore(t *testing.T) {
+	current := mustLoad(t, planBase)
+	deletedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	current.Aliases[0].DeletedAt = &deletedAt
+	current.Aliases[0].Targets[0].DeletedAt = &deletedAt
+
+	// The target is restored together with the alias, so pruning deletes it again
+	desired := mustLoad(t, planBase)
+	desired.Aliases[0].Targets = nil
+
+	plan, err := NewPlan(current, desired, PlanOptions{Prune: true})
+	if err != nil {
+		t.Fatalf("NewPlan() unexpected error: %v", err)
+	}
+	want := []string{
+		"restore alias team@example.com",
+		"delete alias target team@example.com -> user@example.com",
+	}
+	if got := planStrings(plan); !slices.Equal(got, want) {
+		t.Fatalf("NewPlan() = %q, want %q", got, want)
+	}
+	if current.Aliases[0].Targets[0].DeletedAt == nil {
+		t.Fatalf("NewPlan() modified the current state")
+	}
+}
+
+func TestNewPlanDomainTypeChange(t *testing.T) {
+	desired := mustLoad(t, "version: 1\ndomains:\n  - fqdn: example.com\n    type: alias\n")
+	if _, err := NewPlan(mustLoad(t, planBase), desired, PlanOptions{}); err == nil {
+		t.Fatalf("NewPlan() expected error for a domain type change")
+	}
+}
+
+func TestNewPlanImportDeleted(t *testing.T) {
+	desired := mustLoad(t, planBase)
+	deletedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	desired.Aliases[0].DeletedAt = &deletedAt
+	desired.Aliases[0].Targets[0].DeletedAt = &deletedAt
+
+	plan, err := NewPlan(&Document{Version: DocumentVersion}, desired, PlanOptions{OnConflict: ConflictFail})
+	if err != nil {
+		t.Fatalf("NewPlan() unexpected error: %v", err)
+	}
+	want := []string{
+		"create transport dovecot",
+		"create domain example.com",
+		"create mailbox user@example.com",
+		"create alias team@example.com",
+		"create alias target team@example.com -> user@example.com",
+		"delete alias target team@example.com -> user@example.com",
+		"delete alias team@example.com",
+	}
+	if got := planStrings(plan); !slices.Equal(got, want) {
+		t.Fatalf("NewPlan() = %q, want %q", got, want)
+	}
+
+	// Deleted objects are left alone, if they are deleted already
+	current := mustLoad(t, planBase)
+	current.Aliases[0].DeletedAt = &deletedAt
+	current.Aliases[0].Targets[0].DeletedAt = &deletedAt
+	plan, err = NewPlan(current, desired, PlanOptions{OnConflict: ConflictFail})
+	if err != nil {
+		t.Fatalf("NewPlan() unexpected error: %v", err)
+	}
+	if len(plan.Changes) != 0 {
+		t.Fatalf("NewPlan() = %q, want no changes", planStrings(plan))
+	}
+}
+
+func TestNewPlanConflicts(t *testing.T) {
+	current := mustLoad(t, planBase)
+	desired := mustLoad(t, planBase+"recipientsRelayed: []\n")
+	disabled := false
+	desired.Mailboxes[0].Login = &disabled
+	desired.Mailboxes = append(desired.Mailboxes, Mailbox{Email: "new@example.com"})
+	if err := desired.Normalize(); err != nil {
+		t.Fatalf("Normalize() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		policy  ConflictPolicy
+		want    []string
+		wantErr bool
+	}{
+		{ConflictSkip, []string{"create mailbox new@example.com"}, false},
+		{ConflictOverwrite, []string{"update mailbox user@example.com", "create mailbox new@example.com"}, false},
+		{ConflictFail, nil, true},
+	}
+
+	for _, tc := range tests {
+		plan, err := NewPlan(current, desired, PlanOptions{OnConflict: tc.policy})
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("NewPlan(%s) expected error, got %q", tc.policy, planStrings(plan))
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("NewPlan(%s) unexpected error: %v", tc.policy, err)
+		}
+		if got := planStrings(plan); !slices.Equal(got, tc.want) {
+			t.Fatalf("NewPlan(%s) = %q, want %q", tc.policy, got, tc.want)
+		}
+	}
+}
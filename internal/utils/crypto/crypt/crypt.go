@@ -0,0 +1,256 @@
+package crypt
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMismatchedHashAndPassword = errors.New("hashedPassword is not the hash of the given password")
+)
+
+type InvalidHashFormatError string
+
+func (ife InvalidHashFormatError) Error() string {
+	return fmt.Sprintf("invalid crypt hash format: %s", string(ife))
+}
+
+const (
+	itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+	md5Rounds        = 1000
+	md5SaltMaxLen    = 8
+	shaRoundsDefault = 5000
+	shaRoundsMin     = 1000
+	shaRoundsMax     = 999999999
+	shaSaltMaxLen    = 16
+)
+
+// Byte orders of the encoded digests, in groups of three bytes (the last group
+// is padded with -1)
+var (
+	md5Order = [][3]int{
+		{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5}, {-1, -1, 11},
+	}
+	sha256Order = [][3]int{
+		{0, 10, 20}, {21, 1, 11}, {12, 22, 2}, {3, 13, 23}, {24, 4, 14},
+		{15, 25, 5}, {6, 16, 26}, {27, 7, 17}, {18, 28, 8}, {9, 19, 29},
+		{-1, 31, 30},
+	}
+	sha512Order = [][3]int{
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4},
+		{47, 5, 26}, {6, 27, 48}, {28, 49, 7}, {50, 8, 29}, {9, 30, 51},
+		{31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13}, {56, 14, 35},
+		{15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19},
+		{62, 20, 41}, {-1, -1, 63},
+	}
+)
+
+// CompareHashAndPassword compares a MD5-crypt ("$1$"), SHA256-crypt ("$5$")
+// or SHA512-crypt ("$6$") hash with a password.
+func CompareHashAndPassword(hashedPassword, password []byte) error {
+	var computedHash string
+	var err error
+
+	switch {
+	case strings.HasPrefix(string(hashedPassword), "$1$"):
+		computedHash, err = md5Crypt(password, hashedPassword)
+	case strings.HasPrefix(string(hashedPassword), "$5$"):
+		computedHash, err = shaCrypt(sha256.New, "$5$", sha256Order, password, hashedPassword)
+	case strings.HasPrefix(string(hashedPassword), "$6$"):
+		computedHash, err = shaCrypt(sha512.New, "$6$", sha512Order, password, hashedPassword)
+	default:
+		err = InvalidHashFormatError("expected $1$, $5$ or $6$ identifier")
+	}
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(computedHash), hashedPassword) != 1 {
+		return ErrMismatchedHashAndPassword
+	}
+
+	return nil
+}
+
+// md5Crypt computes the MD5-crypt hash of a password with the salt of a hash.
+func md5Crypt(password, hashedPassword []byte) (string, error) {
+	salt, _, ok := strings.Cut(strings.TrimPrefix(string(hashedPassword), "$1$"), "$")
+	if !ok {
+		return "", InvalidHashFormatError("missing hash")
+	}
+	if len(salt) > md5SaltMaxLen {
+		salt = salt[:md5SaltMaxLen]
+	}
+
+	alt := md5.New()
+	alt.Write(password)
+	alt.Write([]byte(salt))
+	alt.Write(password)
+	altSum := alt.Sum(nil)
+
+	h := md5.New()
+	h.Write(password)
+	h.Write([]byte("$1$"))
+	h.Write([]byte(salt))
+	for n := len(password); n > 0; n -= md5.Size {
+		h.Write(altSum[:min(n, md5.Size)])
+	}
+	for n := len(password); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			h.Write([]byte{0})
+		} else {
+			h.Write(password[:1])
+		}
+	}
+	sum := h.Sum(nil)
+
+	for i := range md5Rounds {
+		h := md5.New()
+		if i&1 != 0 {
+			h.Write(password)
+		} else {
+			h.Write(sum)
+		}
+		if i%3 != 0 {
+			h.Write([]byte(salt))
+		}
+		if i%7 != 0 {
+			h.Write(password)
+		}
+		if i&1 != 0 {
+			h.Write(sum)
+		} else {
+			h.Write(password)
+		}
+		sum = h.Sum(nil)
+	}
+
+	return "$1$" + salt + "$" + encode(sum, md5Order), nil
+}
+
+// shaCrypt computes the SHA-crypt hash of a password with the salt and rounds
+// of a hash.
+func shaCrypt(newHash func() hash.Hash, identifier string, order [][3]int, password, hashedPassword []byte) (string, error) {
+	params := strings.TrimPrefix(string(hashedPassword), identifier)
+
+	rounds := shaRoundsDefault
+	roundsPart := ""
+	if rest, ok := strings.CutPrefix(params, "rounds="); ok {
+		value, rest, ok := strings.Cut(rest, "$")
+		if !ok {
+			return "", InvalidHashFormatError("missing salt")
+		}
+		parsedValue, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return "", InvalidHashFormatError("unable to parse rounds")
+		}
+		rounds = min(max(int(parsedValue), shaRoundsMin), shaRoundsMax)
+		roundsPart = "rounds=" + strconv.Itoa(rounds) + "$"
+		params = rest
+	}
+
+	salt, _, ok := strings.Cut(params, "$")
+	if !ok {
+		return "", InvalidHashFormatError("missing hash")
+	}
+	if len(salt) > shaSaltMaxLen {
+		salt = salt[:shaSaltMaxLen]
+	}
+
+	alt := newHash()
+	alt.Write(password)
+	alt.Write([]byte(salt))
+	alt.Write(password)
+	altSum := alt.Sum(nil)
+
+	h := newHash()
+	h.Write(password)
+	h.Write([]byte(salt))
+	for n := len(password); n > 0; n -= len(altSum) {
+		h.Write(altSum[:min(n, len(altSum))])
+	}
+	for n := len(password); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			h.Write(altSum)
+		} else {
+			h.Write(password)
+		}
+	}
+	sum := h.Sum(nil)
+
+	// Sequence P, which is derived from the password
+	h = newHash()
+	for range len(password) {
+		h.Write(password)
+	}
+	p := repeat(h.Sum(nil), len(password))
+
+	// Sequence S, which is derived from the salt
+	h = newHash()
+	for range 16 + int(sum[0]) {
+		h.Write([]byte(salt))
+	}
+	s := repeat(h.Sum(nil), len(salt))
+
+	for i := range rounds {
+		h := newHash()
+		if i&1 != 0 {
+			h.Write(p)
+		} else {
+			h.Write(sum)
+		}
+		if i%3 != 0 {
+			h.Write(s)
+		}
+		if i%7 != 0 {
+			h.Write(p)
+		}
+		if i&1 != 0 {
+			h.Write(sum)
+		} else {
+			h.Write(p)
+		}
+		sum = h.Sum(nil)
+	}
+
+	return identifier + roundsPart + salt + "$" + encode(sum, order), nil
+}
+
+// repeat repeats a sequence of bytes up to a length.
+func repeat(b []byte, n int) []byte {
+	out := make([]byte, 0, n)
+	for len(out) < n {
+		out = append(out, b[:min(len(b), n-len(out))]...)
+	}
+	return out
+}
+
+// encode encodes a digest with the base64 alphabet of crypt in the given byte
+// order.
+func encode(sum []byte, order [][3]int) string {
+	var b strings.Builder
+	for _, group := range order {
+		var w uint32
+		n := 0
+		for _, i := range group {
+			w <<= 8
+			if i >= 0 {
+				w |= uint32(sum[i])
+				n++
+			}
+		}
+		for range n + 1 {
+			b.WriteByte(itoa64[w&0x3f])
+			w >>= 6
+		}
+	}
+	return b.String()
+}
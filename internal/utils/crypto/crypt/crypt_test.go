@@ -0,0 +1,46 @@
+package crypt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompareHashAndPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+	}{
+		{"MD5-crypt", "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/", "password"},
+		{"MD5-crypt without salt", "$1$$ysVNzQc4CTMkp5daOdZ.3/", "secret"},
+		{"SHA256-crypt", "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5", "Hello world!"},
+		{"SHA256-crypt with rounds", "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA", "Hello world!"},
+		{"SHA512-crypt", "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1", "Hello world!"},
+		{"SHA512-crypt with rounds", "$6$rounds=1400$anotherlongsalts$POfYwTEok97VWcjxIiSOjiykti.o/pQs.wPvMxQ6Fm7I6IoYN3CmLs66x9t0oSwbtEW7o7UmJEiDwGqd8p4ur1", "a very much longer text to encrypt.  This one even stretches over morethan one line."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CompareHashAndPassword([]byte(tt.hash), []byte(tt.password)); err != nil {
+				t.Errorf("expected the password to match, got %v", err)
+			}
+			err := CompareHashAndPassword([]byte(tt.hash), []byte(tt.password+"x"))
+			if !errors.Is(err, ErrMismatchedHashAndPassword) {
+				t.Errorf("expected ErrMismatchedHashAndPassword for a wrong password, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCompareHashAndPasswordInvalidFormat(t *testing.T) {
+	for _, hash := range []string{
+		"",
+		"$2y$10$abcdefghijklmnopqrstuv",
+		"$6$saltstring",
+		"$5$rounds=abc$saltstring$hash",
+	} {
+		var formatErr InvalidHashFormatError
+		if err := CompareHashAndPassword([]byte(hash), []byte("password")); !errors.As(err, &formatErr) {
+			t.Errorf("CompareHashAndPassword(%q): expected InvalidHashFormatError, got %v", hash, err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package passwordhash
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/gerolf-vent/mailctl/internal/utils/crypto/argon2"
+	"github.com/gerolf-vent/mailctl/internal/utils/crypto/crypt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrUnsupportedHash = errors.New("unsupported password hash type")
+)
+
+// pattern matches hashes with a Dovecot scheme prefix (e.g. "{SHA512-CRYPT}")
+// and the crypt formats of bcrypt, argon2, MD5-crypt and SHA-crypt.
+var pattern = regexp.MustCompile(`^(\{[A-Z0-9.-]+\}|\$(2[aby]|argon2id|argon2i|1|5|6)\$)`)
+
+// cryptSchemes are the Dovecot schemes of crypt hashes, which Dovecot doesn't
+// detect without a scheme prefix.
+var cryptSchemes = map[string]string{
+	"$1$": "{MD5-CRYPT}",
+	"$5$": "{SHA256-CRYPT}",
+	"$6$": "{SHA512-CRYPT}",
+}
+
+// compareSchemes are the Dovecot schemes of hashes, which can be compared with
+// a password.
+var compareSchemes = []string{
+	"{ARGON2ID}",
+	"{BLF-CRYPT}",
+	"{MD5-CRYPT}",
+	"{SHA256-CRYPT}",
+	"{SHA512-CRYPT}",
+	"{CRYPT}",
+}
+
+// IsHash reports whether a password is already hashed.
+func IsHash(password string) bool {
+	return pattern.MatchString(password)
+}
+
+// Normalize adds the Dovecot scheme prefix to MD5-crypt and SHA-crypt hashes.
+// Other hashes are returned as is, bcrypt and argon2 hashes get their prefix
+// by dovecot.ensure_password_scheme.
+func Normalize(hash string) string {
+	for prefix, scheme := range cryptSchemes {
+		if strings.HasPrefix(hash, prefix) {
+			return scheme + hash
+		}
+	}
+	return hash
+}
+
+// Compare compares a password with an Argon2id, bcrypt, MD5-crypt or SHA-crypt
+// hash, which may have a Dovecot scheme prefix. Other hashes (e.g. "{PLAIN}")
+// fail with ErrUnsupportedHash.
+func Compare(hash, password string) (matches bool, err error) {
+	if strings.HasPrefix(hash, "{") {
+		supported := false
+		for _, scheme := range compareSchemes {
+			if rest, ok := strings.CutPrefix(hash, scheme); ok {
+				hash = rest
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return false, ErrUnsupportedHash
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		err = argon2.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, argon2.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+	case strings.HasPrefix(hash, "$2"):
+		err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+	case strings.HasPrefix(hash, "$1$"), strings.HasPrefix(hash, "$5$"), strings.HasPrefix(hash, "$6$"):
+		err = crypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, crypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+	default:
+		return false, ErrUnsupportedHash
+	}
+
+	return err == nil, err
+}
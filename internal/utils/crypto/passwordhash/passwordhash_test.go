@@ -0,0 +1,95 @@
+package passwordhash
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gerolf-vent/mailctl/internal/utils/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestIsHash(t *testing.T) {
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"$2y$10$abcdefghijklmnopqrstuv", true},
+		{"$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$aGFzaA", true},
+		{"$argon2i$v=19$m=65536,t=3,p=4$c2FsdA$aGFzaA", true},
+		{"$1$salt$hash", true},
+		{"$5$salt$hash", true},
+		{"$6$rounds=5000$salt$hash", true},
+		{"{SHA512-CRYPT}$6$salt$hash", true},
+		{"{PLAIN}secret", true},
+		{"secret", false},
+		{"$3$hash", false},
+		{"{lower}secret", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsHash(tt.password); got != tt.want {
+			t.Errorf("IsHash(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		hash string
+		want string
+	}{
+		{"$1$salt$hash", "{MD5-CRYPT}$1$salt$hash"},
+		{"$5$salt$hash", "{SHA256-CRYPT}$5$salt$hash"},
+		{"$6$rounds=5000$salt$hash", "{SHA512-CRYPT}$6$rounds=5000$salt$hash"},
+		{"{SHA512-CRYPT}$6$salt$hash", "{SHA512-CRYPT}$6$salt$hash"},
+		{"$2y$10$abcdefghijklmnopqrstuv", "$2y$10$abcdefghijklmnopqrstuv"},
+		{"$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$aGFzaA", "$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$aGFzaA"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.hash); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.hash, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("generate bcrypt hash: %v", err)
+	}
+	argon2Hash, err := argon2.GenerateFromPassword([]byte("secret"), 1, 64*1024, 1, 32)
+	if err != nil {
+		t.Fatalf("generate argon2id hash: %v", err)
+	}
+
+	tests := []struct {
+		hash string
+		want bool
+	}{
+		{string(bcryptHash), true},
+		{"{BLF-CRYPT}" + string(bcryptHash), true},
+		{string(argon2Hash), true},
+		{"{ARGON2ID}" + string(argon2Hash), true},
+		{"$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/", false},
+		{"{MD5-CRYPT}$1$$ysVNzQc4CTMkp5daOdZ.3/", true},
+		{"{SHA256-CRYPT}$5$saltstring$C3o4O1TC6aRHF4FI.QSZMXtHbaj2gSXr4sUc/3NcUi.", true},
+		{"{SHA512-CRYPT}$6$saltstring$AIsRs/Ee56G/tC8MEHhvReZTfx8u3rXXMl6eYrjCG9ibix19DxoMBLogdTET5Ukw9Sf7eZTITsuk0Ry5qulYz.", true},
+		{"{CRYPT}$6$saltstring$AIsRs/Ee56G/tC8MEHhvReZTfx8u3rXXMl6eYrjCG9ibix19DxoMBLogdTET5Ukw9Sf7eZTITsuk0Ry5qulYz.", true},
+	}
+	for _, tt := range tests {
+		got, err := Compare(tt.hash, "secret")
+		if err != nil {
+			t.Errorf("Compare(%q) failed: %v", tt.hash, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Compare(%q) = %v, want %v", tt.hash, got, tt.want)
+		}
+	}
+
+	for _, hash := range []string{"{PLAIN}secret", "{SSHA512}c2VjcmV0", "secret", "$argon2i$v=19$m=65536,t=3,p=4$c2FsdA$aGFzaA"} {
+		if _, err := Compare(hash, "secret"); !errors.Is(err, ErrUnsupportedHash) {
+			t.Errorf("Compare(%q): expected ErrUnsupportedHash, got %v", hash, err)
+		}
+	}
+}
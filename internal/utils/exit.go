@@ -0,0 +1,13 @@
+package utils
+
+import "fmt"
+
+// ExitError ends the program with the given exit code. The command has to
+// report the reason itself, nothing is printed for it.
+type ExitError struct {
+	Code int
+}
+
+func (e ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+)
+
+// FieldChange describes the change of a single top-level field between two
+// JSON objects. Old or New is nil if the field is absent (or null) on that side.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// DiffJSONObjects compares two JSON objects field by field and returns the
+// changed fields ordered by name. A nil or empty document is treated as an
+// empty object, so a created or deleted row yields all of its fields.
+func DiffJSONObjects(oldDoc, newDoc []byte) ([]FieldChange, error) {
+	oldFields, err := unmarshalJSONObject(oldDoc)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := unmarshalJSONObject(newDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldSet := make(map[string]struct{}, len(oldFields)+len(newFields))
+	for field := range oldFields {
+		fieldSet[field] = struct{}{}
+	}
+	for field := range newFields {
+		fieldSet[field] = struct{}{}
+	}
+
+	fields := make([]string, 0, len(fieldSet))
+	for field := range fieldSet {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+
+	var changes []FieldChange
+	for _, field := range fields {
+		oldValue := oldFields[field]
+		newValue := newFields[field]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, FieldChange{
+			Field: field,
+			Old:   oldValue,
+			New:   newValue,
+		})
+	}
+
+	return changes, nil
+}
+
+func unmarshalJSONObject(doc []byte) (map[string]any, error) {
+	if len(doc) == 0 {
+		return nil, nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
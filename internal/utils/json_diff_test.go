@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffJSONObjects(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		want    []FieldChange
+		wantErr bool
+	}{
+		{
+			name: "update",
+			old:  `{"id": 1, "name": "alice", "enabled": true, "quota": 100}`,
+			new:  `{"id": 1, "name": "alice", "enabled": false, "quota": null}`,
+			want: []FieldChange{
+				{Field: "enabled", Old: true, New: false},
+				{Field: "quota", Old: float64(100), New: nil},
+			},
+		},
+		{
+			name: "insert",
+			old:  ``,
+			new:  `{"name": "bob", "id": 2}`,
+			want: []FieldChange{
+				{Field: "id", Old: nil, New: float64(2)},
+				{Field: "name", Old: nil, New: "bob"},
+			},
+		},
+		{
+			name: "delete",
+			old:  `{"name": "bob"}`,
+			new:  ``,
+			want: []FieldChange{
+				{Field: "name", Old: "bob", New: nil},
+			},
+		},
+		{
+			name: "unchanged",
+			old:  `{"name": "bob", "tags": ["a", "b"]}`,
+			new:  `{"tags": ["a", "b"], "name": "bob"}`,
+			want: nil,
+		},
+		{
+			name:    "invalid",
+			old:     `{"name":`,
+			new:     `{}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		got, err := DiffJSONObjects([]byte(tc.old), []byte(tc.new))
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("%s: expected error, got %v", tc.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: DiffJSONObjects() = %#v, want %#v", tc.name, got, tc.want)
+		}
+	}
+}
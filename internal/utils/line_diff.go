@@ -0,0 +1,57 @@
+package utils
+
+// LineDiffOp is the operation of a line in a line diff.
+type LineDiffOp byte
+
+const (
+	LineEqual  LineDiffOp = ' '
+	LineDelete LineDiffOp = '-'
+	LineInsert LineDiffOp = '+'
+)
+
+type LineDiff struct {
+	Op   LineDiffOp
+	Text string
+}
+
+// DiffLines computes a minimal line diff between two texts based on their
+// longest common subsequence. Deleted lines are listed before inserted lines
+// of the same position. It is meant for small documents, as it needs
+// quadratic time and memory.
+func DiffLines(oldLines, newLines []string) []LineDiff {
+	n, m := len(oldLines), len(newLines)
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// oldLines[i:] and newLines[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	out := make([]LineDiff, 0, max(n, m))
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && oldLines[i] == newLines[j]:
+			out = append(out, LineDiff{LineEqual, oldLines[i]})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, LineDiff{LineDelete, oldLines[i]})
+			i++
+		default:
+			out = append(out, LineDiff{LineInsert, newLines[j]})
+			j++
+		}
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		old, new []string
+		want     []LineDiff
+	}{
+		{nil, nil, []LineDiff{}},
+		{
+			[]string{"a", "b", "c"},
+			[]string{"a", "b", "c"},
+			[]LineDiff{{LineEqual, "a"}, {LineEqual, "b"}, {LineEqual, "c"}},
+		},
+		{
+			[]string{"email: a", "login: true", "quota: 1"},
+			[]string{"email: a", "login: false", "quota: 1"},
+			[]LineDiff{{LineEqual, "email: a"}, {LineDelete, "login: true"}, {LineInsert, "login: false"}, {LineEqual, "quota: 1"}},
+		},
+		{
+			nil,
+			[]string{"a", "b"},
+			[]LineDiff{{LineInsert, "a"}, {LineInsert, "b"}},
+		},
+		{
+			[]string{"a", "b"},
+			[]string{"b"},
+			[]LineDiff{{LineDelete, "a"}, {LineEqual, "b"}},
+		},
+	}
+
+	for _, tc := range tests {
+		got := DiffLines(tc.old, tc.new)
+		if !slices.Equal(got, tc.want) {
+			t.Fatalf("DiffLines(%q, %q) = %v, want %v", tc.old, tc.new, got, tc.want)
+		}
+	}
+}
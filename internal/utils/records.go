@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RecordFormat is the format of a record input.
+type RecordFormat string
+
+const (
+	RecordFormatCSV   RecordFormat = "csv"
+	RecordFormatJSONL RecordFormat = "jsonl"
+	RecordFormatLines RecordFormat = "lines"
+)
+
+// Record is a row of a CSV, JSONL or newline-separated input. Empty values
+// are omitted. Newline-separated rows only have a single unnamed value, which
+// is stored under the empty key.
+type Record struct {
+	Line   int
+	Fields map[string]string
+}
+
+// DetectRecordFormat guesses the format from the first line, which isn't
+// empty or a comment: JSON objects are JSONL, lines containing a comma are a
+// CSV header, anything else is newline-separated.
+func DetectRecordFormat(data []byte) RecordFormat {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "{"):
+			return RecordFormatJSONL
+		case strings.Contains(line, ","):
+			return RecordFormatCSV
+		default:
+			return RecordFormatLines
+		}
+	}
+	return RecordFormatLines
+}
+
+// ParseRecords parses the records of an input, detecting its format.
+func ParseRecords(data []byte) ([]Record, error) {
+	switch DetectRecordFormat(data) {
+	case RecordFormatJSONL:
+		return parseJSONLRecords(data)
+	case RecordFormatCSV:
+		return parseCSVRecords(data)
+	default:
+		return parseLineRecords(data), nil
+	}
+}
+
+func parseLineRecords(data []byte) []Record {
+	var records []Record
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		records = append(records, Record{Line: i + 1, Fields: map[string]string{"": line}})
+	}
+	return records
+}
+
+func parseCSVRecords(data []byte) ([]Record, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comment = '#'
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i, column := range header {
+		header[i] = strings.TrimSpace(column)
+	}
+
+	var records []Record
+	for {
+		values, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+
+		record := Record{Line: line, Fields: make(map[string]string, len(values))}
+		for i, value := range values {
+			if value = strings.TrimSpace(value); value != "" {
+				record.Fields[header[i]] = value
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func parseJSONLRecords(data []byte) ([]Record, error) {
+	var records []Record
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var object map[string]any
+		d := json.NewDecoder(strings.NewReader(line))
+		d.UseNumber()
+		if err := d.Decode(&object); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		record := Record{Line: i + 1, Fields: make(map[string]string, len(object))}
+		for key, value := range object {
+			switch value := value.(type) {
+			case nil:
+				continue
+			case string:
+				if value != "" {
+					record.Fields[key] = value
+				}
+			case bool:
+				record.Fields[key] = strconv.FormatBool(value)
+			case json.Number:
+				record.Fields[key] = value.String()
+			default:
+				return nil, fmt.Errorf("line %d: unsupported value of %q", i+1, key)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestParseRecords(t *testing.T) {
+	tests := []struct {
+		in     string
+		format RecordFormat
+		want   []Record
+	}{
+		{
+			"# users\na@example.com\n\nb@example.com\n",
+			RecordFormatLines,
+			[]Record{
+				{Line: 2, Fields: map[string]string{"": "a@example.com"}},
+				{Line: 4, Fields: map[string]string{"": "b@example.com"}},
+			},
+		},
+		{
+			"email,quota,transport\na@example.com,1024,\n b@example.com , ,dovecot\n",
+			RecordFormatCSV,
+			[]Record{
+				{Line: 2, Fields: map[string]string{"email": "a@example.com", "quota": "1024"}},
+				{Line: 3, Fields: map[string]string{"email": "b@example.com", "transport": "dovecot"}},
+			},
+		},
+		{
+			`{"alias": "team@example.com", "target": "a@example.com", "send": true}` + "\n" +
+				`{"alias": "team@example.com", "target": "b@example.com", "quota": 10, "transport": null}`,
+			RecordFormatJSONL,
+			[]Record{
+				{Line: 1, Fields: map[string]string{"alias": "team@example.com", "target": "a@example.com", "send": "true"}},
+				{Line: 2, Fields: map[string]string{"alias": "team@example.com", "target": "b@example.com", "quota": "10"}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		if got := DetectRecordFormat([]byte(tc.in)); got != tc.format {
+			t.Fatalf("DetectRecordFormat(%q) = %q, want %q", tc.in, got, tc.format)
+		}
+
+		got, err := ParseRecords([]byte(tc.in))
+		if err != nil {
+			t.Fatalf("ParseRecords(%q) unexpected error: %v", tc.in, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("ParseRecords(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+		for i := range got {
+			if got[i].Line != tc.want[i].Line || !maps.Equal(got[i].Fields, tc.want[i].Fields) {
+				t.Fatalf("ParseRecords(%q)[%d] = %v, want %v", tc.in, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
+
+func TestParseRecordsInvalid(t *testing.T) {
+	tests := []string{
+		"email,quota\na@example.com\n",
+		`{"email": "a@example.com"` + "\n",
+		`{"email": ["a@example.com"]}` + "\n",
+	}
+
+	for _, in := range tests {
+		if _, err := ParseRecords([]byte(in)); err == nil {
+			t.Fatalf("ParseRecords(%q) expected error", in)
+		}
+	}
+}
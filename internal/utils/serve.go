@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// ServeConns handles each connection accepted on the listener in its own
+// goroutine, until the context is done. Then the listener and all open
+// connections are closed and ServeConns returns, once all handlers returned.
+func ServeConns(ctx context.Context, l net.Listener, handle func(conn net.Conn)) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	var mu sync.Mutex
+	conns := make(map[net.Conn]struct{})
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = l.Close()
+
+		mu.Lock()
+		defer mu.Unlock()
+		for conn := range conns {
+			_ = conn.Close()
+		}
+	})
+	defer stop()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		mu.Lock()
+		conns[conn] = struct{}{}
+		mu.Unlock()
+
+		wg.Go(func() {
+			defer func() {
+				mu.Lock()
+				delete(conns, conn)
+				mu.Unlock()
+				_ = conn.Close()
+			}()
+			handle(conn)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseDuration extends time.ParseDuration with the units "d" (days) and
+// "w" (weeks), which are handy for retention periods. Examples:
+//
+//	"90d" -> 90 * 24h
+//	"2w" -> 14 * 24h
+//	"36h" -> 36h
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	default:
+		return time.ParseDuration(s)
+	}
+
+	n, err := strconv.ParseUint(s[:len(s)-1], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	return time.Duration(n) * unit, nil
+}
+
+// FormatDuration formats a duration the way ParseDuration accepts it, using
+// days for whole days (e.g. "90d") and time.Duration.String otherwise.
+func FormatDuration(d time.Duration) string {
+	day := 24 * time.Hour
+	if d > 0 && d%day == 0 {
+		return strconv.FormatInt(int64(d/day), 10) + "d"
+	}
+	return d.String()
+}
+
+// ParseTimeOrDuration parses an absolute point in time (RFC 3339 or
+// "YYYY-MM-DD[ HH:MM[:SS]]" in local time) or a duration, which is
+// interpreted relative to now (e.g. "24h" means 24 hours ago).
+func ParseTimeOrDuration(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	d, err := ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time or duration %q", s)
+	}
+
+	return now.Add(-d), nil
+}
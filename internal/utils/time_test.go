@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90d", 90 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{" 7d ", 7 * 24 * time.Hour, false},
+		{"", 0, true},
+		{"d", 0, true},
+		{"-1d", 0, true},
+		{"1.5d", 0, true},
+		{"abc", 0, true},
+	}
+
+	for _, tc := range tests {
+		got, err := ParseDuration(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("ParseDuration(%q) expected error, got %v", tc.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseDuration(%q) unexpected error: %v", tc.in, err)
+		}
+		if got != tc.want {
+			t.Fatalf("ParseDuration(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{90 * 24 * time.Hour, "90d"},
+		{14 * 24 * time.Hour, "14d"},
+		{36 * time.Hour, "36h0m0s"},
+		{0, "0s"},
+	}
+
+	for _, tc := range tests {
+		got := FormatDuration(tc.in)
+		if got != tc.want {
+			t.Fatalf("FormatDuration(%v) = %q, want %q", tc.in, got, tc.want)
+		}
+		if tc.in > 0 {
+			parsed, err := ParseDuration(got)
+			if err != nil || parsed != tc.in {
+				t.Fatalf("ParseDuration(FormatDuration(%v)) = %v, %v", tc.in, parsed, err)
+			}
+		}
+	}
+}
+
+func TestParseTimeOrDuration(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2025-01-02T03:04:05Z", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"2025-01-02", time.Date(2025, 1, 2, 0, 0, 0, 0, time.Local), false},
+		{"2025-01-02 03:04", time.Date(2025, 1, 2, 3, 4, 0, 0, time.Local), false},
+		{"2025-01-02 03:04:05", time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local), false},
+		{"24h", now.Add(-24 * time.Hour), false},
+		{"7d", now.Add(-7 * 24 * time.Hour), false},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, tc := range tests {
+		got, err := ParseTimeOrDuration(tc.in, now)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("ParseTimeOrDuration(%q) expected error, got %v", tc.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseTimeOrDuration(%q) unexpected error: %v", tc.in, err)
+		}
+		if !got.Equal(tc.want) {
+			t.Fatalf("ParseTimeOrDuration(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}